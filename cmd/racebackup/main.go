@@ -0,0 +1,131 @@
+// Command racebackup writes race backup archive or restores race from it, using database of the app config.
+//
+//	racebackup backup -race <race_id> [-o race.zip]
+//	racebackup restore [-force] race.zip
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/ecoarchie/timeit/config"
+	"github.com/ecoarchie/timeit/internal/database"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/internal/repo"
+	"github.com/ecoarchie/timeit/internal/service"
+	"github.com/ecoarchie/timeit/pkg/logger"
+	"github.com/ecoarchie/timeit/pkg/postgres"
+	"github.com/google/uuid"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cfg, err := config.NewConfig()
+	if err != nil {
+		log.Fatalf("Config error: %s", err)
+	}
+	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(1))
+	if err != nil {
+		log.Fatalf("postgres.New: %s", err)
+	}
+	defer pg.Close()
+	raceService := service.NewRaceService(logger.New(cfg.Log.Level), repo.NewRaceRepoPG(database.New(pg.Pool), pg), nil)
+
+	ctx := context.Background()
+	switch os.Args[1] {
+	case "backup":
+		fs := flag.NewFlagSet("backup", flag.ExitOnError)
+		raceID := fs.String("race", "", "ID of the race")
+		out := fs.String("o", "", "archive file, race-<race_id>.zip by default")
+		fs.Parse(os.Args[2:])
+		err = backup(ctx, raceService, *raceID, *out)
+	case "restore":
+		fs := flag.NewFlagSet("restore", flag.ExitOnError)
+		force := fs.Bool("force", false, "replace race being timed or with official results")
+		fs.Parse(os.Args[2:])
+		if fs.NArg() != 1 {
+			usage()
+		}
+		err = restore(ctx, raceService, fs.Arg(0), *force)
+	default:
+		usage()
+	}
+	if err != nil {
+		pg.Close()
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage:\n  racebackup backup -race <race_id> [-o race.zip]\n  racebackup restore [-force] race.zip")
+	os.Exit(2)
+}
+
+func backup(ctx context.Context, rs *service.RaceService, raceID, out string) error {
+	id, err := uuid.Parse(raceID)
+	if err != nil {
+		return fmt.Errorf("race must be valid uuid: %w", err)
+	}
+	b, err := rs.BackupRace(ctx, id)
+	if err != nil {
+		return err
+	}
+	if b == nil {
+		return fmt.Errorf("race %s not found", id)
+	}
+	if out == "" {
+		out = fmt.Sprintf("race-%s.zip", id)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	err = b.WriteZip(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = f.Close()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("race %q backed up to %s\n", b.RaceName, out)
+	printRows(b)
+	return nil
+}
+
+func restore(ctx context.Context, rs *service.RaceService, path string, force bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(f)
+	f.Close()
+	if err != nil {
+		return err
+	}
+	b, err := entity.ReadRaceBackup(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	race, err := rs.RestoreRace(ctx, b, force)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("race %q (%s) restored from backup of %s\n", race.Name, race.ID, b.CreatedAt.Format("2006-01-02 15:04:05"))
+	printRows(b)
+	return nil
+}
+
+func printRows(b *entity.RaceBackup) {
+	for _, t := range entity.RaceBackupTables {
+		fmt.Printf("  %-22s %d\n", t, b.Rows[t])
+	}
+}
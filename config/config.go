@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
@@ -14,6 +15,8 @@ type (
 		HTTP
 		Log
 		PG
+		AgeGrade
+		Results
 	}
 
 	// App -.
@@ -37,6 +40,24 @@ type (
 		URL     string `env-required:"true" env:"PG_URL"`
 		PoolMax int    `env:"PG_POOL_MAX"`
 	}
+
+	// AgeGrade -.
+	AgeGrade struct {
+		FactorsFile string `env:"AGE_GRADE_FACTORS_FILE"`
+	}
+
+	// Results -.
+	Results struct {
+		// interval of recalculation of live races for new reads, 0 disables live results
+		LiveInterval time.Duration `env:"RESULTS_LIVE_INTERVAL" env-default:"3s"`
+		// interval of full recalculation of races with launched waves, 0 disables periodic recalculation,
+		// recalculations queued by changes of athletes are run anyway
+		RecalcInterval time.Duration `env:"RESULTS_RECALC_INTERVAL" env-default:"1m"`
+		// interval of checking auto start waves, 0 disables automatic launch
+		WaveLaunchInterval time.Duration `env:"RESULTS_WAVE_LAUNCH_INTERVAL" env-default:"5s"`
+		// number of events calculated concurrently, 0 uses number of CPUs
+		Workers int `env:"RESULTS_WORKERS" env-default:"0"`
+	}
 )
 
 func NewConfig() (*Config, error) {
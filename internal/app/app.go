@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -33,18 +34,38 @@ func Run(cfg *config.Config) {
 	// Services
 	logger.Info("Creating services")
 	raceRepo := repo.NewRaceRepoPG(queries, pg)
-	raceService := service.NewRaceService(logger, raceRepo)
+
+	ageGrades, err := service.LoadAgeGradeTable(cfg.AgeGrade.FactorsFile)
+	if err != nil {
+		logger.Fatal(fmt.Errorf("app - Run - service.LoadAgeGradeTable: %w", err).Error())
+	}
 
 	athleteRepo := repo.NewAthleteRepoPG(queries, pg)
-	athleteService := service.NewAthleteService(logger, athleteRepo, raceService)
-	resultsService := service.NewResultsService(athleteRepo)
+	resultsService := service.NewResultsService(athleteRepo, raceRepo, ageGrades)
+	resultsService.Workers = cfg.Results.Workers
+	resultsService.Log = logger
+	raceService := service.NewRaceService(logger, raceRepo, resultsService)
+	scheduler := service.NewResultsScheduler(logger, resultsService, cfg.Results.RecalcInterval)
+	athleteService := service.NewAthleteService(logger, athleteRepo, raceService, scheduler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if cfg.Results.LiveInterval > 0 {
+		liveResults := service.NewLiveResults(logger, resultsService, cfg.Results.LiveInterval)
+		go liveResults.Run(ctx)
+	}
+	if cfg.Results.WaveLaunchInterval > 0 {
+		waveLauncher := service.NewWaveLauncher(logger, resultsService, cfg.Results.WaveLaunchInterval)
+		go waveLauncher.Run(ctx)
+	}
+	go scheduler.Run(ctx)
 
 	// Routers
 	logger.Info("Creating routers")
 	router := chi.NewRouter()
 	router.Use(middleware.Logger)
 	httpv1.NewRaceRouter(router, logger, raceService)
-	httpv1.NewAthleteResultsRouter(router, logger, athleteService, resultsService)
+	httpv1.NewAthleteResultsRouter(router, logger, athleteService, resultsService, scheduler)
 	httpServer := httpserver.New(router, httpserver.Port(cfg.HTTP.Port))
 
 	logger.Info("Starting server at", "port", cfg.HTTP.Port)
@@ -61,6 +82,7 @@ func Run(cfg *config.Config) {
 	}
 
 	// Shutdown
+	cancel()
 	err = httpServer.Shutdown()
 	if err != nil {
 		logger.Error(fmt.Sprintf("app - Run - httpServer.Shutdown: %s", err.Error()))
@@ -2,13 +2,17 @@ package httpv1
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/ecoarchie/timeit/internal/entity"
 	"github.com/ecoarchie/timeit/internal/service"
 	"github.com/ecoarchie/timeit/pkg/logger"
+	"github.com/ecoarchie/timeit/pkg/validator"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
@@ -29,6 +33,10 @@ func newAthletesRoutes(logger *logger.Logger, service service.AthleteManager) ht
 	r.Post("/", rr.createSingleAthlete)
 	r.Post("/csvheaders", rr.checkHeadersCSV)
 	r.Post("/csv/{file_token}", rr.createBulkFromCSV)
+	r.Post("/starttimes", rr.generateStartTimes)
+	r.Get("/startlist", rr.startList)
+	r.Put("/{athlete_id}/status", rr.overrideStatus)
+	r.Put("/{athlete_id}/categories", rr.setAthleteCategories)
 	r.Delete("/{athlete_id}", rr.deleteAthleteByID)
 	r.Delete("/", rr.deleteAthletesForRace)
 	return r
@@ -55,10 +63,19 @@ func (p athletesRoutes) createSingleAthlete(w http.ResponseWriter, r *http.Reque
 		errorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	a, err := p.service.CreateAthlete(context.Background(), req)
+	v := validator.New()
+	a, err := p.service.CreateAthlete(context.Background(), req, v)
 	if err != nil {
+		if errors.Is(err, validator.ErrValidation) {
+			failedValidationResponse(w, v)
+			return
+		}
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
 		mes := "error creating athlete"
-		p.logger.Error(mes, err)
+		p.logger.Error(mes, "err", err.Error())
 		errorResponse(w, http.StatusBadRequest, mes)
 		return
 	}
@@ -69,12 +86,184 @@ func (p athletesRoutes) createSingleAthlete(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+func (p athletesRoutes) overrideStatus(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	aID := chi.URLParam(r, "athlete_id")
+	var req entity.StatusOverrideRequest
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(validator.IsUUID(aID), "athlete_id", "must be valid uuid")
+	v.Check(entity.IsValidStatus(req.Status), "status", "must be valid athlete status")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	st, err := p.service.OverrideStatus(r.Context(), uuid.MustParse(rID), uuid.MustParse(aID), req)
+	if err != nil {
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
+		p.logger.Error("Override athlete status: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if st == nil {
+		notFoundResponse(w, r)
+		return
+	}
+	err = writeJSON(w, http.StatusOK, st, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+// setAthleteCategories replaces custom categories athlete is member of
+func (p athletesRoutes) setAthleteCategories(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	aID := chi.URLParam(r, "athlete_id")
+	var req struct {
+		Categories []uuid.UUID `json:"categories"`
+	}
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(validator.IsUUID(aID), "athlete_id", "must be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	a, err := p.service.SetAthleteCategories(r.Context(), uuid.MustParse(rID), uuid.MustParse(aID), req.Categories, v)
+	if err != nil {
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
+		if errors.Is(err, validator.ErrValidation) {
+			failedValidationResponse(w, v)
+			return
+		}
+		p.logger.Error("Set athlete categories: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if a == nil {
+		notFoundResponse(w, r)
+		return
+	}
+	err = writeJSON(w, http.StatusOK, a, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+// generateStartTimes assigns individual start times to athletes of event in bib order
+func (p athletesRoutes) generateStartTimes(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	var req entity.StartTimesRequest
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	st, err := p.service.GenerateStartTimes(r.Context(), uuid.MustParse(rID), req, v)
+	if err != nil {
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
+		if errors.Is(err, validator.ErrValidation) {
+			failedValidationResponse(w, v)
+			return
+		}
+		p.logger.Error("Generate start times: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if st == nil {
+		notFoundResponse(w, r)
+		return
+	}
+	err = writeJSON(w, http.StatusOK, st, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+// startList exports athletes ordered by start time as JSON or, with format=csv, as CSV file
+func (p athletesRoutes) startList(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	eID := r.URL.Query().Get("event_id")
+	format := r.URL.Query().Get("format")
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(eID == "" || validator.IsUUID(eID), "event_id", "must be valid uuid")
+	v.Check(validator.PermittedValue(format, "", "json", "csv"), "format", "must be json or csv")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	var eventID uuid.NullUUID
+	if eID != "" {
+		eventID = uuid.NullUUID{UUID: uuid.MustParse(eID), Valid: true}
+	}
+	sl, err := p.service.GetStartList(r.Context(), uuid.MustParse(rID), eventID)
+	if err != nil {
+		p.logger.Error("Get start list: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if format != "csv" {
+		err = writeJSON(w, http.StatusOK, sl, nil)
+		if err != nil {
+			serverErrorResponse(w, err)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="startlist.csv"`)
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	cw.Write([]string{"bib", "name", "surname", "event", "wave", "start time"})
+	for _, e := range sl {
+		var start string
+		if !e.StartTime.IsZero() {
+			start = e.StartTime.Format(time.DateTime)
+		}
+		cw.Write([]string{strconv.Itoa(e.Bib), e.FirstName, e.LastName, e.EventName, e.WaveName, start})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		p.logger.Error("Write start list CSV: ", "err", err.Error())
+	}
+}
+
 func (p athletesRoutes) deleteAthleteByID(w http.ResponseWriter, r *http.Request) {
 	athleteID := chi.URLParam(r, "athlete_id")
 	aUUID, _ := uuid.Parse(athleteID)
 	err := p.service.DeleteAthlete(r.Context(), aUUID)
 	if err != nil {
-		p.logger.Error("error deleting athlete", err)
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
+		p.logger.Error("error deleting athlete", "err", err.Error())
 		serverErrorResponse(w, err)
 		return
 	}
@@ -88,6 +277,10 @@ func (p athletesRoutes) deleteAthletesForRace(w http.ResponseWriter, r *http.Req
 	eventID, _ := uuid.Parse(eID)
 	err := p.service.DeleteAthletesForRace(r.Context(), raceID, eventID)
 	if err != nil {
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
 		serverErrorResponse(w, err)
 		return
 	}
@@ -148,8 +341,17 @@ func (p athletesRoutes) createBulkFromCSV(w http.ResponseWriter, r *http.Request
 	// 		p.logger.Error("error create athlete with bib from csv: ", strconv.Itoa(a.Bib), err)
 	// 	}
 	// }
-	count, err := p.service.CreateBulkAthletes(ctx, athletReqs)
+	v := validator.New()
+	count, err := p.service.CreateBulkAthletes(ctx, athletReqs, v)
 	if err != nil {
+		if errors.Is(err, validator.ErrValidation) {
+			failedValidationResponse(w, v)
+			return
+		}
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
 		p.logger.Error("error creating bulk athletes for race csv", "raceID", raceID, "error", err.Error())
 		errorResponse(w, http.StatusBadRequest, "error creating athletes from CSV")
 		return
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"gopkg.in/yaml.v3"
+)
+
+// RaceConfigVersion is version of race config documents written by export. Document without version is a plain
+// race config, e.g. test fixture, and is read as the current version.
+const RaceConfigVersion = 1
+
+// RaceConfigDocument is race config exported to move it between servers
+type RaceConfigDocument struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	*RaceModelDTO
+}
+
+func (d *RaceConfigDocument) Validate(ctx context.Context, v *validator.Validator) {
+	v.CheckField(d.Version >= 0 && d.Version <= RaceConfigVersion, "version", validator.CodeOutOfRange, fmt.Sprintf("must be %d or lower", RaceConfigVersion))
+	v.CheckField(d.RaceModelDTO != nil && d.RaceDTO != nil, "race_id", validator.CodeRequired, "race must be provided")
+	if !v.Valid() {
+		return
+	}
+	d.RaceModelDTO.Validate(ctx, v)
+}
+
+// YAMLToJSON converts YAML document to JSON, so it is decoded by the same json tags as JSON one
+func YAMLToJSON(data []byte) ([]byte, error) {
+	var doc any
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// JSONToYAML converts JSON document to YAML with keys named by json tags
+func JSONToYAML(data []byte) ([]byte, error) {
+	var doc any
+	err := json.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(doc)
+}
@@ -41,15 +41,20 @@ type SplitDTO struct {
 	MaxTime            string    `json:"max_time_sec"`
 	MinLapTime         string    `json:"min_lap_time_sec"`
 	PreviousLapSplitID uuid.NullUUID
+	CutoffTime         string `json:"cutoff_time_sec,omitempty"`
+	CutoffTOD          string `json:"cutoff_tod,omitempty"`
 }
 
 type WaveDTO struct {
-	ID         uuid.UUID `json:"wave_id"`
-	RaceID     uuid.UUID `json:"race_id"`
-	EventID    uuid.UUID `json:"event_id"`
-	Name       string    `json:"wave_name"`
-	StartTime  string    `json:"wave_start_time"`
-	IsLaunched bool      `json:"is_launched"`
+	ID              uuid.UUID     `json:"wave_id"`
+	RaceID          uuid.UUID     `json:"race_id"`
+	EventID         uuid.UUID     `json:"event_id"`
+	Name            string        `json:"wave_name"`
+	StartTime       string        `json:"wave_start_time"`
+	IsLaunched      bool          `json:"is_launched"`
+	TriggerChip     int           `json:"trigger_chip"`
+	TriggerReaderID uuid.NullUUID `json:"trigger_reader_id"`
+	AutoStart       bool          `json:"auto_start"`
 }
 
 type CategoryDTO struct {
@@ -57,11 +62,13 @@ type CategoryDTO struct {
 	RaceID       uuid.UUID `json:"race_id"`
 	EventID      uuid.UUID `json:"event_id"`
 	Name         string    `json:"category_name"`
+	Kind         string    `json:"kind"`
 	Gender       string    `json:"category_gender"`
 	AgeFrom      int       `json:"age_from"`
 	FromRaceDate bool      `json:"from_race_date"`
 	AgeTo        int       `json:"age_to"`
 	ToRaceDate   bool      `json:"to_race_date"`
+	Handicap     string    `json:"handicap"`
 }
 
 type RaceModelDTO struct {
@@ -87,93 +94,119 @@ func (rc RaceModelDTO) String() string {
 }`, rc.Name, rc.Name, rc.Timezone, rc.Events)
 }
 
+// Validate checks race config. Errors are reported at JSON path of the field in config,
+// e.g. events[2].splits[1].min_time
 func (rc *RaceModelDTO) Validate(ctx context.Context, v *validator.Validator) {
 	rc.validateRace(v)
 
-	v.Check(len(rc.TimeReaders) > 0, "time readers", "race must have at least one time reader")
-	if len(rc.TimeReaders) > 0 {
-		rc.validateTimeReaders(v)
-	}
+	v.CheckField(len(rc.TimeReaders) > 0, "time_readers", validator.CodeRequired, "race must have at least one time reader")
+	rc.validateTimeReaders(v)
 
-	v.Check(len(rc.Events) != 0, "events", "must be at least one")
-	if len(rc.Events) > 0 {
-		var eventNames []string
-		for _, e := range rc.Events {
-			eventNames = append(eventNames, e.Name)
-		}
-		v.Check(validator.Unique(eventNames), "event names", "must be unique")
-		for _, ec := range rc.Events {
-			validateEventConfig(v, rc.RaceDTO.ID, rc.TimeReaders, ec)
-		}
+	v.CheckField(len(rc.Events) != 0, "events", validator.CodeRequired, "must be at least one")
+	checkUnique(v, "events", "event_name", rc.Events, func(e *EventModelDTO) string { return e.Name })
+	for i, ec := range rc.Events {
+		validateEventConfig(v.Item("events", i), rc.RaceDTO.ID, rc.TimeReaders, ec)
 	}
 }
 
 func (rc *RaceModelDTO) validateRace(v *validator.Validator) {
-	v.Check(rc.ID != uuid.Nil, "race_id", "must be valid UUID")
-	v.Check(rc.Name != "", "race name", "must not be empty")
+	v.CheckField(rc.ID != uuid.Nil, "race_id", validator.CodeRequired, "must be valid UUID")
+	v.CheckField(rc.Name != "", "race_name", validator.CodeRequired, "must not be empty")
 }
 
 func (rc *RaceModelDTO) validateTimeReaders(v *validator.Validator) {
-	for _, r := range rc.TimeReaders {
-		v.Check(r.RaceID == rc.ID, "timers raceID", "must correspond to ID of configurated race")
+	for i, r := range rc.TimeReaders {
+		r.Validate(v.Item("time_readers", i), rc.ID)
 	}
 }
 
-func validateEventConfig(v *validator.Validator, raceID uuid.UUID, readers []*TimeReaderDTO, ec *EventModelDTO) {
-	v.Check(raceID == ec.RaceID, "race_id for event", "must correspond to ID of configurated race")
-	v.Check(ec.ID != uuid.Nil, "event_id", "must not be empty")
-	v.Check(ec.Name != "", "event_name", "must not be empty")
-	v.Check(ec.DistanceInMeters > 0, "event distance_in_meters", "must be greater than 0")
-	v.Check(validator.IsValidTime(time.RFC3339, ec.EventDate), "event_date", "must be date in RFC3339 format")
-
-	if len(ec.Splits) > 0 {
-		for _, split := range ec.Splits {
-			validateSplit(v, ec.RaceID, ec.ID, readers, split)
-		}
+// checkUnique reports items of list with the same key after the first one as not unique
+func checkUnique[T any](v *validator.Validator, list, key string, items []T, value func(T) string) {
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		val := value(item)
+		v.Item(list, i).CheckField(!seen[val], key, validator.CodeNotUnique, "must be unique")
+		seen[val] = true
 	}
+}
 
-	if len(ec.Waves) > 0 {
-		for _, w := range ec.Waves {
-			validateWave(v, raceID, ec.ID, w)
-		}
-	}
+// Validate checks event with its splits, waves and categories the same way as within whole race config
+func (ec *EventModelDTO) Validate(v *validator.Validator, raceID uuid.UUID, readers []*TimeReaderDTO) {
+	validateEventConfig(v, raceID, readers, ec)
+}
+
+// Validate checks event info without its splits, waves and categories
+func (e *EventDTO) Validate(v *validator.Validator, raceID uuid.UUID) {
+	v.CheckField(raceID == e.RaceID, "race_id", validator.CodeMismatch, "must correspond to ID of configurated race")
+	v.CheckField(e.ID != uuid.Nil, "event_id", validator.CodeRequired, "must not be empty")
+	v.CheckField(e.Name != "", "event_name", validator.CodeRequired, "must not be empty")
+	v.CheckField(e.DistanceInMeters > 0, "distance_in_meters", validator.CodeOutOfRange, "must be greater than 0")
+	v.CheckField(validator.IsValidTime(time.RFC3339, e.EventDate), "event_date", validator.CodeFormat, "must be date in RFC3339 format")
+}
+
+func (s *SplitDTO) Validate(v *validator.Validator, raceID, eventID uuid.UUID, readers []*TimeReaderDTO) {
+	validateSplit(v, raceID, eventID, readers, s)
+}
 
-	if len(ec.Categories) > 0 {
-		for _, c := range ec.Categories {
-			validateCategory(v, raceID, ec.ID, c)
-		}
+func (w *WaveDTO) Validate(v *validator.Validator, raceID, eventID uuid.UUID) {
+	validateWave(v, raceID, eventID, w)
+}
+
+func (c *CategoryDTO) Validate(v *validator.Validator, raceID, eventID uuid.UUID) {
+	validateCategory(v, raceID, eventID, c)
+}
+
+func (tr *TimeReaderDTO) Validate(v *validator.Validator, raceID uuid.UUID) {
+	v.CheckField(tr.RaceID == raceID, "race_id", validator.CodeMismatch, "must correspond to ID of configurated race")
+	v.CheckField(tr.ReaderName != "", "reader_name", validator.CodeRequired, "must not be empty")
+}
+
+func validateEventConfig(v *validator.Validator, raceID uuid.UUID, readers []*TimeReaderDTO, ec *EventModelDTO) {
+	if ec.EventDTO == nil {
+		v.AddFieldError("", validator.CodeRequired, "event must be provided")
+		return
+	}
+	ec.EventDTO.Validate(v, raceID)
+	for i, split := range ec.Splits {
+		validateSplit(v.Item("splits", i), ec.RaceID, ec.ID, readers, split)
+	}
+	for i, w := range ec.Waves {
+		validateWave(v.Item("waves", i), raceID, ec.ID, w)
+	}
+	for i, c := range ec.Categories {
+		validateCategory(v.Item("categories", i), raceID, ec.ID, c)
 	}
 }
 
 func validateCategory(v *validator.Validator, raceID, eventID uuid.UUID, c *CategoryDTO) {
-	v.Check(raceID != uuid.Nil, "category race_id", "must not be empty")
-	v.Check(raceID == c.RaceID, "category race_id", "must correspond to ID of configurated race")
-	v.Check(eventID != uuid.Nil, "category event_id", "must not be empty")
-	v.Check(eventID == c.EventID, "category event_id", "invalid event ID for category")
-	v.Check(c.Name != "", "category name", "must not be empty")
+	v.CheckField(raceID != uuid.Nil, "race_id", validator.CodeRequired, "must not be empty")
+	v.CheckField(raceID == c.RaceID, "race_id", validator.CodeMismatch, "must correspond to ID of configurated race")
+	v.CheckField(eventID != uuid.Nil, "event_id", validator.CodeRequired, "must not be empty")
+	v.CheckField(eventID == c.EventID, "event_id", validator.CodeMismatch, "invalid event ID for category")
+	v.CheckField(c.Name != "", "category_name", validator.CodeRequired, "must not be empty")
+	v.CheckField(c.Handicap == "" || validator.IsValidDuration(c.Handicap), "handicap", validator.CodeFormat, "must be duration string")
 }
 
 func validateWave(v *validator.Validator, raceID, eventID uuid.UUID, w *WaveDTO) {
-	v.Check(raceID != uuid.Nil, "wave race ID", "must not be null")
-	v.Check(raceID == w.RaceID, "wave's race ID", "must correspond to ID of configurated race")
-	v.Check(eventID != uuid.Nil, "wave's event ID", "must not be null")
-	v.Check(eventID == w.EventID, "wave's event ID", "must correspond to ID of configurated event")
-	v.Check(w.Name != "", "wave name", "must not be empty")
-	v.Check(validator.IsValidTime(time.RFC3339, w.StartTime), "start_time", "must be date in RFC3339 format")
+	v.CheckField(raceID != uuid.Nil, "race_id", validator.CodeRequired, "must not be null")
+	v.CheckField(raceID == w.RaceID, "race_id", validator.CodeMismatch, "must correspond to ID of configurated race")
+	v.CheckField(eventID != uuid.Nil, "event_id", validator.CodeRequired, "must not be null")
+	v.CheckField(eventID == w.EventID, "event_id", validator.CodeMismatch, "must correspond to ID of configurated event")
+	v.CheckField(w.Name != "", "wave_name", validator.CodeRequired, "must not be empty")
+	v.CheckField(validator.IsValidTime(time.RFC3339, w.StartTime), "wave_start_time", validator.CodeFormat, "must be date in RFC3339 format")
 }
 
 func validateSplit(v *validator.Validator, raceID, eventID uuid.UUID, readers []*TimeReaderDTO, split *SplitDTO) {
-	v.Check(raceID != uuid.Nil, "split's race ID", "must not be nil")
-	v.Check(raceID == split.RaceID, "split's race ID", "must correspond to ID of configurated race")
-	v.Check(eventID == split.EventID, "split's event ID", "must correspond to ID of configurated event")
-	v.Check(eventID != uuid.Nil, "split's event ID", "must not be null")
-	v.Check(split.Name != "", "split name", "must not be empty")
-	v.Check(split.Type != "", "split type", "must not be empty")
-	v.Check(
-		validator.IsValidDuration(split.MinTime) && validator.IsValidDuration(split.MaxTime) && validator.IsValidDuration(split.MinLapTime),
-		"min_max_min_lap_time",
-		"must be duration string",
-	)
-
-	v.Check(split.TimeReaderID.String() != "", "split ID", "must not be empty")
+	v.CheckField(raceID != uuid.Nil, "race_id", validator.CodeRequired, "must not be nil")
+	v.CheckField(raceID == split.RaceID, "race_id", validator.CodeMismatch, "must correspond to ID of configurated race")
+	v.CheckField(eventID == split.EventID, "event_id", validator.CodeMismatch, "must correspond to ID of configurated event")
+	v.CheckField(eventID != uuid.Nil, "event_id", validator.CodeRequired, "must not be null")
+	v.CheckField(split.Name != "", "split_name", validator.CodeRequired, "must not be empty")
+	v.CheckField(split.Type != "", "split_type", validator.CodeRequired, "must not be empty")
+	v.CheckField(validator.IsValidDuration(split.MinTime), "min_time_sec", validator.CodeFormat, "must be duration string")
+	v.CheckField(validator.IsValidDuration(split.MaxTime), "max_time_sec", validator.CodeFormat, "must be duration string")
+	v.CheckField(validator.IsValidDuration(split.MinLapTime), "min_lap_time_sec", validator.CodeFormat, "must be duration string")
+	v.CheckField(split.CutoffTime == "" || validator.IsValidDuration(split.CutoffTime), "cutoff_time_sec", validator.CodeFormat, "must be duration string")
+	v.CheckField(split.CutoffTOD == "" || validator.IsValidTime(time.RFC3339, split.CutoffTOD), "cutoff_tod", validator.CodeFormat, "must be date in RFC3339 format")
+	v.CheckField(split.TimeReaderID != uuid.Nil, "time_reader_id", validator.CodeRequired, "must not be empty")
 }
@@ -3,6 +3,8 @@ package httpv1
 import (
 	"fmt"
 	"net/http"
+
+	"github.com/ecoarchie/timeit/pkg/validator"
 )
 
 func errorResponse(w http.ResponseWriter, status int, message any) {
@@ -28,6 +30,17 @@ func methodNotAllowedResponse(w http.ResponseWriter, r *http.Request) {
 	errorResponse(w, http.StatusMethodNotAllowed, message)
 }
 
-func failedValidationResponse(w http.ResponseWriter, errors map[string]string) {
-	errorResponse(w, http.StatusUnprocessableEntity, errors)
+// failedValidationResponse reports the first message for every invalid field in "error" and all errors
+// with their codes in "fields", so clients can highlight the field at path of every error
+func failedValidationResponse(w http.ResponseWriter, v *validator.Validator) {
+	mes := map[string]any{"error": v.Errors, "fields": v.FieldErrors()}
+	err := writeJSON(w, http.StatusUnprocessableEntity, mes, nil)
+	if err != nil {
+		w.WriteHeader(500)
+	}
+}
+
+// raceStatusConflictResponse reports operation not allowed in current status of the race
+func raceStatusConflictResponse(w http.ResponseWriter, err error) {
+	errorResponse(w, http.StatusConflict, err.Error())
 }
@@ -0,0 +1,82 @@
+package httpv1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+)
+
+// maxBackupSize limits size of uploaded backup archive
+const maxBackupSize = 1_048_576 * 512
+
+// backupRace writes zip archive with all data of the race
+func (rr *raceRoutes) backupRace(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	b, err := rr.conf.BackupRace(context.Background(), ids[0])
+	if err != nil {
+		serverErrorResponse(w, err)
+		return
+	}
+	if b == nil {
+		errorResponse(w, http.StatusNotFound, "race not found")
+		return
+	}
+	var buf bytes.Buffer
+	err = b.WriteZip(&buf)
+	if err != nil {
+		serverErrorResponse(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="race-%s-%s.zip"`, b.RaceID, b.CreatedAt.UTC().Format("20060102-150405")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// restoreRace restores race from backup archive sent as request body
+func (rr *raceRoutes) restoreRace(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	force := queryBool(r, v, "force")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBackupSize))
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	b, err := entity.ReadRaceBackup(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	race, err := rr.conf.RestoreRace(context.Background(), b, force)
+	if err != nil {
+		rr.log.Error("error restoring race", "error", err)
+		switch {
+		case errors.Is(err, entity.ErrRaceBackup):
+			errorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, entity.ErrRaceBackupConflict):
+			errorResponse(w, http.StatusConflict, err.Error())
+		case errors.Is(err, entity.ErrRaceStatus):
+			raceStatusConflictResponse(w, err)
+		default:
+			serverErrorResponse(w, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"race": race, "rows": b.Rows}, nil)
+}
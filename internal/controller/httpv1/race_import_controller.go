@@ -0,0 +1,123 @@
+package httpv1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// exportRaceConfig writes race config as JSON or, with format=yaml, as YAML file
+func (rr *raceRoutes) exportRaceConfig(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id")
+	format := r.URL.Query().Get("format")
+	v.Check(validator.PermittedValue(format, "", "json", "yaml"), "format", "must be json or yaml")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	doc, err := rr.conf.ExportRaceConfig(context.Background(), ids[0])
+	if err != nil {
+		serverErrorResponse(w, err)
+		return
+	}
+	if doc == nil {
+		errorResponse(w, http.StatusNotFound, "race not found")
+		return
+	}
+	data, err := json.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		serverErrorResponse(w, err)
+		return
+	}
+	contentType, ext := "application/json", "json"
+	if format == "yaml" {
+		data, err = dto.JSONToYAML(data)
+		if err != nil {
+			serverErrorResponse(w, err)
+			return
+		}
+		contentType, ext = "application/yaml", "yaml"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="race-%s.%s"`, doc.ID, ext))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// importRaceConfig saves exported race config. YAML document is read when format=yaml or it is sent
+// with YAML content type. With race_id the config is mapped onto that race.
+func (rr *raceRoutes) importRaceConfig(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	query := r.URL.Query()
+	format := query.Get("format")
+	v.Check(validator.PermittedValue(format, "", "json", "yaml"), "format", "must be json or yaml")
+	rID := query.Get("race_id")
+	v.Check(rID == "" || validator.IsUUID(rID), "race_id", "must be valid uuid")
+	dryRun := queryBool(r, v, "dry_run")
+	force := queryBool(r, v, "force")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	var targetID uuid.UUID
+	if rID != "" {
+		targetID = uuid.MustParse(rID)
+	}
+
+	if format == "yaml" || (format == "" && strings.Contains(r.Header.Get("Content-Type"), "yaml")) {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 1_048_576*5))
+		if err != nil {
+			errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		data, err = dto.YAMLToJSON(data)
+		if err != nil {
+			errorResponse(w, http.StatusBadRequest, fmt.Sprintf("body contains badly-formed YAML: %s", err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+	}
+	var doc dto.RaceConfigDocument
+	err := readJSON(w, r, &doc)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	diff, reassigned, err := rr.conf.ImportRaceConfig(context.Background(), &doc, targetID, dryRun, force, v)
+	if err != nil {
+		rr.log.Error("error importing race config", "error", err)
+		switch {
+		case errors.Is(err, validator.ErrValidation):
+			failedValidationResponse(w, v)
+		case errors.Is(err, entity.ErrRaceStatus):
+			raceStatusConflictResponse(w, err)
+		default:
+			serverErrorResponse(w, err)
+		}
+		return
+	}
+	if diff == nil {
+		errorResponse(w, http.StatusNotFound, "race not found")
+		return
+	}
+	res := map[string]any{"race_id": diff.RaceID, "diff": diff}
+	if dryRun {
+		res["dry_run"] = true
+	}
+	if len(reassigned) != 0 {
+		res["categories_reassigned"] = reassigned
+	}
+	writeJSON(w, http.StatusOK, res, nil)
+}
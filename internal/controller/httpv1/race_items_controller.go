@@ -0,0 +1,307 @@
+package httpv1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (rr *raceRoutes) createEvent(w http.ResponseWriter, r *http.Request) {
+	var req dto.EventModelDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	event, err := rr.conf.CreateEvent(context.Background(), ids[0], &req, v)
+	itemResponse(w, http.StatusCreated, event, err, v, "race not found")
+}
+
+func (rr *raceRoutes) updateEvent(w http.ResponseWriter, r *http.Request) {
+	var req dto.EventDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	event, err := rr.conf.UpdateEvent(context.Background(), ids[0], ids[1], &req, v)
+	itemResponse(w, http.StatusOK, event, err, v, "event not found")
+}
+
+func (rr *raceRoutes) deleteEvent(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id")
+	force := queryBool(r, v, "force")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	found, err := rr.conf.DeleteEvent(context.Background(), ids[0], ids[1], force)
+	deleteResponse(w, found, err, v, "event not found")
+}
+
+func (rr *raceRoutes) createSplit(w http.ResponseWriter, r *http.Request) {
+	var req dto.SplitDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	split, err := rr.conf.CreateSplit(context.Background(), ids[0], ids[1], &req, v)
+	itemResponse(w, http.StatusCreated, split, err, v, "event not found")
+}
+
+func (rr *raceRoutes) updateSplit(w http.ResponseWriter, r *http.Request) {
+	var req dto.SplitDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id", "split_id")
+	force := queryBool(r, v, "force")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	split, err := rr.conf.UpdateSplit(context.Background(), ids[0], ids[1], ids[2], &req, force, v)
+	itemResponse(w, http.StatusOK, split, err, v, "split not found")
+}
+
+func (rr *raceRoutes) deleteSplit(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id", "split_id")
+	force := queryBool(r, v, "force")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	found, err := rr.conf.DeleteSplit(context.Background(), ids[0], ids[1], ids[2], force, v)
+	deleteResponse(w, found, err, v, "split not found")
+}
+
+func (rr *raceRoutes) createWave(w http.ResponseWriter, r *http.Request) {
+	var req dto.WaveDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	wave, err := rr.conf.CreateWave(context.Background(), ids[0], ids[1], &req, v)
+	itemResponse(w, http.StatusCreated, wave, err, v, "event not found")
+}
+
+func (rr *raceRoutes) updateWave(w http.ResponseWriter, r *http.Request) {
+	var req dto.WaveDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id", "wave_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	wave, err := rr.conf.UpdateWave(context.Background(), ids[0], ids[1], ids[2], &req, v)
+	itemResponse(w, http.StatusOK, wave, err, v, "wave not found")
+}
+
+func (rr *raceRoutes) deleteWave(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id", "wave_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	found, err := rr.conf.DeleteWave(context.Background(), ids[0], ids[1], ids[2], v)
+	deleteResponse(w, found, err, v, "wave not found")
+}
+
+func (rr *raceRoutes) createCategory(w http.ResponseWriter, r *http.Request) {
+	var req dto.CategoryDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	category, err := rr.conf.CreateCategory(context.Background(), ids[0], ids[1], &req, v)
+	itemResponse(w, http.StatusCreated, category, err, v, "event not found")
+}
+
+func (rr *raceRoutes) updateCategory(w http.ResponseWriter, r *http.Request) {
+	var req dto.CategoryDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id", "category_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	category, err := rr.conf.UpdateCategory(context.Background(), ids[0], ids[1], ids[2], &req, v)
+	itemResponse(w, http.StatusOK, category, err, v, "category not found")
+}
+
+func (rr *raceRoutes) deleteCategory(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "event_id", "category_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	found, err := rr.conf.DeleteCategory(context.Background(), ids[0], ids[1], ids[2], v)
+	deleteResponse(w, found, err, v, "category not found")
+}
+
+func (rr *raceRoutes) createTimeReader(w http.ResponseWriter, r *http.Request) {
+	var req dto.TimeReaderDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	reader, err := rr.conf.CreateTimeReader(context.Background(), ids[0], &req, v)
+	itemResponse(w, http.StatusCreated, reader, err, v, "race not found")
+}
+
+func (rr *raceRoutes) updateTimeReader(w http.ResponseWriter, r *http.Request) {
+	var req dto.TimeReaderDTO
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "time_reader_id")
+	force := queryBool(r, v, "force")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	reader, err := rr.conf.UpdateTimeReader(context.Background(), ids[0], ids[1], &req, force, v)
+	itemResponse(w, http.StatusOK, reader, err, v, "time reader not found")
+}
+
+func (rr *raceRoutes) deleteTimeReader(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id", "time_reader_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	found, err := rr.conf.DeleteTimeReader(context.Background(), ids[0], ids[1], v)
+	deleteResponse(w, found, err, v, "time reader not found")
+}
+
+// pathIDs returns UUID URL params of request in the order of names, invalid params are added to v
+func pathIDs(r *http.Request, v *validator.Validator, names ...string) []uuid.UUID {
+	ids := make([]uuid.UUID, len(names))
+	for i, name := range names {
+		param := chi.URLParam(r, name)
+		if !validator.IsUUID(param) {
+			v.AddError(name, "must be provided and be valid uuid")
+			continue
+		}
+		ids[i] = uuid.MustParse(param)
+	}
+	return ids
+}
+
+// queryBool returns boolean query param of request, false if it is not set. Invalid value is added to v.
+func queryBool(r *http.Request, v *validator.Validator, name string) bool {
+	param := r.URL.Query().Get(name)
+	if param == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(param)
+	v.Check(err == nil, name, "must be true or false")
+	return b
+}
+
+// itemResponse writes created or changed item of race config, nil item means it or its race or event is not found
+func itemResponse[T any](w http.ResponseWriter, status int, item *T, err error, v *validator.Validator, notFound string) {
+	if err != nil {
+		switch {
+		case errors.Is(err, validator.ErrValidation):
+			failedValidationResponse(w, v)
+		case errors.Is(err, entity.ErrRaceStatus):
+			raceStatusConflictResponse(w, err)
+		default:
+			serverErrorResponse(w, err)
+		}
+		return
+	}
+	if item == nil {
+		errorResponse(w, http.StatusNotFound, notFound)
+		return
+	}
+	writeJSON(w, status, item, nil)
+}
+
+func deleteResponse(w http.ResponseWriter, found bool, err error, v *validator.Validator, notFound string) {
+	if err != nil {
+		switch {
+		case errors.Is(err, validator.ErrValidation):
+			failedValidationResponse(w, v)
+		case errors.Is(err, entity.ErrRaceStatus):
+			raceStatusConflictResponse(w, err)
+		default:
+			serverErrorResponse(w, err)
+		}
+		return
+	}
+	if !found {
+		errorResponse(w, http.StatusNotFound, notFound)
+		return
+	}
+	writeJSON(w, http.StatusNoContent, nil, nil)
+}
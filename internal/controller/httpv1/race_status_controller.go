@@ -0,0 +1,74 @@
+package httpv1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+)
+
+func (rr *raceRoutes) getRaceStatus(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	race, err := rr.conf.GetRace(context.Background(), ids[0])
+	if err != nil {
+		serverErrorResponse(w, err)
+		return
+	}
+	if race == nil {
+		errorResponse(w, http.StatusNotFound, "race not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"race_id": race.ID, "status": race.Status}, nil)
+}
+
+// changeRaceStatus moves race to the next stage of its lifecycle
+func (rr *raceRoutes) changeRaceStatus(w http.ResponseWriter, r *http.Request) {
+	var req entity.RaceStatusRequest
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	c, err := rr.conf.ChangeRaceStatus(context.Background(), ids[0], req, v)
+	if err != nil {
+		if errors.Is(err, validator.ErrValidation) {
+			failedValidationResponse(w, v)
+			return
+		}
+		serverErrorResponse(w, err)
+		return
+	}
+	if c == nil {
+		errorResponse(w, http.StatusNotFound, "race not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, c, nil)
+}
+
+func (rr *raceRoutes) getRaceStatusHistory(w http.ResponseWriter, r *http.Request) {
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	changes, err := rr.conf.GetRaceStatusHistory(context.Background(), ids[0])
+	if err != nil {
+		serverErrorResponse(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, changes, nil)
+}
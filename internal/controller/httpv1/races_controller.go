@@ -28,11 +28,38 @@ func newRaceRoutes(log *logger.Logger, conf service.RaceConfigurator) http.Handl
 	r := chi.NewRouter()
 	r.Get("/", rr.getRaces)
 	r.Post("/", rr.createRace)
+	r.Post("/import", rr.importRaceConfig)
+	r.Post("/restore", rr.restoreRace)
 	r.Get("/{race_id}", rr.getRaceConfig)
 	r.Post("/{race_id}", rr.saveRaceConfig)
 	r.Delete("/{race_id}", rr.deleteRace)
+	r.Post("/{race_id}/clone", rr.cloneRace)
+	r.Get("/{race_id}/export", rr.exportRaceConfig)
+	r.Get("/{race_id}/backup", rr.backupRace)
+	r.Get("/{race_id}/status", rr.getRaceStatus)
+	r.Post("/{race_id}/status", rr.changeRaceStatus)
+	r.Get("/{race_id}/status/history", rr.getRaceStatusHistory)
 	r.Get("/{race_id}/waves", rr.getWavesForRace)
 	r.Post("/{race_id}/waves/start", rr.startWave)
+	r.Post("/{race_id}/waves/launch", rr.launchWaves)
+	r.Get("/{race_id}/waves/audit", rr.getWaveAudit)
+	r.Post("/{race_id}/waves/{wave_id}/reset", rr.resetWave)
+	r.Put("/{race_id}/waves/{wave_id}/start_time", rr.adjustWaveStart)
+	r.Post("/{race_id}/events", rr.createEvent)
+	r.Put("/{race_id}/events/{event_id}", rr.updateEvent)
+	r.Delete("/{race_id}/events/{event_id}", rr.deleteEvent)
+	r.Post("/{race_id}/events/{event_id}/splits", rr.createSplit)
+	r.Put("/{race_id}/events/{event_id}/splits/{split_id}", rr.updateSplit)
+	r.Delete("/{race_id}/events/{event_id}/splits/{split_id}", rr.deleteSplit)
+	r.Post("/{race_id}/events/{event_id}/waves", rr.createWave)
+	r.Put("/{race_id}/events/{event_id}/waves/{wave_id}", rr.updateWave)
+	r.Delete("/{race_id}/events/{event_id}/waves/{wave_id}", rr.deleteWave)
+	r.Post("/{race_id}/events/{event_id}/categories", rr.createCategory)
+	r.Put("/{race_id}/events/{event_id}/categories/{category_id}", rr.updateCategory)
+	r.Delete("/{race_id}/events/{event_id}/categories/{category_id}", rr.deleteCategory)
+	r.Post("/{race_id}/time_readers", rr.createTimeReader)
+	r.Put("/{race_id}/time_readers/{time_reader_id}", rr.updateTimeReader)
+	r.Delete("/{race_id}/time_readers/{time_reader_id}", rr.deleteTimeReader)
 	return r
 }
 
@@ -48,11 +75,15 @@ func (rr *raceRoutes) startWave(w http.ResponseWriter, r *http.Request) {
 	v.Check(validator.IsUUID(rID), "race_id", "must be provided and be valid uuid")
 	v.Check(validator.IsUUID(waveStart.WaveID.String()), "wave_id", "must be provided and be valid uuid")
 	if !v.Valid() {
-		failedValidationResponse(w, v.Errors)
+		failedValidationResponse(w, v)
 		return
 	}
 	startTime, waveFound, err := rr.conf.StartWave(context.Background(), uuid.MustParse(rID), waveStart)
 	if err != nil {
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
 		serverErrorResponse(w, err)
 		return
 	}
@@ -66,6 +97,127 @@ func (rr *raceRoutes) startWave(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, res, nil)
 }
 
+func (rr *raceRoutes) launchWaves(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	var req entity.WavesLaunch
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be provided and be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	waves, err := rr.conf.LaunchWaves(context.Background(), uuid.MustParse(rID), req, v)
+	if err != nil {
+		switch {
+		case errors.Is(err, validator.ErrValidation):
+			failedValidationResponse(w, v)
+		case errors.Is(err, entity.ErrRaceStatus):
+			raceStatusConflictResponse(w, err)
+		case errors.Is(err, entity.ErrWaveChanged):
+			errorResponse(w, http.StatusConflict, err.Error())
+		default:
+			serverErrorResponse(w, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, waves, nil)
+}
+
+func (rr *raceRoutes) resetWave(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	wID := chi.URLParam(r, "wave_id")
+	var req entity.WaveReset
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be provided and be valid uuid")
+	v.Check(validator.IsUUID(wID), "wave_id", "must be provided and be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	wave, err := rr.conf.ResetWave(context.Background(), uuid.MustParse(rID), uuid.MustParse(wID), req, v)
+	if err != nil {
+		switch {
+		case errors.Is(err, validator.ErrValidation):
+			failedValidationResponse(w, v)
+		case errors.Is(err, entity.ErrRaceStatus):
+			raceStatusConflictResponse(w, err)
+		case errors.Is(err, entity.ErrWaveChanged):
+			errorResponse(w, http.StatusConflict, err.Error())
+		default:
+			serverErrorResponse(w, err)
+		}
+		return
+	}
+	if wave == nil {
+		errorResponse(w, http.StatusNotFound, "wave not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, wave, nil)
+}
+
+func (rr *raceRoutes) adjustWaveStart(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	wID := chi.URLParam(r, "wave_id")
+	var req entity.WaveAdjust
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be provided and be valid uuid")
+	v.Check(validator.IsUUID(wID), "wave_id", "must be provided and be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	wave, err := rr.conf.AdjustWaveStart(context.Background(), uuid.MustParse(rID), uuid.MustParse(wID), req, v)
+	if err != nil {
+		switch {
+		case errors.Is(err, validator.ErrValidation):
+			failedValidationResponse(w, v)
+		case errors.Is(err, entity.ErrRaceStatus):
+			raceStatusConflictResponse(w, err)
+		case errors.Is(err, entity.ErrWaveChanged):
+			errorResponse(w, http.StatusConflict, err.Error())
+		default:
+			serverErrorResponse(w, err)
+		}
+		return
+	}
+	if wave == nil {
+		errorResponse(w, http.StatusNotFound, "wave not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, wave, nil)
+}
+
+func (rr *raceRoutes) getWaveAudit(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be provided and be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	entries, err := rr.conf.GetWaveAudit(context.Background(), uuid.MustParse(rID))
+	if err != nil {
+		serverErrorResponse(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, entries, nil)
+}
+
 func (rr *raceRoutes) getWavesForRace(w http.ResponseWriter, r *http.Request) {
 	rID := chi.URLParam(r, "race_id")
 
@@ -73,7 +225,7 @@ func (rr *raceRoutes) getWavesForRace(w http.ResponseWriter, r *http.Request) {
 	v.Check(rID != "", "race_id", "must be provided ")
 	v.Check(validator.IsUUID(rID), "race_id", "must be valid UUID")
 	if !v.Valid() {
-		failedValidationResponse(w, v.Errors)
+		failedValidationResponse(w, v)
 		return
 	}
 	waves, err := rr.conf.GetWavesForRace(context.Background(), uuid.MustParse(rID))
@@ -90,20 +242,43 @@ func (rr *raceRoutes) getWavesForRace(w http.ResponseWriter, r *http.Request) {
 
 func (rr *raceRoutes) deleteRace(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "race_id")
-	v := *validator.New()
+	v := validator.New()
 	v.Check(validator.IsUUID(id), "race_id", "must be valid uuid")
+	force := queryBool(r, v, "force")
 	if !v.Valid() {
-		failedValidationResponse(w, v.Errors)
+		failedValidationResponse(w, v)
 		return
 	}
-	err := rr.conf.DeleteRace(context.Background(), uuid.MustParse(id))
+	err := rr.conf.DeleteRace(context.Background(), uuid.MustParse(id), force)
 	if err != nil {
+		if errors.Is(err, entity.ErrRaceStatus) {
+			raceStatusConflictResponse(w, err)
+			return
+		}
 		serverErrorResponse(w, err)
 		return
 	}
 	writeJSON(w, http.StatusNoContent, nil, nil)
 }
 
+// cloneRace copies race config as a template for the next edition of the race
+func (rr *raceRoutes) cloneRace(w http.ResponseWriter, r *http.Request) {
+	var req entity.RaceCloneRequest
+	err := readJSON(w, r, &req)
+	if err != nil {
+		errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	v := validator.New()
+	ids := pathIDs(r, v, "race_id")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	clone, err := rr.conf.CloneRace(context.Background(), ids[0], req, v)
+	itemResponse(w, http.StatusCreated, clone, err, v, "race not found")
+}
+
 func (rr *raceRoutes) getRaces(w http.ResponseWriter, r *http.Request) {
 	races, err := rr.conf.GetRaces(context.Background())
 	if err != nil {
@@ -119,10 +294,10 @@ func (rr *raceRoutes) getRaces(w http.ResponseWriter, r *http.Request) {
 
 func (rr *raceRoutes) getRaceConfig(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "race_id")
-	v := *validator.New()
+	v := validator.New()
 	v.Check(validator.IsUUID(id), "race_id", "must be valid uuid")
 	if !v.Valid() {
-		failedValidationResponse(w, v.Errors)
+		failedValidationResponse(w, v)
 		return
 	}
 	cfg, err := rr.conf.GetRaceConfig(context.Background(), uuid.MustParse(id))
@@ -153,7 +328,7 @@ func (rr *raceRoutes) createRace(w http.ResponseWriter, r *http.Request) {
 		rr.log.Error("error creating race", "error", err)
 		if errors.Is(err, validator.ErrValidation) {
 			rr.log.Error("error validating race config to save")
-			failedValidationResponse(w, v.Errors)
+			failedValidationResponse(w, v)
 			return
 		}
 		serverErrorResponse(w, err)
@@ -175,22 +350,35 @@ func (rr *raceRoutes) saveRaceConfig(w http.ResponseWriter, r *http.Request) {
 
 	v := validator.New()
 	raceConfig.Validate(ctx, v)
+	dryRun := queryBool(r, v, "dry_run")
+	force := queryBool(r, v, "force")
 
 	if !v.Valid() {
-		failedValidationResponse(w, v.Errors)
+		failedValidationResponse(w, v)
 		return
 	}
-	err = rr.conf.SaveRaceConfig(ctx, raceConfig, v)
+	diff, reassigned, err := rr.conf.SaveRaceConfig(ctx, raceConfig, dryRun, force, v)
 	if err != nil {
 		mes := "error saving race config"
 		rr.log.Error(mes, "race config", err)
-		if errors.Is(err, validator.ErrValidation) {
-			failedValidationResponse(w, v.Errors)
-			return
+		switch {
+		case errors.Is(err, validator.ErrValidation):
+			failedValidationResponse(w, v)
+		case errors.Is(err, entity.ErrRaceStatus):
+			raceStatusConflictResponse(w, err)
+		default:
+			serverErrorResponse(w, err)
 		}
-		serverErrorResponse(w, err)
+		return
+	}
+	if dryRun {
+		writeJSON(w, http.StatusOK, map[string]any{"dry_run": true, "diff": diff}, nil)
 		return
 	}
 	rr.log.Info("Config for race saved")
-	writeJSON(w, http.StatusNoContent, "", nil)
+	res := map[string]any{"diff": diff}
+	if len(reassigned) != 0 {
+		res["categories_reassigned"] = reassigned
+	}
+	writeJSON(w, http.StatusOK, res, nil)
 }
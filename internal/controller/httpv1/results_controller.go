@@ -2,35 +2,377 @@ package httpv1
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
+	"github.com/ecoarchie/timeit/internal/entity"
 	"github.com/ecoarchie/timeit/internal/service"
 	"github.com/ecoarchie/timeit/pkg/logger"
+	"github.com/ecoarchie/timeit/pkg/validator"
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type resultsRoutes struct {
-	service service.ResultsManager
-	logger  *logger.Logger
+	service   service.ResultsManager
+	scheduler service.RecalcScheduler
+	logger    *logger.Logger
 }
 
-func newResultsRoutes(logger *logger.Logger, service service.ResultsManager) http.Handler {
+func newResultsRoutes(logger *logger.Logger, service service.ResultsManager, scheduler service.RecalcScheduler) http.Handler {
 	logger.Info("creating new race routes")
 	rr := &resultsRoutes{
-		service: service,
-		logger:  logger,
+		service:   service,
+		scheduler: scheduler,
+		logger:    logger,
 	}
 	r := chi.NewRouter()
 	r.Get("/", rr.getResults)
 	r.Get("/calculate", rr.calculateResults)
+	r.Get("/agegraded", rr.getAgeGradedResults)
+	r.Get("/handicap", rr.getHandicapResults)
+	r.Get("/predictions", rr.getPredictions)
+	r.Get("/expected", rr.getExpectedAtSplit)
+	r.Get("/overdue", rr.getOverdueReport)
+	r.Get("/diagnostics/{athlete_id}", rr.getAthleteDiagnostics)
+	r.Get("/categories/{category_id}", rr.getCategoryResults)
+	r.Get("/overdue/stream", rr.streamOverdueReport)
+	r.Get("/recalc", rr.getRecalcStatus)
+	r.Post("/recalc/pause", rr.pauseRecalc)
+	r.Post("/recalc/resume", rr.resumeRecalc)
 	return r
 }
 
+func (p resultsRoutes) getRecalcStatus(w http.ResponseWriter, r *http.Request) {
+	p.recalcResponse(w, r, "Get recalc status: ", p.scheduler.Status)
+}
+
+func (p resultsRoutes) pauseRecalc(w http.ResponseWriter, r *http.Request) {
+	p.recalcResponse(w, r, "Pause recalc: ", p.scheduler.Pause)
+}
+
+func (p resultsRoutes) resumeRecalc(w http.ResponseWriter, r *http.Request) {
+	p.recalcResponse(w, r, "Resume recalc: ", p.scheduler.Resume)
+}
+
+// recalcResponse applies scheduler action to the race and writes resulting recalculation status
+func (p resultsRoutes) recalcResponse(w http.ResponseWriter, r *http.Request, msg string, action func(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error)) {
+	rID := chi.URLParam(r, "race_id")
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	res, err := action(r.Context(), uuid.MustParse(rID))
+	if err != nil {
+		p.logger.Error(msg, "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if res == nil {
+		notFoundResponse(w, r)
+		return
+	}
+	err = writeJSON(w, http.StatusOK, res, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+func (p resultsRoutes) getAthleteDiagnostics(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	aID := chi.URLParam(r, "athlete_id")
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(validator.IsUUID(aID), "athlete_id", "must be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	res, err := p.service.GetAthleteDiagnostics(r.Context(), uuid.MustParse(rID), uuid.MustParse(aID))
+	if err != nil {
+		p.logger.Error("Get athlete diagnostics: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if res == nil {
+		notFoundResponse(w, r)
+		return
+	}
+	err = writeJSON(w, http.StatusOK, res, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+// getCategoryResults returns category ranking at the split given by split_id query parameter, at finish by default
+func (p resultsRoutes) getCategoryResults(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	cID := chi.URLParam(r, "category_id")
+	sID := r.URL.Query().Get("split_id")
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(validator.IsUUID(cID), "category_id", "must be valid uuid")
+	v.Check(sID == "" || validator.IsUUID(sID), "split_id", "must be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	var splitID uuid.UUID
+	if sID != "" {
+		splitID = uuid.MustParse(sID)
+	}
+	res, err := p.service.GetCategoryResults(r.Context(), uuid.MustParse(rID), uuid.MustParse(cID), splitID)
+	if err != nil {
+		p.logger.Error("Get category results: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if res == nil {
+		notFoundResponse(w, r)
+		return
+	}
+	err = writeJSON(w, http.StatusOK, res, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+const defaultOverdueTolerance = 1.5
+
+func parseOverdueTolerance(r *http.Request, v *validator.Validator) float64 {
+	tolerance := defaultOverdueTolerance
+	if t := r.URL.Query().Get("tolerance"); t != "" {
+		var err error
+		tolerance, err = strconv.ParseFloat(t, 64)
+		v.Check(err == nil && tolerance >= 1, "tolerance", "must be number not less than 1")
+	}
+	return tolerance
+}
+
+func (p resultsRoutes) getOverdueReport(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	tolerance := parseOverdueTolerance(r, v)
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	report, err := p.service.GetOverdueReport(r.Context(), uuid.MustParse(rID), tolerance)
+	if err != nil {
+		p.logger.Error("Get overdue report: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if report == nil {
+		notFoundResponse(w, r)
+		return
+	}
+	err = writeJSON(w, http.StatusOK, report, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+// streamOverdueReport sends overdue report as server-sent events every interval seconds until client disconnects.
+func (p resultsRoutes) streamOverdueReport(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	interval := 30
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	tolerance := parseOverdueTolerance(r, v)
+	if is := r.URL.Query().Get("interval"); is != "" {
+		var err error
+		interval, err = strconv.Atoi(is)
+		v.Check(err == nil && interval >= 5, "interval", "must be number of seconds not less than 5")
+	}
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	raceID := uuid.MustParse(rID)
+
+	rc := http.NewResponseController(w)
+	// stream lives longer than server write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		errorResponse(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		report, err := p.service.GetOverdueReport(r.Context(), raceID, tolerance)
+		if err != nil {
+			if r.Context().Err() != nil {
+				return
+			}
+			p.logger.Error("Stream overdue report: ", "err", err.Error())
+			fmt.Fprintf(w, "event: error\ndata: %q\n\n", err.Error())
+		} else {
+			js, err := json.Marshal(report)
+			if err != nil {
+				p.logger.Error("Stream overdue report: ", "err", err.Error())
+				return
+			}
+			fmt.Fprintf(w, "event: overdue\ndata: %s\n\n", js)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p resultsRoutes) getPredictions(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	eID := r.URL.Query().Get("event_id")
+	aID := r.URL.Query().Get("athlete_id")
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(validator.IsUUID(eID), "event_id", "must be provided and be valid uuid")
+	v.Check(aID == "" || validator.IsUUID(aID), "athlete_id", "must be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	res, err := p.service.GetPredictions(r.Context(), uuid.MustParse(rID), uuid.MustParse(eID))
+	if err != nil {
+		p.logger.Error("Get predictions: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if res == nil {
+		errorResponse(w, http.StatusNotFound, "event not found")
+		return
+	}
+	if aID != "" {
+		athleteID := uuid.MustParse(aID)
+		idx := slices.IndexFunc(res, func(ap *entity.AthletePrediction) bool {
+			return ap.AthleteID == athleteID
+		})
+		if idx == -1 {
+			errorResponse(w, http.StatusNotFound, "no prediction for athlete")
+			return
+		}
+		res = res[idx : idx+1]
+	}
+	err = writeJSON(w, http.StatusOK, res, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+func (p resultsRoutes) getExpectedAtSplit(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	sID := r.URL.Query().Get("split_id")
+	within := 10
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(validator.IsUUID(sID), "split_id", "must be provided and be valid uuid")
+	if wm := r.URL.Query().Get("within"); wm != "" {
+		var err error
+		within, err = strconv.Atoi(wm)
+		v.Check(err == nil && within > 0, "within", "must be positive number of minutes")
+	}
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	res, err := p.service.GetExpectedAtSplit(r.Context(), uuid.MustParse(rID), uuid.MustParse(sID), time.Duration(within)*time.Minute)
+	if err != nil {
+		p.logger.Error("Get athletes expected at split: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if res == nil {
+		errorResponse(w, http.StatusNotFound, "split not found")
+		return
+	}
+	err = writeJSON(w, http.StatusOK, res, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+func (p resultsRoutes) getAgeGradedResults(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	eID := r.URL.Query().Get("event_id")
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(validator.IsUUID(eID), "event_id", "must be provided and be valid uuid")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	res, err := p.service.GetAgeGradedResults(r.Context(), uuid.MustParse(rID), uuid.MustParse(eID))
+	if err != nil {
+		p.logger.Error("Get age graded results: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if res == nil {
+		errorResponse(w, http.StatusNotFound, "event not found")
+		return
+	}
+	err = writeJSON(w, http.StatusOK, res, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
+// getHandicapResults returns finish results with handicapped and scratch times ranked on basis query parameter,
+// on handicapped time by default
+func (p resultsRoutes) getHandicapResults(w http.ResponseWriter, r *http.Request) {
+	rID := chi.URLParam(r, "race_id")
+	eID := r.URL.Query().Get("event_id")
+	basis := entity.HandicapBasis(r.URL.Query().Get("basis"))
+	if basis == "" {
+		basis = entity.HandicapBasisHandicap
+	}
+	v := validator.New()
+	v.Check(validator.IsUUID(rID), "race_id", "must be valid uuid")
+	v.Check(validator.IsUUID(eID), "event_id", "must be provided and be valid uuid")
+	v.Check(entity.IsValidHandicapBasis(basis), "basis", "must be handicap or scratch")
+	if !v.Valid() {
+		failedValidationResponse(w, v)
+		return
+	}
+	res, err := p.service.GetHandicapResults(r.Context(), uuid.MustParse(rID), uuid.MustParse(eID), basis)
+	if err != nil {
+		p.logger.Error("Get handicap results: ", "err", err.Error())
+		serverErrorResponse(w, err)
+		return
+	}
+	if res == nil {
+		errorResponse(w, http.StatusNotFound, "event not found")
+		return
+	}
+	err = writeJSON(w, http.StatusOK, res, nil)
+	if err != nil {
+		serverErrorResponse(w, err)
+	}
+}
+
 func (p resultsRoutes) getResults(w http.ResponseWriter, r *http.Request) {
 	rID := chi.URLParam(r, "race_id")
 	raceID, _ := uuid.Parse(rID)
-	res, err := p.service.GetSplitResults(context.Background(), raceID)
+	res, err := p.service.GetSplitResults(r.Context(), raceID)
 	if err != nil {
 		p.logger.Error("Get splits results: ", "err", err.Error())
 		serverErrorResponse(w, err)
@@ -45,8 +387,12 @@ func (p resultsRoutes) getResults(w http.ResponseWriter, r *http.Request) {
 func (p resultsRoutes) calculateResults(w http.ResponseWriter, r *http.Request) {
 	rID := chi.URLParam(r, "race_id")
 	raceID, _ := uuid.Parse(rID)
-	err := p.service.CalculateSplitResults(context.Background(), raceID)
+	err := p.service.CalculateSplitResults(r.Context(), raceID)
 	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			p.logger.Info("Calculate results cancelled by client", "race_id", rID)
+			return
+		}
 		p.logger.Error("Calculate results: ", "err", err)
 		serverErrorResponse(w, err)
 		return
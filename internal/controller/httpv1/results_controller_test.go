@@ -0,0 +1,113 @@
+package httpv1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/logger"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeScheduler struct {
+	races map[uuid.UUID]*entity.RecalcStatus
+}
+
+func (s *fakeScheduler) update(raceID uuid.UUID, f func(st *entity.RecalcStatus)) (*entity.RecalcStatus, error) {
+	st, ok := s.races[raceID]
+	if !ok {
+		return nil, nil
+	}
+	f(st)
+	res := *st
+	return &res, nil
+}
+
+func (s *fakeScheduler) Status(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error) {
+	return s.update(raceID, func(st *entity.RecalcStatus) {})
+}
+
+func (s *fakeScheduler) Pause(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error) {
+	return s.update(raceID, func(st *entity.RecalcStatus) { st.Paused = true })
+}
+
+func (s *fakeScheduler) Resume(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error) {
+	return s.update(raceID, func(st *entity.RecalcStatus) { st.Paused = false })
+}
+
+func TestRecalcRoutes(t *testing.T) {
+	raceID := uuid.New()
+	scheduler := &fakeScheduler{races: map[uuid.UUID]*entity.RecalcStatus{
+		raceID: {RaceID: raceID, Runs: 3},
+	}}
+	router := chi.NewRouter()
+	router.Mount("/races/{race_id}/results", newResultsRoutes(logger.New("error"), nil, scheduler))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantCode   int
+		wantPaused bool
+	}{
+		{
+			name:     "status",
+			method:   http.MethodGet,
+			path:     "/races/" + raceID.String() + "/results/recalc",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:       "pause",
+			method:     http.MethodPost,
+			path:       "/races/" + raceID.String() + "/results/recalc/pause",
+			wantCode:   http.StatusOK,
+			wantPaused: true,
+		},
+		{
+			name:       "status of paused race",
+			method:     http.MethodGet,
+			path:       "/races/" + raceID.String() + "/results/recalc",
+			wantCode:   http.StatusOK,
+			wantPaused: true,
+		},
+		{
+			name:     "resume",
+			method:   http.MethodPost,
+			path:     "/races/" + raceID.String() + "/results/recalc/resume",
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "unknown race",
+			method:   http.MethodPost,
+			path:     "/races/" + uuid.NewString() + "/results/recalc/pause",
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid race id",
+			method:   http.MethodGet,
+			path:     "/races/123/results/recalc",
+			wantCode: http.StatusUnprocessableEntity,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			if !assert.Equal(t, tt.wantCode, w.Code) || w.Code != http.StatusOK {
+				return
+			}
+			var st entity.RecalcStatus
+			if !assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &st)) {
+				return
+			}
+			assert.Equal(t, raceID, st.RaceID)
+			assert.Equal(t, 3, st.Runs)
+			assert.Equal(t, tt.wantPaused, st.Paused)
+		})
+	}
+}
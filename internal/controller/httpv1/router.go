@@ -30,9 +30,9 @@ func NewRaceRouter(handler *chi.Mux, logger *logger.Logger, raceService service.
 	handler.Mount("/races", newRaceRoutes(logger, raceService))
 }
 
-func NewAthleteResultsRouter(handler *chi.Mux, logger *logger.Logger, amanager service.AthleteManager, rmanager service.ResultsManager) {
+func NewAthleteResultsRouter(handler *chi.Mux, logger *logger.Logger, amanager service.AthleteManager, rmanager service.ResultsManager, scheduler service.RecalcScheduler) {
 	handler.Mount("/races/{race_id}/athletes", newAthletesRoutes(logger, amanager))
-	handler.Mount("/races/{race_id}/results", newResultsRoutes(logger, rmanager))
+	handler.Mount("/races/{race_id}/results", newResultsRoutes(logger, rmanager, scheduler))
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any, headers http.Header) error {
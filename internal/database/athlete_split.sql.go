@@ -12,6 +12,38 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countSplitResults = `-- name: CountSplitResults :many
+SELECT split_id, count(*)
+FROM athlete_split
+WHERE race_id = $1
+GROUP BY split_id
+`
+
+type CountSplitResultsRow struct {
+	SplitID uuid.UUID
+	Count   int64
+}
+
+func (q *Queries) CountSplitResults(ctx context.Context, raceID uuid.UUID) ([]CountSplitResultsRow, error) {
+	rows, err := q.db.Query(ctx, countSplitResults, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountSplitResultsRow
+	for rows.Next() {
+		var i CountSplitResultsRow
+		if err := rows.Scan(&i.SplitID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createAthleteSplits = `-- name: CreateAthleteSplits :exec
 INSERT INTO athlete_split
 (race_id, event_id, split_id, athlete_id, tod, gun_time, net_time)
@@ -25,7 +57,7 @@ type CreateAthleteSplitsParams struct {
 	EventID   uuid.UUID
 	SplitID   uuid.UUID
 	AthleteID uuid.UUID
-	Tod       pgtype.Timestamp
+	Tod       pgtype.Timestamptz
 	GunTime   pgtype.Interval
 	NetTime   pgtype.Interval
 }
@@ -58,6 +90,116 @@ func (q *Queries) DeleteAthleteSplit(ctx context.Context, arg DeleteAthleteSplit
 	return err
 }
 
+const deleteEventResults = `-- name: DeleteEventResults :exec
+DELETE FROM athlete_split
+WHERE race_id = $1 AND event_id = $2
+`
+
+type DeleteEventResultsParams struct {
+	RaceID  uuid.UUID
+	EventID uuid.UUID
+}
+
+func (q *Queries) DeleteEventResults(ctx context.Context, arg DeleteEventResultsParams) error {
+	_, err := q.db.Exec(ctx, deleteEventResults, arg.RaceID, arg.EventID)
+	return err
+}
+
+const deleteSplitResults = `-- name: DeleteSplitResults :exec
+DELETE FROM athlete_split
+WHERE race_id = $1 AND split_id = $2
+`
+
+type DeleteSplitResultsParams struct {
+	RaceID  uuid.UUID
+	SplitID uuid.UUID
+}
+
+func (q *Queries) DeleteSplitResults(ctx context.Context, arg DeleteSplitResultsParams) error {
+	_, err := q.db.Exec(ctx, deleteSplitResults, arg.RaceID, arg.SplitID)
+	return err
+}
+
+const deleteWaveResults = `-- name: DeleteWaveResults :exec
+DELETE FROM athlete_split ast
+USING event_athlete ea
+WHERE ea.race_id = $1 AND ea.wave_id = $2
+    AND ast.race_id = ea.race_id
+    AND ast.event_id = ea.event_id
+    AND ast.athlete_id = ea.athlete_id
+    AND ast.is_manual IS NOT TRUE
+`
+
+type DeleteWaveResultsParams struct {
+	RaceID uuid.UUID
+	WaveID uuid.UUID
+}
+
+func (q *Queries) DeleteWaveResults(ctx context.Context, arg DeleteWaveResultsParams) error {
+	_, err := q.db.Exec(ctx, deleteWaveResults, arg.RaceID, arg.WaveID)
+	return err
+}
+
+const getFinishResultsForEvent = `-- name: GetFinishResultsForEvent :many
+SELECT ast.athlete_id, ea.bib, a.first_name, a.last_name, a.gender, a.date_of_birth, ast.gun_time, ast.net_time,
+    coalesce(ea.handicap, c.handicap, '0'::interval)::interval AS handicap
+FROM athlete_split ast
+join splits s on s.id = ast.split_id and s.race_id = ast.race_id and s.event_id = ast.event_id
+join event_athlete ea on ea.athlete_id = ast.athlete_id and ea.race_id = ast.race_id and ea.event_id = ast.event_id
+join athletes a on ea.athlete_id = a.id
+join statuses st on st.status_id = ea.status_id
+left join categories c on c.id = ea.category_id
+WHERE ast.race_id = $1 AND ast.event_id = $2 AND s.split_type = 'finish' AND st.status_full = 'finished'
+ORDER BY ast.net_time
+`
+
+type GetFinishResultsForEventParams struct {
+	RaceID  uuid.UUID
+	EventID uuid.UUID
+}
+
+type GetFinishResultsForEventRow struct {
+	AthleteID   uuid.UUID
+	Bib         int32
+	FirstName   pgtype.Text
+	LastName    pgtype.Text
+	Gender      CategoryGender
+	DateOfBirth pgtype.Date
+	GunTime     pgtype.Interval
+	NetTime     pgtype.Interval
+	Handicap    pgtype.Interval
+}
+
+func (q *Queries) GetFinishResultsForEvent(ctx context.Context, arg GetFinishResultsForEventParams) ([]GetFinishResultsForEventRow, error) {
+	rows, err := q.db.Query(ctx, getFinishResultsForEvent, arg.RaceID, arg.EventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFinishResultsForEventRow
+	for rows.Next() {
+		var i GetFinishResultsForEventRow
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.Bib,
+			&i.FirstName,
+			&i.LastName,
+			&i.Gender,
+			&i.DateOfBirth,
+			&i.GunTime,
+			&i.NetTime,
+			&i.Handicap,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getManualAthleteSplits = `-- name: GetManualAthleteSplits :many
 SELECT ast.race_id, ast.event_id, ast.split_id, ast.athlete_id, ast.tod, ast.gun_time, ast.net_time, ea.category_id, a.gender
 FROM athlete_split ast
@@ -76,7 +218,7 @@ type GetManualAthleteSplitsRow struct {
 	EventID    uuid.UUID
 	SplitID    uuid.UUID
 	AthleteID  uuid.UUID
-	Tod        pgtype.Timestamp
+	Tod        pgtype.Timestamptz
 	GunTime    pgtype.Interval
 	NetTime    pgtype.Interval
 	CategoryID uuid.NullUUID
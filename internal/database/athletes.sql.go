@@ -105,7 +105,7 @@ func (q *Queries) DeleteAthletesWithRaceID(ctx context.Context, raceID uuid.UUID
 
 const getAthleteByID = `-- name: GetAthleteByID :one
 SELECT a.id, a.race_id, a.first_name, a.last_name, a.gender, a.date_of_birth, a.phone, a.athlete_comments, ea.event_id, ea.wave_id, ea.category_id,
-cb.bib, cb.chip
+ea.category_locked, ea.start_time, ea.handicap, cb.bib, cb.chip
 FROM athletes a
 join event_athlete ea 
 on ea.athlete_id = a.id
@@ -126,6 +126,9 @@ type GetAthleteByIDRow struct {
 	EventID         uuid.UUID
 	WaveID          uuid.UUID
 	CategoryID      uuid.NullUUID
+	CategoryLocked  bool
+	StartTime       pgtype.Timestamptz
+	Handicap        pgtype.Interval
 	Bib             int32
 	Chip            int32
 }
@@ -145,6 +148,9 @@ func (q *Queries) GetAthleteByID(ctx context.Context, id uuid.UUID) (GetAthleteB
 		&i.EventID,
 		&i.WaveID,
 		&i.CategoryID,
+		&i.CategoryLocked,
+		&i.StartTime,
+		&i.Handicap,
 		&i.Bib,
 		&i.Chip,
 	)
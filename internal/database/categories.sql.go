@@ -14,12 +14,12 @@ import (
 
 const addOrUpdateCategory = `-- name: AddOrUpdateCategory :one
 INSERT INTO categories
-(id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to)
-VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+(id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to, kind, handicap)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 ON CONFLICT (id)
 DO UPDATE
-SET category_name=EXCLUDED.category_name, gender=EXCLUDED.gender, age_from=EXCLUDED.age_from, date_from=EXCLUDED.date_from, age_to=EXCLUDED.age_to, date_to=EXCLUDED.date_to
-RETURNING id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to
+SET category_name=EXCLUDED.category_name, gender=EXCLUDED.gender, age_from=EXCLUDED.age_from, date_from=EXCLUDED.date_from, age_to=EXCLUDED.age_to, date_to=EXCLUDED.date_to, kind=EXCLUDED.kind, handicap=EXCLUDED.handicap
+RETURNING id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to, kind, handicap
 `
 
 type AddOrUpdateCategoryParams struct {
@@ -32,6 +32,8 @@ type AddOrUpdateCategoryParams struct {
 	DateFrom     pgtype.Timestamp
 	AgeTo        int32
 	DateTo       pgtype.Timestamp
+	Kind         string
+	Handicap     pgtype.Interval
 }
 
 func (q *Queries) AddOrUpdateCategory(ctx context.Context, arg AddOrUpdateCategoryParams) (Category, error) {
@@ -45,6 +47,8 @@ func (q *Queries) AddOrUpdateCategory(ctx context.Context, arg AddOrUpdateCatego
 		arg.DateFrom,
 		arg.AgeTo,
 		arg.DateTo,
+		arg.Kind,
+		arg.Handicap,
 	)
 	var i Category
 	err := row.Scan(
@@ -57,6 +61,8 @@ func (q *Queries) AddOrUpdateCategory(ctx context.Context, arg AddOrUpdateCatego
 		&i.DateFrom,
 		&i.AgeTo,
 		&i.DateTo,
+		&i.Kind,
+		&i.Handicap,
 	)
 	return i, err
 }
@@ -72,7 +78,7 @@ func (q *Queries) DeleteCategoryByID(ctx context.Context, id uuid.UUID) error {
 }
 
 const getCategoriesForEvent = `-- name: GetCategoriesForEvent :many
-SELECT id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to
+SELECT id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to, kind, handicap
 FROM categories
 WHERE event_id=$1
 ORDER BY age_from ASC
@@ -97,6 +103,8 @@ func (q *Queries) GetCategoriesForEvent(ctx context.Context, eventID uuid.UUID)
 			&i.DateFrom,
 			&i.AgeTo,
 			&i.DateTo,
+			&i.Kind,
+			&i.Handicap,
 		); err != nil {
 			return nil, err
 		}
@@ -109,10 +117,11 @@ func (q *Queries) GetCategoriesForEvent(ctx context.Context, eventID uuid.UUID)
 }
 
 const getCategoryForAthlete = `-- name: GetCategoryForAthlete :one
-SELECT id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to
+SELECT id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to, kind, handicap
 FROM categories
 WHERE 
-event_id = $1 
+kind = 'age'
+AND event_id = $1 
 AND gender = $2 
 AND $3 BETWEEN date_from AND date_to
 `
@@ -136,6 +145,8 @@ func (q *Queries) GetCategoryForAthlete(ctx context.Context, arg GetCategoryForA
 		&i.DateFrom,
 		&i.AgeTo,
 		&i.DateTo,
+		&i.Kind,
+		&i.Handicap,
 	)
 	return i, err
 }
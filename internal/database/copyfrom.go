@@ -9,6 +9,41 @@ import (
 	"context"
 )
 
+// iteratorForAddAthleteCategoryBulk implements pgx.CopyFromSource.
+type iteratorForAddAthleteCategoryBulk struct {
+	rows                 []AddAthleteCategoryBulkParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForAddAthleteCategoryBulk) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForAddAthleteCategoryBulk) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].AthleteID,
+		r.rows[0].CategoryID,
+	}, nil
+}
+
+func (r iteratorForAddAthleteCategoryBulk) Err() error {
+	return nil
+}
+
+func (q *Queries) AddAthleteCategoryBulk(ctx context.Context, arg []AddAthleteCategoryBulkParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"athlete_category"}, []string{"race_id", "event_id", "athlete_id", "category_id"}, &iteratorForAddAthleteCategoryBulk{rows: arg})
+}
+
 // iteratorForAddChipBibBulk implements pgx.CopyFromSource.
 type iteratorForAddChipBibBulk struct {
 	rows                 []AddChipBibBulkParams
@@ -70,6 +105,9 @@ func (r iteratorForAddEventAthleteBulk) Values() ([]interface{}, error) {
 		r.rows[0].WaveID,
 		r.rows[0].CategoryID,
 		r.rows[0].Bib,
+		r.rows[0].CategoryLocked,
+		r.rows[0].StartTime,
+		r.rows[0].Handicap,
 	}, nil
 }
 
@@ -78,7 +116,7 @@ func (r iteratorForAddEventAthleteBulk) Err() error {
 }
 
 func (q *Queries) AddEventAthleteBulk(ctx context.Context, arg []AddEventAthleteBulkParams) (int64, error) {
-	return q.db.CopyFrom(ctx, []string{"event_athlete"}, []string{"race_id", "event_id", "athlete_id", "wave_id", "category_id", "bib"}, &iteratorForAddEventAthleteBulk{rows: arg})
+	return q.db.CopyFrom(ctx, []string{"event_athlete"}, []string{"race_id", "event_id", "athlete_id", "wave_id", "category_id", "bib", "category_locked", "start_time", "handicap"}, &iteratorForAddEventAthleteBulk{rows: arg})
 }
 
 // iteratorForCreateAthleteBulk implements pgx.CopyFromSource.
@@ -119,3 +157,584 @@ func (r iteratorForCreateAthleteBulk) Err() error {
 func (q *Queries) CreateAthleteBulk(ctx context.Context, arg []CreateAthleteBulkParams) (int64, error) {
 	return q.db.CopyFrom(ctx, []string{"athletes"}, []string{"id", "race_id", "first_name", "last_name", "gender", "date_of_birth", "phone", "athlete_comments"}, &iteratorForCreateAthleteBulk{rows: arg})
 }
+
+// iteratorForRestoreAthleteCategories implements pgx.CopyFromSource.
+type iteratorForRestoreAthleteCategories struct {
+	rows                 []RestoreAthleteCategoriesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreAthleteCategories) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreAthleteCategories) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].AthleteID,
+		r.rows[0].CategoryID,
+	}, nil
+}
+
+func (r iteratorForRestoreAthleteCategories) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreAthleteCategories(ctx context.Context, arg []RestoreAthleteCategoriesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"athlete_category"}, []string{"race_id", "event_id", "athlete_id", "category_id"}, &iteratorForRestoreAthleteCategories{rows: arg})
+}
+
+// iteratorForRestoreAthleteCategoryRanks implements pgx.CopyFromSource.
+type iteratorForRestoreAthleteCategoryRanks struct {
+	rows                 []RestoreAthleteCategoryRanksParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreAthleteCategoryRanks) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreAthleteCategoryRanks) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].SplitID,
+		r.rows[0].AthleteID,
+		r.rows[0].CategoryID,
+		r.rows[0].GunRank,
+		r.rows[0].NetRank,
+	}, nil
+}
+
+func (r iteratorForRestoreAthleteCategoryRanks) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreAthleteCategoryRanks(ctx context.Context, arg []RestoreAthleteCategoryRanksParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"athlete_category_rank"}, []string{"race_id", "event_id", "split_id", "athlete_id", "category_id", "gun_rank", "net_rank"}, &iteratorForRestoreAthleteCategoryRanks{rows: arg})
+}
+
+// iteratorForRestoreAthleteSplits implements pgx.CopyFromSource.
+type iteratorForRestoreAthleteSplits struct {
+	rows                 []RestoreAthleteSplitsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreAthleteSplits) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreAthleteSplits) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].SplitID,
+		r.rows[0].AthleteID,
+		r.rows[0].Tod,
+		r.rows[0].GunTime,
+		r.rows[0].NetTime,
+		r.rows[0].GunRankGender,
+		r.rows[0].GunRankCategory,
+		r.rows[0].GunRankOverall,
+		r.rows[0].NetRankGender,
+		r.rows[0].NetRankCategory,
+		r.rows[0].NetRankOverall,
+		r.rows[0].IsManual,
+	}, nil
+}
+
+func (r iteratorForRestoreAthleteSplits) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreAthleteSplits(ctx context.Context, arg []RestoreAthleteSplitsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"athlete_split"}, []string{"race_id", "event_id", "split_id", "athlete_id", "tod", "gun_time", "net_time", "gun_rank_gender", "gun_rank_category", "gun_rank_overall", "net_rank_gender", "net_rank_category", "net_rank_overall", "is_manual"}, &iteratorForRestoreAthleteSplits{rows: arg})
+}
+
+// iteratorForRestoreAthletes implements pgx.CopyFromSource.
+type iteratorForRestoreAthletes struct {
+	rows                 []RestoreAthletesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreAthletes) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreAthletes) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].RaceID,
+		r.rows[0].FirstName,
+		r.rows[0].LastName,
+		r.rows[0].Gender,
+		r.rows[0].DateOfBirth,
+		r.rows[0].Phone,
+		r.rows[0].AthleteComments,
+		r.rows[0].CreatedAt,
+		r.rows[0].UpdatedAt,
+	}, nil
+}
+
+func (r iteratorForRestoreAthletes) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreAthletes(ctx context.Context, arg []RestoreAthletesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"athletes"}, []string{"id", "race_id", "first_name", "last_name", "gender", "date_of_birth", "phone", "athlete_comments", "created_at", "updated_at"}, &iteratorForRestoreAthletes{rows: arg})
+}
+
+// iteratorForRestoreCategories implements pgx.CopyFromSource.
+type iteratorForRestoreCategories struct {
+	rows                 []RestoreCategoriesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreCategories) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreCategories) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].CategoryName,
+		r.rows[0].Gender,
+		r.rows[0].AgeFrom,
+		r.rows[0].DateFrom,
+		r.rows[0].AgeTo,
+		r.rows[0].DateTo,
+		r.rows[0].Kind,
+		r.rows[0].Handicap,
+	}, nil
+}
+
+func (r iteratorForRestoreCategories) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreCategories(ctx context.Context, arg []RestoreCategoriesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"categories"}, []string{"id", "race_id", "event_id", "category_name", "gender", "age_from", "date_from", "age_to", "date_to", "kind", "handicap"}, &iteratorForRestoreCategories{rows: arg})
+}
+
+// iteratorForRestoreChipBibs implements pgx.CopyFromSource.
+type iteratorForRestoreChipBibs struct {
+	rows                 []RestoreChipBibsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreChipBibs) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreChipBibs) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].Chip,
+		r.rows[0].Bib,
+	}, nil
+}
+
+func (r iteratorForRestoreChipBibs) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreChipBibs(ctx context.Context, arg []RestoreChipBibsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"chip_bib"}, []string{"race_id", "event_id", "chip", "bib"}, &iteratorForRestoreChipBibs{rows: arg})
+}
+
+// iteratorForRestoreEventAthletes implements pgx.CopyFromSource.
+type iteratorForRestoreEventAthletes struct {
+	rows                 []RestoreEventAthletesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreEventAthletes) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreEventAthletes) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].AthleteID,
+		r.rows[0].WaveID,
+		r.rows[0].CategoryID,
+		r.rows[0].Bib,
+		r.rows[0].StatusID,
+		r.rows[0].StatusReason,
+		r.rows[0].StatusLocked,
+		r.rows[0].CategoryLocked,
+		r.rows[0].StartTime,
+		r.rows[0].Handicap,
+	}, nil
+}
+
+func (r iteratorForRestoreEventAthletes) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreEventAthletes(ctx context.Context, arg []RestoreEventAthletesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"event_athlete"}, []string{"race_id", "event_id", "athlete_id", "wave_id", "category_id", "bib", "status_id", "status_reason", "status_locked", "category_locked", "start_time", "handicap"}, &iteratorForRestoreEventAthletes{rows: arg})
+}
+
+// iteratorForRestoreEvents implements pgx.CopyFromSource.
+type iteratorForRestoreEvents struct {
+	rows                 []RestoreEventsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreEvents) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreEvents) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].RaceID,
+		r.rows[0].EventName,
+		r.rows[0].DistanceInMeters,
+		r.rows[0].EventDate,
+	}, nil
+}
+
+func (r iteratorForRestoreEvents) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreEvents(ctx context.Context, arg []RestoreEventsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"events"}, []string{"id", "race_id", "event_name", "distance_in_meters", "event_date"}, &iteratorForRestoreEvents{rows: arg})
+}
+
+// iteratorForRestoreRace implements pgx.CopyFromSource.
+type iteratorForRestoreRace struct {
+	rows                 []RestoreRaceParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreRace) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreRace) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].RaceName,
+		r.rows[0].Timezone,
+		r.rows[0].Status,
+	}, nil
+}
+
+func (r iteratorForRestoreRace) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreRace(ctx context.Context, arg []RestoreRaceParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"races"}, []string{"id", "race_name", "timezone", "status"}, &iteratorForRestoreRace{rows: arg})
+}
+
+// iteratorForRestoreRaceStatusHistory implements pgx.CopyFromSource.
+type iteratorForRestoreRaceStatusHistory struct {
+	rows                 []RestoreRaceStatusHistoryParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreRaceStatusHistory) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreRaceStatusHistory) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].OldStatus,
+		r.rows[0].NewStatus,
+		r.rows[0].Forced,
+		r.rows[0].Reason,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForRestoreRaceStatusHistory) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreRaceStatusHistory(ctx context.Context, arg []RestoreRaceStatusHistoryParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"race_status_history"}, []string{"race_id", "old_status", "new_status", "forced", "reason", "created_at"}, &iteratorForRestoreRaceStatusHistory{rows: arg})
+}
+
+// iteratorForRestoreReaderRecords implements pgx.CopyFromSource.
+type iteratorForRestoreReaderRecords struct {
+	rows                 []RestoreReaderRecordsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreReaderRecords) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreReaderRecords) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].Chip,
+		r.rows[0].Tod,
+		r.rows[0].ReaderName,
+		r.rows[0].CanUse,
+	}, nil
+}
+
+func (r iteratorForRestoreReaderRecords) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreReaderRecords(ctx context.Context, arg []RestoreReaderRecordsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"reader_records"}, []string{"race_id", "chip", "tod", "reader_name", "can_use"}, &iteratorForRestoreReaderRecords{rows: arg})
+}
+
+// iteratorForRestoreSplits implements pgx.CopyFromSource.
+type iteratorForRestoreSplits struct {
+	rows                 []RestoreSplitsParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreSplits) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreSplits) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].SplitName,
+		r.rows[0].SplitType,
+		r.rows[0].DistanceFromStart,
+		r.rows[0].TimeReaderID,
+		r.rows[0].MinTime,
+		r.rows[0].MaxTime,
+		r.rows[0].MinLapTime,
+		r.rows[0].PreviousLapSplitID,
+		r.rows[0].CutoffTime,
+		r.rows[0].CutoffTod,
+	}, nil
+}
+
+func (r iteratorForRestoreSplits) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreSplits(ctx context.Context, arg []RestoreSplitsParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"splits"}, []string{"id", "race_id", "event_id", "split_name", "split_type", "distance_from_start", "time_reader_id", "min_time", "max_time", "min_lap_time", "previous_lap_split_id", "cutoff_time", "cutoff_tod"}, &iteratorForRestoreSplits{rows: arg})
+}
+
+// iteratorForRestoreTimeReaders implements pgx.CopyFromSource.
+type iteratorForRestoreTimeReaders struct {
+	rows                 []RestoreTimeReadersParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreTimeReaders) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreTimeReaders) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].RaceID,
+		r.rows[0].ReaderName,
+	}, nil
+}
+
+func (r iteratorForRestoreTimeReaders) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreTimeReaders(ctx context.Context, arg []RestoreTimeReadersParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"time_readers"}, []string{"id", "race_id", "reader_name"}, &iteratorForRestoreTimeReaders{rows: arg})
+}
+
+// iteratorForRestoreWaveAudit implements pgx.CopyFromSource.
+type iteratorForRestoreWaveAudit struct {
+	rows                 []RestoreWaveAuditParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreWaveAudit) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreWaveAudit) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].RaceID,
+		r.rows[0].WaveID,
+		r.rows[0].Action,
+		r.rows[0].OldStartTime,
+		r.rows[0].NewStartTime,
+		r.rows[0].WasLaunched,
+		r.rows[0].IsLaunched,
+		r.rows[0].Reason,
+		r.rows[0].CreatedAt,
+	}, nil
+}
+
+func (r iteratorForRestoreWaveAudit) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreWaveAudit(ctx context.Context, arg []RestoreWaveAuditParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"wave_audit"}, []string{"race_id", "wave_id", "action", "old_start_time", "new_start_time", "was_launched", "is_launched", "reason", "created_at"}, &iteratorForRestoreWaveAudit{rows: arg})
+}
+
+// iteratorForRestoreWaves implements pgx.CopyFromSource.
+type iteratorForRestoreWaves struct {
+	rows                 []RestoreWavesParams
+	skippedFirstNextCall bool
+}
+
+func (r *iteratorForRestoreWaves) Next() bool {
+	if len(r.rows) == 0 {
+		return false
+	}
+	if !r.skippedFirstNextCall {
+		r.skippedFirstNextCall = true
+		return true
+	}
+	r.rows = r.rows[1:]
+	return len(r.rows) > 0
+}
+
+func (r iteratorForRestoreWaves) Values() ([]interface{}, error) {
+	return []interface{}{
+		r.rows[0].ID,
+		r.rows[0].RaceID,
+		r.rows[0].EventID,
+		r.rows[0].WaveName,
+		r.rows[0].StartTime,
+		r.rows[0].IsLaunched,
+		r.rows[0].TriggerChip,
+		r.rows[0].TriggerReaderID,
+		r.rows[0].AutoStart,
+	}, nil
+}
+
+func (r iteratorForRestoreWaves) Err() error {
+	return nil
+}
+
+func (q *Queries) RestoreWaves(ctx context.Context, arg []RestoreWavesParams) (int64, error) {
+	return q.db.CopyFrom(ctx, []string{"waves"}, []string{"id", "race_id", "event_id", "wave_name", "start_time", "is_launched", "trigger_chip", "trigger_reader_id", "auto_start"}, &iteratorForRestoreWaves{rows: arg})
+}
@@ -108,8 +108,25 @@ type Athlete struct {
 	DateOfBirth     pgtype.Date
 	Phone           pgtype.Text
 	AthleteComments pgtype.Text
-	CreatedAt       pgtype.Timestamp
-	UpdatedAt       pgtype.Timestamp
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
+type AthleteCategory struct {
+	RaceID     uuid.UUID
+	EventID    uuid.UUID
+	AthleteID  uuid.UUID
+	CategoryID uuid.UUID
+}
+
+type AthleteCategoryRank struct {
+	RaceID     uuid.UUID
+	EventID    uuid.UUID
+	SplitID    uuid.UUID
+	AthleteID  uuid.UUID
+	CategoryID uuid.UUID
+	GunRank    pgtype.Int4
+	NetRank    pgtype.Int4
 }
 
 type AthleteSplit struct {
@@ -117,7 +134,7 @@ type AthleteSplit struct {
 	EventID         uuid.UUID
 	SplitID         uuid.UUID
 	AthleteID       uuid.UUID
-	Tod             pgtype.Timestamp
+	Tod             pgtype.Timestamptz
 	GunTime         pgtype.Interval
 	NetTime         pgtype.Interval
 	GunRankGender   pgtype.Int4
@@ -139,6 +156,8 @@ type Category struct {
 	DateFrom     pgtype.Timestamp
 	AgeTo        int32
 	DateTo       pgtype.Timestamp
+	Kind         string
+	Handicap     pgtype.Interval
 }
 
 type ChipBib struct {
@@ -157,28 +176,51 @@ type Event struct {
 }
 
 type EventAthlete struct {
-	RaceID     uuid.UUID
-	EventID    uuid.UUID
-	AthleteID  uuid.UUID
-	WaveID     uuid.UUID
-	CategoryID uuid.NullUUID
-	Bib        int32
-	StatusID   pgtype.Int4
+	RaceID         uuid.UUID
+	EventID        uuid.UUID
+	AthleteID      uuid.UUID
+	WaveID         uuid.UUID
+	CategoryID     uuid.NullUUID
+	Bib            int32
+	StatusID       pgtype.Int4
+	StatusReason   string
+	StatusLocked   bool
+	CategoryLocked bool
+	StartTime      pgtype.Timestamptz
+	Handicap       pgtype.Interval
 }
 
 type Race struct {
 	ID       uuid.UUID
 	RaceName string
 	Timezone string
+	Status   string
+}
+
+type RaceStatusHistory struct {
+	ID        int64
+	RaceID    uuid.UUID
+	OldStatus string
+	NewStatus string
+	Forced    bool
+	Reason    string
+	CreatedAt pgtype.Timestamptz
 }
 
 type ReaderRecord struct {
 	ID         int32
 	RaceID     uuid.UUID
 	Chip       int32
-	Tod        pgtype.Timestamp
+	Tod        pgtype.Timestamptz
 	ReaderName string
 	CanUse     bool
+	Xid        int64
+}
+
+type ResultsWatermark struct {
+	RaceID       uuid.UUID
+	UpdatedAt    pgtype.Timestamptz
+	SnapshotXmin int64
 }
 
 type Split struct {
@@ -193,6 +235,8 @@ type Split struct {
 	MaxTime            pgtype.Interval
 	MinLapTime         pgtype.Interval
 	PreviousLapSplitID uuid.NullUUID
+	CutoffTime         pgtype.Interval
+	CutoffTod          pgtype.Timestamptz
 }
 
 type Status struct {
@@ -210,10 +254,26 @@ type TimeReader struct {
 }
 
 type Wave struct {
-	ID         uuid.UUID
-	RaceID     uuid.UUID
-	EventID    uuid.UUID
-	WaveName   string
-	StartTime  pgtype.Timestamp
-	IsLaunched bool
+	ID              uuid.UUID
+	RaceID          uuid.UUID
+	EventID         uuid.UUID
+	WaveName        string
+	StartTime       pgtype.Timestamptz
+	IsLaunched      bool
+	TriggerChip     pgtype.Int4
+	TriggerReaderID uuid.NullUUID
+	AutoStart       bool
+}
+
+type WaveAudit struct {
+	ID           int64
+	RaceID       uuid.UUID
+	WaveID       uuid.UUID
+	Action       string
+	OldStartTime pgtype.Timestamptz
+	NewStartTime pgtype.Timestamptz
+	WasLaunched  bool
+	IsLaunched   bool
+	Reason       string
+	CreatedAt    pgtype.Timestamptz
 }
@@ -15,21 +15,24 @@ import (
 
 const addEventAthlete = `-- name: AddEventAthlete :one
 INSERT INTO event_athlete
-(race_id, event_id, athlete_id, wave_id, category_id, bib)
-VALUES ($1, $2, $3, $4, $5, $6)
+(race_id, event_id, athlete_id, wave_id, category_id, bib, category_locked, start_time, handicap)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (race_id, event_id, athlete_id)
 DO UPDATE
-SET event_id=EXCLUDED.event_id, wave_id=EXCLUDED.wave_id, category_id=EXCLUDED.category_id, bib=EXCLUDED.bib 
-RETURNING race_id, event_id, athlete_id, wave_id, category_id, bib, status_id
+SET event_id=EXCLUDED.event_id, wave_id=EXCLUDED.wave_id, category_id=EXCLUDED.category_id, bib=EXCLUDED.bib, category_locked=EXCLUDED.category_locked, start_time=EXCLUDED.start_time, handicap=EXCLUDED.handicap 
+RETURNING race_id, event_id, athlete_id, wave_id, category_id, bib, status_id, status_reason, status_locked, category_locked, start_time, handicap
 `
 
 type AddEventAthleteParams struct {
-	RaceID     uuid.UUID
-	EventID    uuid.UUID
-	AthleteID  uuid.UUID
-	WaveID     uuid.UUID
-	CategoryID uuid.NullUUID
-	Bib        int32
+	RaceID         uuid.UUID
+	EventID        uuid.UUID
+	AthleteID      uuid.UUID
+	WaveID         uuid.UUID
+	CategoryID     uuid.NullUUID
+	Bib            int32
+	CategoryLocked bool
+	StartTime      pgtype.Timestamptz
+	Handicap       pgtype.Interval
 }
 
 func (q *Queries) AddEventAthlete(ctx context.Context, arg AddEventAthleteParams) (EventAthlete, error) {
@@ -40,6 +43,9 @@ func (q *Queries) AddEventAthlete(ctx context.Context, arg AddEventAthleteParams
 		arg.WaveID,
 		arg.CategoryID,
 		arg.Bib,
+		arg.CategoryLocked,
+		arg.StartTime,
+		arg.Handicap,
 	)
 	var i EventAthlete
 	err := row.Scan(
@@ -50,17 +56,170 @@ func (q *Queries) AddEventAthlete(ctx context.Context, arg AddEventAthleteParams
 		&i.CategoryID,
 		&i.Bib,
 		&i.StatusID,
+		&i.StatusReason,
+		&i.StatusLocked,
+		&i.CategoryLocked,
+		&i.StartTime,
+		&i.Handicap,
 	)
 	return i, err
 }
 
 type AddEventAthleteBulkParams struct {
+	RaceID         uuid.UUID
+	EventID        uuid.UUID
+	AthleteID      uuid.UUID
+	WaveID         uuid.UUID
+	CategoryID     uuid.NullUUID
+	Bib            int32
+	CategoryLocked bool
+	StartTime      pgtype.Timestamptz
+	Handicap       pgtype.Interval
+}
+
+const clearCategory = `-- name: ClearCategory :exec
+UPDATE event_athlete
+SET category_id = NULL, category_locked = FALSE
+WHERE race_id = $1 AND category_id = $2
+`
+
+type ClearCategoryParams struct {
 	RaceID     uuid.UUID
-	EventID    uuid.UUID
-	AthleteID  uuid.UUID
-	WaveID     uuid.UUID
 	CategoryID uuid.NullUUID
-	Bib        int32
+}
+
+func (q *Queries) ClearCategory(ctx context.Context, arg ClearCategoryParams) error {
+	_, err := q.db.Exec(ctx, clearCategory, arg.RaceID, arg.CategoryID)
+	return err
+}
+
+const countAthletesByCategory = `-- name: CountAthletesByCategory :many
+SELECT c.category_id, count(*)
+FROM (
+    SELECT ea.category_id FROM event_athlete ea WHERE ea.race_id = $1 AND ea.category_id IS NOT NULL
+    UNION ALL
+    SELECT ac.category_id FROM athlete_category ac WHERE ac.race_id = $1
+) c
+GROUP BY c.category_id
+`
+
+type CountAthletesByCategoryRow struct {
+	CategoryID uuid.NullUUID
+	Count      int64
+}
+
+func (q *Queries) CountAthletesByCategory(ctx context.Context, raceID uuid.UUID) ([]CountAthletesByCategoryRow, error) {
+	rows, err := q.db.Query(ctx, countAthletesByCategory, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountAthletesByCategoryRow
+	for rows.Next() {
+		var i CountAthletesByCategoryRow
+		if err := rows.Scan(&i.CategoryID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countAthletesByWave = `-- name: CountAthletesByWave :many
+SELECT wave_id, count(*)
+FROM event_athlete
+WHERE race_id = $1
+GROUP BY wave_id
+`
+
+type CountAthletesByWaveRow struct {
+	WaveID uuid.UUID
+	Count  int64
+}
+
+func (q *Queries) CountAthletesByWave(ctx context.Context, raceID uuid.UUID) ([]CountAthletesByWaveRow, error) {
+	rows, err := q.db.Query(ctx, countAthletesByWave, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountAthletesByWaveRow
+	for rows.Next() {
+		var i CountAthletesByWaveRow
+		if err := rows.Scan(&i.WaveID, &i.Count); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countWaveAthletes = `-- name: CountWaveAthletes :one
+SELECT count(*)
+FROM event_athlete
+WHERE race_id = $1 AND wave_id = $2
+`
+
+type CountWaveAthletesParams struct {
+	RaceID uuid.UUID
+	WaveID uuid.UUID
+}
+
+func (q *Queries) CountWaveAthletes(ctx context.Context, arg CountWaveAthletesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countWaveAthletes, arg.RaceID, arg.WaveID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getAthletesForCategories = `-- name: GetAthletesForCategories :many
+SELECT ea.athlete_id, ea.category_id, a.gender, a.date_of_birth
+FROM event_athlete ea
+JOIN athletes a ON a.id = ea.athlete_id
+WHERE ea.race_id = $1 AND ea.event_id = $2 AND ea.category_locked IS FALSE
+`
+
+type GetAthletesForCategoriesParams struct {
+	RaceID  uuid.UUID
+	EventID uuid.UUID
+}
+
+type GetAthletesForCategoriesRow struct {
+	AthleteID   uuid.UUID
+	CategoryID  uuid.NullUUID
+	Gender      CategoryGender
+	DateOfBirth pgtype.Date
+}
+
+func (q *Queries) GetAthletesForCategories(ctx context.Context, arg GetAthletesForCategoriesParams) ([]GetAthletesForCategoriesRow, error) {
+	rows, err := q.db.Query(ctx, getAthletesForCategories, arg.RaceID, arg.EventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAthletesForCategoriesRow
+	for rows.Next() {
+		var i GetAthletesForCategoriesRow
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.CategoryID,
+			&i.Gender,
+			&i.DateOfBirth,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getEventAthlete = `-- name: GetEventAthlete :one
@@ -84,6 +243,77 @@ func (q *Queries) GetEventAthlete(ctx context.Context, athleteID uuid.UUID) (Eve
 	return i, err
 }
 
+const getEventAthleteProgress = `-- name: GetEventAthleteProgress :many
+SELECT ea.athlete_id, ea.bib, a.first_name, a.last_name, a.phone, s.status_full, (coalesce(ea.start_time, w.start_time) + coalesce(ea.handicap, c.handicap, '0'::interval))::timestamptz as wave_start,
+ast.split_id, ast.tod, ast.gun_time, ast.net_time
+FROM event_athlete ea
+join statuses s on ea.status_id = s.status_id
+join waves w on
+    w.race_id = ea.race_id
+    and w.event_id = ea.event_id
+    and w.id = ea.wave_id
+left join categories c on c.id = ea.category_id
+join athletes a on a.id = ea.athlete_id
+join athlete_split ast on
+    ast.race_id = ea.race_id
+    and ast.event_id = ea.event_id
+    and ast.athlete_id = ea.athlete_id
+WHERE ea.race_id = $1 AND ea.event_id = $2 AND s.status_full = $3
+ORDER BY ea.athlete_id, ast.tod
+`
+
+type GetEventAthleteProgressParams struct {
+	RaceID     uuid.UUID
+	EventID    uuid.UUID
+	StatusFull string
+}
+
+type GetEventAthleteProgressRow struct {
+	AthleteID  uuid.UUID
+	Bib        int32
+	FirstName  pgtype.Text
+	LastName   pgtype.Text
+	Phone      pgtype.Text
+	StatusFull string
+	WaveStart  pgtype.Timestamptz
+	SplitID    uuid.UUID
+	Tod        pgtype.Timestamptz
+	GunTime    pgtype.Interval
+	NetTime    pgtype.Interval
+}
+
+func (q *Queries) GetEventAthleteProgress(ctx context.Context, arg GetEventAthleteProgressParams) ([]GetEventAthleteProgressRow, error) {
+	rows, err := q.db.Query(ctx, getEventAthleteProgress, arg.RaceID, arg.EventID, arg.StatusFull)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetEventAthleteProgressRow
+	for rows.Next() {
+		var i GetEventAthleteProgressRow
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.Bib,
+			&i.FirstName,
+			&i.LastName,
+			&i.Phone,
+			&i.StatusFull,
+			&i.WaveStart,
+			&i.SplitID,
+			&i.Tod,
+			&i.GunTime,
+			&i.NetTime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getEventAthleteRecordsC = `-- name: GetEventAthleteRecordsC :many
 with distinct_rr_tod as (
     select distinct tr.id, rr.tod, rr.chip, rr.race_id
@@ -100,7 +330,9 @@ select
     cb.chip,
     a.gender,
     s.status_full,
-    w.start_time as wave_start,
+    ea.status_reason,
+    ea.status_locked,
+    (coalesce(ea.start_time, w.start_time) + coalesce(ea.handicap, c.handicap, '0'::interval))::timestamptz as wave_start,
     (
         select array_agg(row(d.id, d.tod)::rr_tod order by d.tod)::rr_tod[]
         from distinct_rr_tod d
@@ -114,6 +346,7 @@ join waves w on
     w.race_id = ea.race_id
     and w.event_id = ea.event_id
     and w.id = ea.wave_id
+left join categories c on c.id = ea.category_id
 join chip_bib cb on
     cb.race_id = ea.race_id
     and cb.event_id = ea.event_id
@@ -124,26 +357,30 @@ join athletes a on
 	where ea.race_id = $1 
 		and ea.event_id = $2 
 		and w.is_launched is true
+		and ($3::int[] is null or cb.chip = any($3::int[]))
 `
 
 type GetEventAthleteRecordsCParams struct {
 	RaceID  uuid.UUID
 	EventID uuid.UUID
+	Chips   []int32
 }
 
 type GetEventAthleteRecordsCRow struct {
-	AthleteID  uuid.UUID
-	CategoryID uuid.NullUUID
-	Bib        int32
-	Chip       int32
-	Gender     CategoryGender
-	StatusFull string
-	WaveStart  pgtype.Timestamp
-	RrTod      []entity.RecordTOD
+	AthleteID    uuid.UUID
+	CategoryID   uuid.NullUUID
+	Bib          int32
+	Chip         int32
+	Gender       CategoryGender
+	StatusFull   string
+	StatusReason string
+	StatusLocked bool
+	WaveStart    pgtype.Timestamptz
+	RrTod        []entity.RecordTOD
 }
 
 func (q *Queries) GetEventAthleteRecordsC(ctx context.Context, arg GetEventAthleteRecordsCParams) ([]GetEventAthleteRecordsCRow, error) {
-	rows, err := q.db.Query(ctx, getEventAthleteRecordsC, arg.RaceID, arg.EventID)
+	rows, err := q.db.Query(ctx, getEventAthleteRecordsC, arg.RaceID, arg.EventID, arg.Chips)
 	if err != nil {
 		return nil, err
 	}
@@ -158,6 +395,8 @@ func (q *Queries) GetEventAthleteRecordsC(ctx context.Context, arg GetEventAthle
 			&i.Chip,
 			&i.Gender,
 			&i.StatusFull,
+			&i.StatusReason,
+			&i.StatusLocked,
 			&i.WaveStart,
 			&i.RrTod,
 		); err != nil {
@@ -171,25 +410,220 @@ func (q *Queries) GetEventAthleteRecordsC(ctx context.Context, arg GetEventAthle
 	return items, nil
 }
 
+const getStartList = `-- name: GetStartList :many
+SELECT ea.athlete_id, ea.event_id, ea.wave_id, ea.bib, a.first_name, a.last_name, e.event_name, w.wave_name,
+    ea.start_time, w.start_time AS wave_start, coalesce(ea.handicap, c.handicap, '0'::interval)::interval AS handicap
+FROM event_athlete ea
+JOIN athletes a ON a.id = ea.athlete_id
+JOIN events e ON e.id = ea.event_id
+JOIN waves w ON
+    w.race_id = ea.race_id
+    AND w.event_id = ea.event_id
+    AND w.id = ea.wave_id
+LEFT JOIN categories c ON c.id = ea.category_id
+WHERE ea.race_id = $1
+    AND ($2::uuid IS NULL OR ea.event_id = $2::uuid)
+    AND ($3::uuid IS NULL OR ea.wave_id = $3::uuid)
+ORDER BY coalesce(ea.start_time, w.start_time) + coalesce(ea.handicap, c.handicap, '0'::interval), ea.bib
+`
+
+type GetStartListParams struct {
+	RaceID  uuid.UUID
+	EventID uuid.NullUUID
+	WaveID  uuid.NullUUID
+}
+
+type GetStartListRow struct {
+	AthleteID uuid.UUID
+	EventID   uuid.UUID
+	WaveID    uuid.UUID
+	Bib       int32
+	FirstName pgtype.Text
+	LastName  pgtype.Text
+	EventName string
+	WaveName  string
+	StartTime pgtype.Timestamptz
+	WaveStart pgtype.Timestamptz
+	Handicap  pgtype.Interval
+}
+
+func (q *Queries) GetStartList(ctx context.Context, arg GetStartListParams) ([]GetStartListRow, error) {
+	rows, err := q.db.Query(ctx, getStartList, arg.RaceID, arg.EventID, arg.WaveID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStartListRow
+	for rows.Next() {
+		var i GetStartListRow
+		if err := rows.Scan(
+			&i.AthleteID,
+			&i.EventID,
+			&i.WaveID,
+			&i.Bib,
+			&i.FirstName,
+			&i.LastName,
+			&i.EventName,
+			&i.WaveName,
+			&i.StartTime,
+			&i.WaveStart,
+			&i.Handicap,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const overrideStatus = `-- name: OverrideStatus :execrows
+UPDATE event_athlete
+SET status_id = $1, status_reason = $2, status_locked = $3
+WHERE athlete_id = $4 AND race_id = $5 AND event_id = $6
+`
+
+type OverrideStatusParams struct {
+	StatusID     pgtype.Int4
+	StatusReason string
+	StatusLocked bool
+	AthleteID    uuid.UUID
+	RaceID       uuid.UUID
+	EventID      uuid.UUID
+}
+
+func (q *Queries) OverrideStatus(ctx context.Context, arg OverrideStatusParams) (int64, error) {
+	result, err := q.db.Exec(ctx, overrideStatus,
+		arg.StatusID,
+		arg.StatusReason,
+		arg.StatusLocked,
+		arg.AthleteID,
+		arg.RaceID,
+		arg.EventID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const resetWaveStatuses = `-- name: ResetWaveStatuses :exec
+UPDATE event_athlete
+SET status_id = (SELECT status_id FROM statuses WHERE status_full = 'not yet started'), status_reason = ''
+WHERE race_id = $1 AND wave_id = $2 AND status_locked IS FALSE
+`
+
+type ResetWaveStatusesParams struct {
+	RaceID uuid.UUID
+	WaveID uuid.UUID
+}
+
+func (q *Queries) ResetWaveStatuses(ctx context.Context, arg ResetWaveStatusesParams) error {
+	_, err := q.db.Exec(ctx, resetWaveStatuses, arg.RaceID, arg.WaveID)
+	return err
+}
+
+const setCategoryBulk = `-- name: SetCategoryBulk :execrows
+UPDATE event_athlete ea
+SET category_id = NULLIF(u.category_id, '00000000-0000-0000-0000-000000000000'::uuid)
+FROM unnest($1::uuid[], $2::uuid[]) AS u(athlete_id, category_id)
+WHERE ea.race_id = $3
+    AND ea.event_id = $4
+    AND ea.athlete_id = u.athlete_id
+    AND ea.category_locked IS FALSE
+`
+
+type SetCategoryBulkParams struct {
+	AthleteIds  []uuid.UUID
+	CategoryIds []uuid.UUID
+	RaceID      uuid.UUID
+	EventID     uuid.UUID
+}
+
+func (q *Queries) SetCategoryBulk(ctx context.Context, arg SetCategoryBulkParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setCategoryBulk,
+		arg.AthleteIds,
+		arg.CategoryIds,
+		arg.RaceID,
+		arg.EventID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setStartTimeBulk = `-- name: SetStartTimeBulk :exec
+UPDATE event_athlete ea
+SET start_time = u.start_time
+FROM unnest($1::uuid[], $2::timestamptz[]) AS u(athlete_id, start_time)
+WHERE ea.race_id = $3 AND ea.athlete_id = u.athlete_id
+`
+
+type SetStartTimeBulkParams struct {
+	AthleteIds []uuid.UUID
+	StartTimes []pgtype.Timestamptz
+	RaceID     uuid.UUID
+}
+
+func (q *Queries) SetStartTimeBulk(ctx context.Context, arg SetStartTimeBulkParams) error {
+	_, err := q.db.Exec(ctx, setStartTimeBulk, arg.AthleteIds, arg.StartTimes, arg.RaceID)
+	return err
+}
+
 const setStatus = `-- name: SetStatus :exec
 UPDATE event_athlete
-SET status_id = $1
-WHERE athlete_id = $2 AND race_id = $3 AND event_id = $4
+SET status_id = $1, status_reason = $2
+WHERE athlete_id = $3 AND race_id = $4 AND event_id = $5 AND status_locked IS FALSE
 `
 
 type SetStatusParams struct {
-	StatusID  pgtype.Int4
-	AthleteID uuid.UUID
-	RaceID    uuid.UUID
-	EventID   uuid.UUID
+	StatusID     pgtype.Int4
+	StatusReason string
+	AthleteID    uuid.UUID
+	RaceID       uuid.UUID
+	EventID      uuid.UUID
 }
 
 func (q *Queries) SetStatus(ctx context.Context, arg SetStatusParams) error {
 	_, err := q.db.Exec(ctx, setStatus,
 		arg.StatusID,
+		arg.StatusReason,
 		arg.AthleteID,
 		arg.RaceID,
 		arg.EventID,
 	)
 	return err
 }
+
+const setStatusBulk = `-- name: SetStatusBulk :exec
+UPDATE event_athlete ea
+SET status_id = u.status_id, status_reason = u.status_reason
+FROM unnest($1::uuid[], $2::uuid[], $3::int[], $4::text[])
+    AS u(athlete_id, event_id, status_id, status_reason)
+WHERE ea.race_id = $5
+    AND ea.athlete_id = u.athlete_id
+    AND ea.event_id = u.event_id
+    AND ea.status_locked IS FALSE
+`
+
+type SetStatusBulkParams struct {
+	AthleteIds    []uuid.UUID
+	EventIds      []uuid.UUID
+	StatusIds     []int32
+	StatusReasons []string
+	RaceID        uuid.UUID
+}
+
+func (q *Queries) SetStatusBulk(ctx context.Context, arg SetStatusBulkParams) error {
+	_, err := q.db.Exec(ctx, setStatusBulk,
+		arg.AthleteIds,
+		arg.EventIds,
+		arg.StatusIds,
+		arg.StatusReasons,
+		arg.RaceID,
+	)
+	return err
+}
@@ -0,0 +1,37 @@
+-- name: AddAthleteCategoryBulk :copyfrom
+INSERT INTO athlete_category
+(race_id, event_id, athlete_id, category_id)
+VALUES ($1, $2, $3, $4);
+
+-- name: DeleteAthleteCategories :exec
+DELETE FROM athlete_category
+WHERE athlete_id = $1;
+
+-- name: GetAthleteCategories :many
+SELECT category_id
+FROM athlete_category
+WHERE athlete_id = $1
+ORDER BY category_id;
+
+-- name: GetCategoryResults :many
+SELECT ats.athlete_id, ea.bib, a.first_name, a.last_name, s.status_full, ats.tod, ats.gun_time, ats.net_time,
+    coalesce(acr.gun_rank, ats.gun_rank_category) AS gun_rank,
+    coalesce(acr.net_rank, ats.net_rank_category) AS net_rank
+FROM athlete_split ats
+JOIN event_athlete ea ON ea.race_id = ats.race_id AND ea.event_id = ats.event_id AND ea.athlete_id = ats.athlete_id
+JOIN athletes a ON a.id = ats.athlete_id
+JOIN statuses s ON s.status_id = ea.status_id
+LEFT JOIN athlete_category_rank acr ON acr.race_id = ats.race_id
+    AND acr.event_id = ats.event_id
+    AND acr.split_id = ats.split_id
+    AND acr.athlete_id = ats.athlete_id
+    AND acr.category_id = @category_id
+WHERE ats.race_id = @race_id AND ats.split_id = @split_id
+    AND (ea.category_id = @category_id OR EXISTS (
+        SELECT 1 FROM athlete_category ac WHERE ac.athlete_id = ats.athlete_id AND ac.category_id = @category_id
+    ))
+ORDER BY net_rank NULLS LAST, ats.net_time;
+
+-- name: DeleteSplitCategoryRanks :exec
+DELETE FROM athlete_category_rank
+WHERE race_id = $1 AND split_id = $2;
@@ -14,4 +14,39 @@ WHERE ast.race_id = $1 AND ast.event_id = $2 AND is_manual IS TRUE;
 
 -- name: DeleteAthleteSplit :exec
 DELETE FROM athlete_split
-WHERE race_id = $1 AND athlete_ID = $2;
\ No newline at end of file
+WHERE race_id = $1 AND athlete_ID = $2;
+
+-- name: GetFinishResultsForEvent :many
+SELECT ast.athlete_id, ea.bib, a.first_name, a.last_name, a.gender, a.date_of_birth, ast.gun_time, ast.net_time,
+    coalesce(ea.handicap, c.handicap, '0'::interval)::interval AS handicap
+FROM athlete_split ast
+join splits s on s.id = ast.split_id and s.race_id = ast.race_id and s.event_id = ast.event_id
+join event_athlete ea on ea.athlete_id = ast.athlete_id and ea.race_id = ast.race_id and ea.event_id = ast.event_id
+join athletes a on ea.athlete_id = a.id
+join statuses st on st.status_id = ea.status_id
+left join categories c on c.id = ea.category_id
+WHERE ast.race_id = $1 AND ast.event_id = $2 AND s.split_type = 'finish' AND st.status_full = 'finished'
+ORDER BY ast.net_time;
+
+-- name: DeleteWaveResults :exec
+DELETE FROM athlete_split ast
+USING event_athlete ea
+WHERE ea.race_id = $1 AND ea.wave_id = $2
+    AND ast.race_id = ea.race_id
+    AND ast.event_id = ea.event_id
+    AND ast.athlete_id = ea.athlete_id
+    AND ast.is_manual IS NOT TRUE;
+
+-- name: DeleteEventResults :exec
+DELETE FROM athlete_split
+WHERE race_id = $1 AND event_id = $2;
+
+-- name: DeleteSplitResults :exec
+DELETE FROM athlete_split
+WHERE race_id = $1 AND split_id = $2;
+
+-- name: CountSplitResults :many
+SELECT split_id, count(*)
+FROM athlete_split
+WHERE race_id = $1
+GROUP BY split_id;
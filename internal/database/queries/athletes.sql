@@ -1,6 +1,6 @@
 -- name: GetAthleteByID :one
 SELECT a.id, a.race_id, a.first_name, a.last_name, a.gender, a.date_of_birth, a.phone, a.athlete_comments, ea.event_id, ea.wave_id, ea.category_id,
-cb.bib, cb.chip
+ea.category_locked, ea.start_time, ea.handicap, cb.bib, cb.chip
 FROM athletes a
 join event_athlete ea 
 on ea.athlete_id = a.id
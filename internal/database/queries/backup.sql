@@ -0,0 +1,152 @@
+-- name: GetBackupRace :many
+SELECT * FROM races
+WHERE id = $1;
+
+-- name: RestoreRace :copyfrom
+INSERT INTO races
+(id, race_name, timezone, status)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetBackupRaceStatusHistory :many
+SELECT * FROM race_status_history
+WHERE race_id = $1
+ORDER BY id;
+
+-- name: RestoreRaceStatusHistory :copyfrom
+INSERT INTO race_status_history
+(race_id, old_status, new_status, forced, reason, created_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetBackupTimeReaders :many
+SELECT * FROM time_readers
+WHERE race_id = $1;
+
+-- name: RestoreTimeReaders :copyfrom
+INSERT INTO time_readers
+(id, race_id, reader_name)
+VALUES ($1, $2, $3);
+
+-- name: GetBackupEvents :many
+SELECT * FROM events
+WHERE race_id = $1;
+
+-- name: RestoreEvents :copyfrom
+INSERT INTO events
+(id, race_id, event_name, distance_in_meters, event_date)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetBackupSplits :many
+SELECT * FROM splits
+WHERE race_id = $1;
+
+-- name: RestoreSplits :copyfrom
+INSERT INTO splits
+(id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id, cutoff_time, cutoff_tod)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+
+-- name: GetBackupWaves :many
+SELECT * FROM waves
+WHERE race_id = $1;
+
+-- name: RestoreWaves :copyfrom
+INSERT INTO waves
+(id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetBackupWaveAudit :many
+SELECT * FROM wave_audit
+WHERE race_id = $1
+ORDER BY id;
+
+-- name: RestoreWaveAudit :copyfrom
+INSERT INTO wave_audit
+(race_id, wave_id, action, old_start_time, new_start_time, was_launched, is_launched, reason, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetBackupCategories :many
+SELECT * FROM categories
+WHERE race_id = $1;
+
+-- name: RestoreCategories :copyfrom
+INSERT INTO categories
+(id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to, kind, handicap)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+
+-- name: GetBackupAthletes :many
+SELECT * FROM athletes
+WHERE race_id = $1;
+
+-- name: RestoreAthletes :copyfrom
+INSERT INTO athletes
+(id, race_id, first_name, last_name, gender, date_of_birth, phone, athlete_comments, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+
+-- name: GetBackupEventAthletes :many
+SELECT * FROM event_athlete
+WHERE race_id = $1;
+
+-- name: RestoreEventAthletes :copyfrom
+INSERT INTO event_athlete
+(race_id, event_id, athlete_id, wave_id, category_id, bib, status_id, status_reason, status_locked, category_locked, start_time, handicap)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: GetBackupChipBibs :many
+SELECT * FROM chip_bib
+WHERE race_id = $1;
+
+-- name: RestoreChipBibs :copyfrom
+INSERT INTO chip_bib
+(race_id, event_id, chip, bib)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetBackupAthleteCategories :many
+SELECT * FROM athlete_category
+WHERE race_id = $1;
+
+-- name: RestoreAthleteCategories :copyfrom
+INSERT INTO athlete_category
+(race_id, event_id, athlete_id, category_id)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetBackupReaderRecords :many
+SELECT * FROM reader_records
+WHERE race_id = $1
+ORDER BY id;
+
+-- name: RestoreReaderRecords :copyfrom
+-- reads get new IDs, so they don't collide with reads of other races
+INSERT INTO reader_records
+(race_id, chip, tod, reader_name, can_use)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetBackupAthleteSplits :many
+SELECT * FROM athlete_split
+WHERE race_id = $1;
+
+-- name: RestoreAthleteSplits :copyfrom
+INSERT INTO athlete_split
+(race_id, event_id, split_id, athlete_id, tod, gun_time, net_time, gun_rank_gender, gun_rank_category, gun_rank_overall, net_rank_gender, net_rank_category, net_rank_overall, is_manual)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+
+-- name: GetBackupAthleteCategoryRanks :many
+SELECT * FROM athlete_category_rank
+WHERE race_id = $1;
+
+-- name: RestoreAthleteCategoryRanks :copyfrom
+INSERT INTO athlete_category_rank
+(race_id, event_id, split_id, athlete_id, category_id, gun_rank, net_rank)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: GetBackupStatuses :many
+SELECT * FROM statuses
+ORDER BY status_id;
+
+-- name: RestoreStatuses :exec
+INSERT INTO statuses
+(status_id, status_full, status_code, can_get_rank, can_assign_at_split)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (status_id) DO NOTHING;
+
+-- name: DeleteReaderRecordsWithRaceID :exec
+DELETE FROM reader_records
+WHERE race_id = $1;
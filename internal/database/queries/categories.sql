@@ -1,10 +1,10 @@
 -- name: AddOrUpdateCategory :one
 INSERT INTO categories
-(id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to)
-VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+(id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to, kind, handicap)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 ON CONFLICT (id)
 DO UPDATE
-SET category_name=EXCLUDED.category_name, gender=EXCLUDED.gender, age_from=EXCLUDED.age_from, date_from=EXCLUDED.date_from, age_to=EXCLUDED.age_to, date_to=EXCLUDED.date_to
+SET category_name=EXCLUDED.category_name, gender=EXCLUDED.gender, age_from=EXCLUDED.age_from, date_from=EXCLUDED.date_from, age_to=EXCLUDED.age_to, date_to=EXCLUDED.date_to, kind=EXCLUDED.kind, handicap=EXCLUDED.handicap
 RETURNING *;
 
 -- name: DeleteCategoryByID :exec
@@ -12,15 +12,16 @@ DELETE FROM categories
 WHERE id=$1;
 
 -- name: GetCategoriesForEvent :many
-SELECT id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to
+SELECT id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to, kind, handicap
 FROM categories
 WHERE event_id=$1
 ORDER BY age_from ASC;
 
 -- name: GetCategoryForAthlete :one
-SELECT id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to
+SELECT id, race_id, event_id, category_name, gender, age_from, date_from, age_to, date_to, kind, handicap
 FROM categories
 WHERE 
-event_id = $1 
+kind = 'age'
+AND event_id = $1 
 AND gender = $2 
 AND $3 BETWEEN date_from AND date_to;
\ No newline at end of file
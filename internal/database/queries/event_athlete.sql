@@ -1,26 +1,52 @@
 -- name: AddEventAthlete :one
 INSERT INTO event_athlete
-(race_id, event_id, athlete_id, wave_id, category_id, bib)
-VALUES ($1, $2, $3, $4, $5, $6)
+(race_id, event_id, athlete_id, wave_id, category_id, bib, category_locked, start_time, handicap)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (race_id, event_id, athlete_id)
 DO UPDATE
-SET event_id=EXCLUDED.event_id, wave_id=EXCLUDED.wave_id, category_id=EXCLUDED.category_id, bib=EXCLUDED.bib 
+SET event_id=EXCLUDED.event_id, wave_id=EXCLUDED.wave_id, category_id=EXCLUDED.category_id, bib=EXCLUDED.bib, category_locked=EXCLUDED.category_locked, start_time=EXCLUDED.start_time, handicap=EXCLUDED.handicap 
 RETURNING *;
 
 -- name: AddEventAthleteBulk :copyfrom
 INSERT INTO event_athlete
-(race_id, event_id, athlete_id, wave_id, category_id, bib)
-VALUES ($1, $2, $3, $4, $5, $6);
+(race_id, event_id, athlete_id, wave_id, category_id, bib, category_locked, start_time, handicap)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 
 -- name: GetEventAthlete :one
 SELECT race_id, event_id, athlete_id, wave_id, category_id, bib, status_id
 FROM event_athlete
 WHERE athlete_id=$1;
 
+-- name: OverrideStatus :execrows
+UPDATE event_athlete
+SET status_id = $1, status_reason = $2, status_locked = $3
+WHERE athlete_id = $4 AND race_id = $5 AND event_id = $6;
+
+-- name: GetAthletesForCategories :many
+SELECT ea.athlete_id, ea.category_id, a.gender, a.date_of_birth
+FROM event_athlete ea
+JOIN athletes a ON a.id = ea.athlete_id
+WHERE ea.race_id = $1 AND ea.event_id = $2 AND ea.category_locked IS FALSE;
+
+-- name: SetCategoryBulk :execrows
+UPDATE event_athlete ea
+SET category_id = NULLIF(u.category_id, '00000000-0000-0000-0000-000000000000'::uuid)
+FROM unnest(@athlete_ids::uuid[], @category_ids::uuid[]) AS u(athlete_id, category_id)
+WHERE ea.race_id = @race_id
+    AND ea.event_id = @event_id
+    AND ea.athlete_id = u.athlete_id
+    AND ea.category_locked IS FALSE;
+
+-- name: SetStartTimeBulk :exec
+UPDATE event_athlete ea
+SET start_time = u.start_time
+FROM unnest(@athlete_ids::uuid[], @start_times::timestamptz[]) AS u(athlete_id, start_time)
+WHERE ea.race_id = @race_id AND ea.athlete_id = u.athlete_id;
+
 -- name: SetStatus :exec
 UPDATE event_athlete
-SET status_id = $1
-WHERE athlete_id = $2 AND race_id = $3 AND event_id = $4;
+SET status_id = $1, status_reason = $2
+WHERE athlete_id = $3 AND race_id = $4 AND event_id = $5 AND status_locked IS FALSE;
 
 -- name: GetEventAthleteRecordsC :many
 with distinct_rr_tod as (
@@ -38,7 +64,9 @@ select
     cb.chip,
     a.gender,
     s.status_full,
-    w.start_time as wave_start,
+    ea.status_reason,
+    ea.status_locked,
+    (coalesce(ea.start_time, w.start_time) + coalesce(ea.handicap, c.handicap, '0'::interval))::timestamptz as wave_start,
     (
         select array_agg(row(d.id, d.tod)::rr_tod order by d.tod)::rr_tod[]
         from distinct_rr_tod d
@@ -52,6 +80,7 @@ join waves w on
     w.race_id = ea.race_id
     and w.event_id = ea.event_id
     and w.id = ea.wave_id
+left join categories c on c.id = ea.category_id
 join chip_bib cb on
     cb.race_id = ea.race_id
     and cb.event_id = ea.event_id
@@ -61,4 +90,79 @@ join athletes a on
     and a.race_id = ea.race_id
 	where ea.race_id = $1 
 		and ea.event_id = $2 
-		and w.is_launched is true;
+		and w.is_launched is true
+		and (sqlc.narg('chips')::int[] is null or cb.chip = any(sqlc.narg('chips')::int[]));
+
+-- name: GetStartList :many
+SELECT ea.athlete_id, ea.event_id, ea.wave_id, ea.bib, a.first_name, a.last_name, e.event_name, w.wave_name,
+    ea.start_time, w.start_time AS wave_start, coalesce(ea.handicap, c.handicap, '0'::interval)::interval AS handicap
+FROM event_athlete ea
+JOIN athletes a ON a.id = ea.athlete_id
+JOIN events e ON e.id = ea.event_id
+JOIN waves w ON
+    w.race_id = ea.race_id
+    AND w.event_id = ea.event_id
+    AND w.id = ea.wave_id
+LEFT JOIN categories c ON c.id = ea.category_id
+WHERE ea.race_id = $1
+    AND (sqlc.narg('event_id')::uuid IS NULL OR ea.event_id = sqlc.narg('event_id')::uuid)
+    AND (sqlc.narg('wave_id')::uuid IS NULL OR ea.wave_id = sqlc.narg('wave_id')::uuid)
+ORDER BY coalesce(ea.start_time, w.start_time) + coalesce(ea.handicap, c.handicap, '0'::interval), ea.bib;
+
+-- name: GetEventAthleteProgress :many
+SELECT ea.athlete_id, ea.bib, a.first_name, a.last_name, a.phone, s.status_full, (coalesce(ea.start_time, w.start_time) + coalesce(ea.handicap, c.handicap, '0'::interval))::timestamptz as wave_start,
+ast.split_id, ast.tod, ast.gun_time, ast.net_time
+FROM event_athlete ea
+join statuses s on ea.status_id = s.status_id
+join waves w on
+    w.race_id = ea.race_id
+    and w.event_id = ea.event_id
+    and w.id = ea.wave_id
+left join categories c on c.id = ea.category_id
+join athletes a on a.id = ea.athlete_id
+join athlete_split ast on
+    ast.race_id = ea.race_id
+    and ast.event_id = ea.event_id
+    and ast.athlete_id = ea.athlete_id
+WHERE ea.race_id = $1 AND ea.event_id = $2 AND s.status_full = $3
+ORDER BY ea.athlete_id, ast.tod;
+
+-- name: SetStatusBulk :exec
+UPDATE event_athlete ea
+SET status_id = u.status_id, status_reason = u.status_reason
+FROM unnest(@athlete_ids::uuid[], @event_ids::uuid[], @status_ids::int[], @status_reasons::text[])
+    AS u(athlete_id, event_id, status_id, status_reason)
+WHERE ea.race_id = @race_id
+    AND ea.athlete_id = u.athlete_id
+    AND ea.event_id = u.event_id
+    AND ea.status_locked IS FALSE;
+
+-- name: ResetWaveStatuses :exec
+UPDATE event_athlete
+SET status_id = (SELECT status_id FROM statuses WHERE status_full = 'not yet started'), status_reason = ''
+WHERE race_id = $1 AND wave_id = $2 AND status_locked IS FALSE;
+
+-- name: CountWaveAthletes :one
+SELECT count(*)
+FROM event_athlete
+WHERE race_id = $1 AND wave_id = $2;
+
+-- name: ClearCategory :exec
+UPDATE event_athlete
+SET category_id = NULL, category_locked = FALSE
+WHERE race_id = $1 AND category_id = $2;
+
+-- name: CountAthletesByWave :many
+SELECT wave_id, count(*)
+FROM event_athlete
+WHERE race_id = $1
+GROUP BY wave_id;
+
+-- name: CountAthletesByCategory :many
+SELECT c.category_id, count(*)
+FROM (
+    SELECT ea.category_id FROM event_athlete ea WHERE ea.race_id = $1 AND ea.category_id IS NOT NULL
+    UNION ALL
+    SELECT ac.category_id FROM athlete_category ac WHERE ac.race_id = $1
+) c
+GROUP BY c.category_id;
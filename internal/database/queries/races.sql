@@ -4,14 +4,41 @@ ON CONFLICT (id) DO UPDATE
 SET race_name=EXCLUDED.race_name, timezone=EXCLUDED.timezone
 RETURNING *;
 
+-- name: AddRaceStatusHistory :exec
+INSERT INTO race_status_history
+(race_id, old_status, new_status, forced, reason)
+VALUES($1, $2, $3, $4, $5);
+
 -- name: DeleteRace :exec
 DELETE FROM races
 WHERE id=$1;
 
 -- name: GetRaceInfo :one
-SELECT id, race_name, timezone
+SELECT id, race_name, timezone, status
 FROM races
 WHERE id = $1;
 
+-- name: GetRaceStatusHistory :many
+SELECT * FROM race_status_history
+WHERE race_id = $1
+ORDER BY created_at, id;
+
 -- name: GetRaces :many
-SELECT id, race_name, timezone FROM races;
\ No newline at end of file
+SELECT id, race_name, timezone, status FROM races;
+
+-- name: GetRacesWithScheduledWaves :many
+SELECT r.id, r.race_name, r.timezone, r.status FROM races r
+WHERE r.status = $1 AND EXISTS (
+    SELECT 1 FROM waves w
+    WHERE w.race_id = r.id AND w.auto_start IS TRUE AND w.is_launched IS FALSE AND w.start_time <= $2
+);
+
+-- name: GetRacesWithStatus :many
+SELECT id, race_name, timezone, status FROM races
+WHERE status = $1;
+
+-- name: SetRaceStatus :one
+UPDATE races
+SET status = @new_status
+WHERE id = @id AND status = @old_status
+RETURNING *;
@@ -0,0 +1,38 @@
+-- name: GetAthleteReaderRecords :many
+SELECT rr.id, rr.tod, rr.reader_name, rr.can_use, tr.id as time_reader_id
+FROM reader_records rr
+LEFT JOIN time_readers tr on
+    tr.reader_name = rr.reader_name
+    and tr.race_id = rr.race_id
+WHERE rr.race_id = $1 AND rr.chip = $2
+ORDER BY rr.tod, rr.id;
+
+-- name: GetChipsWithNewRecords :many
+SELECT DISTINCT chip
+FROM reader_records
+WHERE race_id = $1 AND xid >= $2;
+
+-- name: GetReadsSnapshotXmin :one
+SELECT pg_snapshot_xmin(pg_current_snapshot())::text::bigint AS snapshot_xmin;
+
+-- name: GetResultsWatermark :one
+SELECT snapshot_xmin
+FROM results_watermark
+WHERE race_id = $1;
+
+-- name: SetResultsWatermark :exec
+INSERT INTO results_watermark (race_id, snapshot_xmin, updated_at)
+VALUES ($1, $2, now())
+ON CONFLICT (race_id)
+DO UPDATE
+SET snapshot_xmin = EXCLUDED.snapshot_xmin, updated_at = EXCLUDED.updated_at;
+
+-- name: DisableTriggerReads :exec
+UPDATE reader_records rr
+SET can_use = false
+FROM waves w
+JOIN time_readers tr ON tr.id = w.trigger_reader_id
+WHERE w.race_id = $1 AND w.id = $2
+    AND rr.race_id = w.race_id
+    AND rr.reader_name = tr.reader_name
+    AND rr.chip = w.trigger_chip;
@@ -13,4 +13,10 @@ RETURNING *;
 
 -- name: DeleteTimeReaderByID :exec
 DELETE FROM time_readers
-WHERE id=$1;
\ No newline at end of file
+WHERE id=$1;
+
+-- name: UpdateTimeReader :one
+UPDATE time_readers
+SET reader_name = $3
+WHERE race_id = $1 AND id = $2
+RETURNING *;
@@ -1,10 +1,10 @@
 -- name: AddOrUpdateWave :one
 INSERT INTO waves
-(id, race_id, event_id, wave_name, start_time, is_launched)
-VALUES($1, $2, $3, $4, $5, $6)
+(id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (race_id, event_id, id)
 DO UPDATE
-SET wave_name=EXCLUDED.wave_name, start_time=EXCLUDED.start_time, is_launched=EXCLUDED.is_launched
+SET wave_name=EXCLUDED.wave_name, start_time=EXCLUDED.start_time, is_launched=EXCLUDED.is_launched, trigger_chip=EXCLUDED.trigger_chip, trigger_reader_id=EXCLUDED.trigger_reader_id, auto_start=EXCLUDED.auto_start
 RETURNING *;
 
 -- name: DeleteWaveByID :exec
@@ -12,23 +12,75 @@ DELETE FROM waves
 WHERE id=$1;
 
 -- name: GetWavesForRace :many
-SELECT id, race_id, event_id, wave_name, start_time, is_launched
+SELECT id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
 FROM waves
 WHERE race_id=$1
 ORDER BY start_time ASC;
 
 -- name: GetWavesForEvent :many
-SELECT id, race_id, event_id, wave_name, start_time, is_launched
+SELECT id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
 FROM waves
 WHERE event_id=$1
 ORDER BY start_time ASC;
 
+-- name: LaunchWavesByTrigger :many
+UPDATE waves w
+SET start_time = t.tod, is_launched = true
+FROM (
+    SELECT tw.id, min(rr.tod)::timestamptz AS tod
+    FROM waves tw
+    JOIN time_readers tr ON tr.id = tw.trigger_reader_id
+    JOIN reader_records rr ON
+        rr.race_id = tw.race_id
+        AND rr.reader_name = tr.reader_name
+        AND rr.chip = tw.trigger_chip
+    WHERE tw.race_id = $1 AND tw.is_launched IS FALSE AND rr.can_use IS TRUE
+    GROUP BY tw.id
+) t
+WHERE w.id = t.id
+RETURNING w.id, w.race_id, w.event_id, w.wave_name, w.start_time, w.is_launched, w.trigger_chip, w.trigger_reader_id, w.auto_start;
+
 -- name: StartWave :exec
 UPDATE waves
 SET is_launched=true
 WHERE id=$1; 
 
 -- name: GetWaveByID :one
-SELECT id, race_id, event_id, wave_name, start_time, is_launched
+SELECT id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
 FROM waves
-WHERE id=$1; 
\ No newline at end of file
+WHERE id=$1; 
+
+-- name: LaunchWave :one
+UPDATE waves
+SET start_time = $3, is_launched = true
+WHERE race_id = $1 AND id = $2 AND is_launched IS FALSE
+RETURNING *;
+
+-- name: ResetWave :one
+UPDATE waves
+SET is_launched = false, auto_start = false
+WHERE race_id = $1 AND id = $2 AND is_launched IS TRUE
+RETURNING *;
+
+-- name: SetWaveStartTime :one
+UPDATE waves
+SET start_time = $3
+WHERE race_id = $1 AND id = $2 AND is_launched IS TRUE
+RETURNING *;
+
+-- name: AddWaveAudit :exec
+INSERT INTO wave_audit
+(race_id, wave_id, action, old_start_time, new_start_time, was_launched, is_launched, reason)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: GetWaveAudit :many
+SELECT *
+FROM wave_audit
+WHERE race_id = $1
+ORDER BY created_at, id;
+
+-- name: LaunchScheduledWaves :many
+UPDATE waves
+SET is_launched = true
+WHERE race_id = $1 AND auto_start IS TRUE AND is_launched IS FALSE AND start_time <= $2
+RETURNING *;
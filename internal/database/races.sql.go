@@ -9,13 +9,14 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const addRace = `-- name: AddRace :one
 INSERT INTO races (id, race_name, timezone) VALUES ($1, $2, $3)
 ON CONFLICT (id) DO UPDATE
 SET race_name=EXCLUDED.race_name, timezone=EXCLUDED.timezone
-RETURNING id, race_name, timezone
+RETURNING id, race_name, timezone, status
 `
 
 type AddRaceParams struct {
@@ -27,10 +28,40 @@ type AddRaceParams struct {
 func (q *Queries) AddRace(ctx context.Context, arg AddRaceParams) (Race, error) {
 	row := q.db.QueryRow(ctx, addRace, arg.ID, arg.RaceName, arg.Timezone)
 	var i Race
-	err := row.Scan(&i.ID, &i.RaceName, &i.Timezone)
+	err := row.Scan(
+		&i.ID,
+		&i.RaceName,
+		&i.Timezone,
+		&i.Status,
+	)
 	return i, err
 }
 
+const addRaceStatusHistory = `-- name: AddRaceStatusHistory :exec
+INSERT INTO race_status_history
+(race_id, old_status, new_status, forced, reason)
+VALUES($1, $2, $3, $4, $5)
+`
+
+type AddRaceStatusHistoryParams struct {
+	RaceID    uuid.UUID
+	OldStatus string
+	NewStatus string
+	Forced    bool
+	Reason    string
+}
+
+func (q *Queries) AddRaceStatusHistory(ctx context.Context, arg AddRaceStatusHistoryParams) error {
+	_, err := q.db.Exec(ctx, addRaceStatusHistory,
+		arg.RaceID,
+		arg.OldStatus,
+		arg.NewStatus,
+		arg.Forced,
+		arg.Reason,
+	)
+	return err
+}
+
 const deleteRace = `-- name: DeleteRace :exec
 DELETE FROM races
 WHERE id=$1
@@ -42,7 +73,7 @@ func (q *Queries) DeleteRace(ctx context.Context, id uuid.UUID) error {
 }
 
 const getRaceInfo = `-- name: GetRaceInfo :one
-SELECT id, race_name, timezone
+SELECT id, race_name, timezone, status
 FROM races
 WHERE id = $1
 `
@@ -50,12 +81,51 @@ WHERE id = $1
 func (q *Queries) GetRaceInfo(ctx context.Context, id uuid.UUID) (Race, error) {
 	row := q.db.QueryRow(ctx, getRaceInfo, id)
 	var i Race
-	err := row.Scan(&i.ID, &i.RaceName, &i.Timezone)
+	err := row.Scan(
+		&i.ID,
+		&i.RaceName,
+		&i.Timezone,
+		&i.Status,
+	)
 	return i, err
 }
 
+const getRaceStatusHistory = `-- name: GetRaceStatusHistory :many
+SELECT id, race_id, old_status, new_status, forced, reason, created_at FROM race_status_history
+WHERE race_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) GetRaceStatusHistory(ctx context.Context, raceID uuid.UUID) ([]RaceStatusHistory, error) {
+	rows, err := q.db.Query(ctx, getRaceStatusHistory, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RaceStatusHistory
+	for rows.Next() {
+		var i RaceStatusHistory
+		if err := rows.Scan(
+			&i.ID,
+			&i.RaceID,
+			&i.OldStatus,
+			&i.NewStatus,
+			&i.Forced,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRaces = `-- name: GetRaces :many
-SELECT id, race_name, timezone FROM races
+SELECT id, race_name, timezone, status FROM races
 `
 
 func (q *Queries) GetRaces(ctx context.Context) ([]Race, error) {
@@ -67,7 +137,12 @@ func (q *Queries) GetRaces(ctx context.Context) ([]Race, error) {
 	var items []Race
 	for rows.Next() {
 		var i Race
-		if err := rows.Scan(&i.ID, &i.RaceName, &i.Timezone); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.RaceName,
+			&i.Timezone,
+			&i.Status,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -77,3 +152,96 @@ func (q *Queries) GetRaces(ctx context.Context) ([]Race, error) {
 	}
 	return items, nil
 }
+
+const getRacesWithScheduledWaves = `-- name: GetRacesWithScheduledWaves :many
+SELECT r.id, r.race_name, r.timezone, r.status FROM races r
+WHERE r.status = $1 AND EXISTS (
+    SELECT 1 FROM waves w
+    WHERE w.race_id = r.id AND w.auto_start IS TRUE AND w.is_launched IS FALSE AND w.start_time <= $2
+)
+`
+
+type GetRacesWithScheduledWavesParams struct {
+	Status    string
+	StartTime pgtype.Timestamptz
+}
+
+func (q *Queries) GetRacesWithScheduledWaves(ctx context.Context, arg GetRacesWithScheduledWavesParams) ([]Race, error) {
+	rows, err := q.db.Query(ctx, getRacesWithScheduledWaves, arg.Status, arg.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Race
+	for rows.Next() {
+		var i Race
+		if err := rows.Scan(
+			&i.ID,
+			&i.RaceName,
+			&i.Timezone,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRacesWithStatus = `-- name: GetRacesWithStatus :many
+SELECT id, race_name, timezone, status FROM races
+WHERE status = $1
+`
+
+func (q *Queries) GetRacesWithStatus(ctx context.Context, status string) ([]Race, error) {
+	rows, err := q.db.Query(ctx, getRacesWithStatus, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Race
+	for rows.Next() {
+		var i Race
+		if err := rows.Scan(
+			&i.ID,
+			&i.RaceName,
+			&i.Timezone,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setRaceStatus = `-- name: SetRaceStatus :one
+UPDATE races
+SET status = $1
+WHERE id = $2 AND status = $3
+RETURNING id, race_name, timezone, status
+`
+
+type SetRaceStatusParams struct {
+	NewStatus string
+	ID        uuid.UUID
+	OldStatus string
+}
+
+func (q *Queries) SetRaceStatus(ctx context.Context, arg SetRaceStatusParams) (Race, error) {
+	row := q.db.QueryRow(ctx, setRaceStatus, arg.NewStatus, arg.ID, arg.OldStatus)
+	var i Race
+	err := row.Scan(
+		&i.ID,
+		&i.RaceName,
+		&i.Timezone,
+		&i.Status,
+	)
+	return i, err
+}
@@ -14,12 +14,12 @@ import (
 
 const addOrUpdateSplit = `-- name: AddOrUpdateSplit :one
 INSERT INTO splits
-(id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id)
-VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+(id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id, cutoff_time, cutoff_tod)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 ON CONFLICT (race_id, event_id, id)
 DO UPDATE
-SET split_name=EXCLUDED.split_name, split_type=EXCLUDED. split_type, distance_from_start=EXCLUDED.distance_from_start, time_reader_id=EXCLUDED.time_reader_id, min_time=EXCLUDED.min_time, max_time=EXCLUDED.max_time, min_lap_time=EXCLUDED.min_lap_time, previous_lap_split_id=EXCLUDED.previous_lap_split_id
-RETURNING id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id
+SET split_name=EXCLUDED.split_name, split_type=EXCLUDED. split_type, distance_from_start=EXCLUDED.distance_from_start, time_reader_id=EXCLUDED.time_reader_id, min_time=EXCLUDED.min_time, max_time=EXCLUDED.max_time, min_lap_time=EXCLUDED.min_lap_time, previous_lap_split_id=EXCLUDED.previous_lap_split_id, cutoff_time=EXCLUDED.cutoff_time, cutoff_tod=EXCLUDED.cutoff_tod
+RETURNING id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id, cutoff_time, cutoff_tod
 `
 
 type AddOrUpdateSplitParams struct {
@@ -34,6 +34,8 @@ type AddOrUpdateSplitParams struct {
 	MaxTime            pgtype.Interval
 	MinLapTime         pgtype.Interval
 	PreviousLapSplitID uuid.NullUUID
+	CutoffTime         pgtype.Interval
+	CutoffTod          pgtype.Timestamptz
 }
 
 func (q *Queries) AddOrUpdateSplit(ctx context.Context, arg AddOrUpdateSplitParams) (Split, error) {
@@ -49,6 +51,8 @@ func (q *Queries) AddOrUpdateSplit(ctx context.Context, arg AddOrUpdateSplitPara
 		arg.MaxTime,
 		arg.MinLapTime,
 		arg.PreviousLapSplitID,
+		arg.CutoffTime,
+		arg.CutoffTod,
 	)
 	var i Split
 	err := row.Scan(
@@ -63,6 +67,8 @@ func (q *Queries) AddOrUpdateSplit(ctx context.Context, arg AddOrUpdateSplitPara
 		&i.MaxTime,
 		&i.MinLapTime,
 		&i.PreviousLapSplitID,
+		&i.CutoffTime,
+		&i.CutoffTod,
 	)
 	return i, err
 }
@@ -78,7 +84,7 @@ func (q *Queries) DeleteSplitByID(ctx context.Context, id uuid.UUID) error {
 }
 
 const getSplitsForEvent = `-- name: GetSplitsForEvent :many
-SELECT id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id
+SELECT id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id, cutoff_time, cutoff_tod
 FROM splits
 WHERE event_id=$1
 ORDER BY distance_from_start ASC
@@ -105,6 +111,8 @@ func (q *Queries) GetSplitsForEvent(ctx context.Context, eventID uuid.UUID) ([]S
 			&i.MaxTime,
 			&i.MinLapTime,
 			&i.PreviousLapSplitID,
+			&i.CutoffTime,
+			&i.CutoffTod,
 		); err != nil {
 			return nil, err
 		}
@@ -117,7 +125,7 @@ func (q *Queries) GetSplitsForEvent(ctx context.Context, eventID uuid.UUID) ([]S
 }
 
 const getSplitsForRace = `-- name: GetSplitsForRace :many
-SELECT id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id
+SELECT id, race_id, event_id, split_name, split_type, distance_from_start, time_reader_id, min_time, max_time, min_lap_time, previous_lap_split_id, cutoff_time, cutoff_tod
 FROM splits
 WHERE race_id=$1
 ORDER BY distance_from_start ASC
@@ -144,6 +152,8 @@ func (q *Queries) GetSplitsForRace(ctx context.Context, raceID uuid.UUID) ([]Spl
 			&i.MaxTime,
 			&i.MinLapTime,
 			&i.PreviousLapSplitID,
+			&i.CutoffTime,
+			&i.CutoffTod,
 		); err != nil {
 			return nil, err
 		}
@@ -68,3 +68,23 @@ func (q *Queries) GetTimeReadersForRace(ctx context.Context, raceID uuid.UUID) (
 	}
 	return items, nil
 }
+
+const updateTimeReader = `-- name: UpdateTimeReader :one
+UPDATE time_readers
+SET reader_name = $3
+WHERE race_id = $1 AND id = $2
+RETURNING id, race_id, reader_name
+`
+
+type UpdateTimeReaderParams struct {
+	RaceID     uuid.UUID
+	ID         uuid.UUID
+	ReaderName string
+}
+
+func (q *Queries) UpdateTimeReader(ctx context.Context, arg UpdateTimeReaderParams) (TimeReader, error) {
+	row := q.db.QueryRow(ctx, updateTimeReader, arg.RaceID, arg.ID, arg.ReaderName)
+	var i TimeReader
+	err := row.Scan(&i.ID, &i.RaceID, &i.ReaderName)
+	return i, err
+}
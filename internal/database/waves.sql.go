@@ -14,21 +14,24 @@ import (
 
 const addOrUpdateWave = `-- name: AddOrUpdateWave :one
 INSERT INTO waves
-(id, race_id, event_id, wave_name, start_time, is_launched)
-VALUES($1, $2, $3, $4, $5, $6)
+(id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (race_id, event_id, id)
 DO UPDATE
-SET wave_name=EXCLUDED.wave_name, start_time=EXCLUDED.start_time, is_launched=EXCLUDED.is_launched
-RETURNING id, race_id, event_id, wave_name, start_time, is_launched
+SET wave_name=EXCLUDED.wave_name, start_time=EXCLUDED.start_time, is_launched=EXCLUDED.is_launched, trigger_chip=EXCLUDED.trigger_chip, trigger_reader_id=EXCLUDED.trigger_reader_id, auto_start=EXCLUDED.auto_start
+RETURNING id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
 `
 
 type AddOrUpdateWaveParams struct {
-	ID         uuid.UUID
-	RaceID     uuid.UUID
-	EventID    uuid.UUID
-	WaveName   string
-	StartTime  pgtype.Timestamp
-	IsLaunched bool
+	ID              uuid.UUID
+	RaceID          uuid.UUID
+	EventID         uuid.UUID
+	WaveName        string
+	StartTime       pgtype.Timestamptz
+	IsLaunched      bool
+	TriggerChip     pgtype.Int4
+	TriggerReaderID uuid.NullUUID
+	AutoStart       bool
 }
 
 func (q *Queries) AddOrUpdateWave(ctx context.Context, arg AddOrUpdateWaveParams) (Wave, error) {
@@ -39,6 +42,9 @@ func (q *Queries) AddOrUpdateWave(ctx context.Context, arg AddOrUpdateWaveParams
 		arg.WaveName,
 		arg.StartTime,
 		arg.IsLaunched,
+		arg.TriggerChip,
+		arg.TriggerReaderID,
+		arg.AutoStart,
 	)
 	var i Wave
 	err := row.Scan(
@@ -48,10 +54,44 @@ func (q *Queries) AddOrUpdateWave(ctx context.Context, arg AddOrUpdateWaveParams
 		&i.WaveName,
 		&i.StartTime,
 		&i.IsLaunched,
+		&i.TriggerChip,
+		&i.TriggerReaderID,
+		&i.AutoStart,
 	)
 	return i, err
 }
 
+const addWaveAudit = `-- name: AddWaveAudit :exec
+INSERT INTO wave_audit
+(race_id, wave_id, action, old_start_time, new_start_time, was_launched, is_launched, reason)
+VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type AddWaveAuditParams struct {
+	RaceID       uuid.UUID
+	WaveID       uuid.UUID
+	Action       string
+	OldStartTime pgtype.Timestamptz
+	NewStartTime pgtype.Timestamptz
+	WasLaunched  bool
+	IsLaunched   bool
+	Reason       string
+}
+
+func (q *Queries) AddWaveAudit(ctx context.Context, arg AddWaveAuditParams) error {
+	_, err := q.db.Exec(ctx, addWaveAudit,
+		arg.RaceID,
+		arg.WaveID,
+		arg.Action,
+		arg.OldStartTime,
+		arg.NewStartTime,
+		arg.WasLaunched,
+		arg.IsLaunched,
+		arg.Reason,
+	)
+	return err
+}
+
 const deleteWaveByID = `-- name: DeleteWaveByID :exec
 DELETE FROM waves
 WHERE id=$1
@@ -62,8 +102,46 @@ func (q *Queries) DeleteWaveByID(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const getWaveAudit = `-- name: GetWaveAudit :many
+SELECT id, race_id, wave_id, action, old_start_time, new_start_time, was_launched, is_launched, reason, created_at
+FROM wave_audit
+WHERE race_id = $1
+ORDER BY created_at, id
+`
+
+func (q *Queries) GetWaveAudit(ctx context.Context, raceID uuid.UUID) ([]WaveAudit, error) {
+	rows, err := q.db.Query(ctx, getWaveAudit, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WaveAudit
+	for rows.Next() {
+		var i WaveAudit
+		if err := rows.Scan(
+			&i.ID,
+			&i.RaceID,
+			&i.WaveID,
+			&i.Action,
+			&i.OldStartTime,
+			&i.NewStartTime,
+			&i.WasLaunched,
+			&i.IsLaunched,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWaveByID = `-- name: GetWaveByID :one
-SELECT id, race_id, event_id, wave_name, start_time, is_launched
+SELECT id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
 FROM waves
 WHERE id=$1
 `
@@ -78,12 +156,15 @@ func (q *Queries) GetWaveByID(ctx context.Context, id uuid.UUID) (Wave, error) {
 		&i.WaveName,
 		&i.StartTime,
 		&i.IsLaunched,
+		&i.TriggerChip,
+		&i.TriggerReaderID,
+		&i.AutoStart,
 	)
 	return i, err
 }
 
 const getWavesForEvent = `-- name: GetWavesForEvent :many
-SELECT id, race_id, event_id, wave_name, start_time, is_launched
+SELECT id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
 FROM waves
 WHERE event_id=$1
 ORDER BY start_time ASC
@@ -105,6 +186,9 @@ func (q *Queries) GetWavesForEvent(ctx context.Context, eventID uuid.UUID) ([]Wa
 			&i.WaveName,
 			&i.StartTime,
 			&i.IsLaunched,
+			&i.TriggerChip,
+			&i.TriggerReaderID,
+			&i.AutoStart,
 		); err != nil {
 			return nil, err
 		}
@@ -117,7 +201,7 @@ func (q *Queries) GetWavesForEvent(ctx context.Context, eventID uuid.UUID) ([]Wa
 }
 
 const getWavesForRace = `-- name: GetWavesForRace :many
-SELECT id, race_id, event_id, wave_name, start_time, is_launched
+SELECT id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
 FROM waves
 WHERE race_id=$1
 ORDER BY start_time ASC
@@ -139,6 +223,129 @@ func (q *Queries) GetWavesForRace(ctx context.Context, raceID uuid.UUID) ([]Wave
 			&i.WaveName,
 			&i.StartTime,
 			&i.IsLaunched,
+			&i.TriggerChip,
+			&i.TriggerReaderID,
+			&i.AutoStart,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const launchScheduledWaves = `-- name: LaunchScheduledWaves :many
+UPDATE waves
+SET is_launched = true
+WHERE race_id = $1 AND auto_start IS TRUE AND is_launched IS FALSE AND start_time <= $2
+RETURNING id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
+`
+
+type LaunchScheduledWavesParams struct {
+	RaceID    uuid.UUID
+	StartTime pgtype.Timestamptz
+}
+
+func (q *Queries) LaunchScheduledWaves(ctx context.Context, arg LaunchScheduledWavesParams) ([]Wave, error) {
+	rows, err := q.db.Query(ctx, launchScheduledWaves, arg.RaceID, arg.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wave
+	for rows.Next() {
+		var i Wave
+		if err := rows.Scan(
+			&i.ID,
+			&i.RaceID,
+			&i.EventID,
+			&i.WaveName,
+			&i.StartTime,
+			&i.IsLaunched,
+			&i.TriggerChip,
+			&i.TriggerReaderID,
+			&i.AutoStart,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const launchWave = `-- name: LaunchWave :one
+UPDATE waves
+SET start_time = $3, is_launched = true
+WHERE race_id = $1 AND id = $2 AND is_launched IS FALSE
+RETURNING id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
+`
+
+type LaunchWaveParams struct {
+	RaceID    uuid.UUID
+	ID        uuid.UUID
+	StartTime pgtype.Timestamptz
+}
+
+func (q *Queries) LaunchWave(ctx context.Context, arg LaunchWaveParams) (Wave, error) {
+	row := q.db.QueryRow(ctx, launchWave, arg.RaceID, arg.ID, arg.StartTime)
+	var i Wave
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.EventID,
+		&i.WaveName,
+		&i.StartTime,
+		&i.IsLaunched,
+		&i.TriggerChip,
+		&i.TriggerReaderID,
+		&i.AutoStart,
+	)
+	return i, err
+}
+
+const launchWavesByTrigger = `-- name: LaunchWavesByTrigger :many
+UPDATE waves w
+SET start_time = t.tod, is_launched = true
+FROM (
+    SELECT tw.id, min(rr.tod)::timestamptz AS tod
+    FROM waves tw
+    JOIN time_readers tr ON tr.id = tw.trigger_reader_id
+    JOIN reader_records rr ON
+        rr.race_id = tw.race_id
+        AND rr.reader_name = tr.reader_name
+        AND rr.chip = tw.trigger_chip
+    WHERE tw.race_id = $1 AND tw.is_launched IS FALSE AND rr.can_use IS TRUE
+    GROUP BY tw.id
+) t
+WHERE w.id = t.id
+RETURNING w.id, w.race_id, w.event_id, w.wave_name, w.start_time, w.is_launched, w.trigger_chip, w.trigger_reader_id, w.auto_start
+`
+
+func (q *Queries) LaunchWavesByTrigger(ctx context.Context, raceID uuid.UUID) ([]Wave, error) {
+	rows, err := q.db.Query(ctx, launchWavesByTrigger, raceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wave
+	for rows.Next() {
+		var i Wave
+		if err := rows.Scan(
+			&i.ID,
+			&i.RaceID,
+			&i.EventID,
+			&i.WaveName,
+			&i.StartTime,
+			&i.IsLaunched,
+			&i.TriggerChip,
+			&i.TriggerReaderID,
+			&i.AutoStart,
 		); err != nil {
 			return nil, err
 		}
@@ -150,6 +357,65 @@ func (q *Queries) GetWavesForRace(ctx context.Context, raceID uuid.UUID) ([]Wave
 	return items, nil
 }
 
+const resetWave = `-- name: ResetWave :one
+UPDATE waves
+SET is_launched = false, auto_start = false
+WHERE race_id = $1 AND id = $2 AND is_launched IS TRUE
+RETURNING id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
+`
+
+type ResetWaveParams struct {
+	RaceID uuid.UUID
+	ID     uuid.UUID
+}
+
+func (q *Queries) ResetWave(ctx context.Context, arg ResetWaveParams) (Wave, error) {
+	row := q.db.QueryRow(ctx, resetWave, arg.RaceID, arg.ID)
+	var i Wave
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.EventID,
+		&i.WaveName,
+		&i.StartTime,
+		&i.IsLaunched,
+		&i.TriggerChip,
+		&i.TriggerReaderID,
+		&i.AutoStart,
+	)
+	return i, err
+}
+
+const setWaveStartTime = `-- name: SetWaveStartTime :one
+UPDATE waves
+SET start_time = $3
+WHERE race_id = $1 AND id = $2 AND is_launched IS TRUE
+RETURNING id, race_id, event_id, wave_name, start_time, is_launched, trigger_chip, trigger_reader_id, auto_start
+`
+
+type SetWaveStartTimeParams struct {
+	RaceID    uuid.UUID
+	ID        uuid.UUID
+	StartTime pgtype.Timestamptz
+}
+
+func (q *Queries) SetWaveStartTime(ctx context.Context, arg SetWaveStartTimeParams) (Wave, error) {
+	row := q.db.QueryRow(ctx, setWaveStartTime, arg.RaceID, arg.ID, arg.StartTime)
+	var i Wave
+	err := row.Scan(
+		&i.ID,
+		&i.RaceID,
+		&i.EventID,
+		&i.WaveName,
+		&i.StartTime,
+		&i.IsLaunched,
+		&i.TriggerChip,
+		&i.TriggerReaderID,
+		&i.AutoStart,
+	)
+	return i, err
+}
+
 const startWave = `-- name: StartWave :exec
 UPDATE waves
 SET is_launched=true
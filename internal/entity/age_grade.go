@@ -0,0 +1,174 @@
+package entity
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AgeGradeTable holds age factors and open class standards per gender and distance.
+// Factor for age is stored at index age - MinAge.
+type AgeGradeTable struct {
+	Version   string              `json:"version"`
+	Source    string              `json:"source"`
+	Standards []*AgeGradeStandard `json:"standards"`
+}
+
+type AgeGradeStandard struct {
+	Gender           CategoryGender `json:"gender"`
+	DistanceInMeters int            `json:"distance_in_meters"`
+	OpenStandardSec  float64        `json:"open_standard_sec"`
+	MinAge           int            `json:"min_age"`
+	Factors          []float64      `json:"factors"`
+}
+
+type AgeGrade struct {
+	Age           int           `json:"age"`
+	Factor        float64       `json:"age_factor"`
+	AgeGradedTime time.Duration `json:"age_graded_time"`
+	Percent       float64       `json:"age_grade_percent"`
+}
+
+// FinishResult is the finish split result of a single finished athlete.
+type FinishResult struct {
+	AthleteID   uuid.UUID      `json:"athlete_id"`
+	Bib         int            `json:"bib"`
+	FirstName   string         `json:"first_name"`
+	LastName    string         `json:"last_name"`
+	Gender      CategoryGender `json:"gender"`
+	DateOfBirth time.Time      `json:"date_of_birth"`
+	GunTime     time.Duration  `json:"gun_time"`
+	NetTime     time.Duration  `json:"net_time"`
+	Handicap    time.Duration  `json:"handicap,omitempty"`
+}
+
+type AgeGradedResult struct {
+	*FinishResult
+	AgeGrade
+	Rank int `json:"age_grade_rank"`
+}
+
+func NewAgeGradeTable(r io.Reader) (*AgeGradeTable, error) {
+	var t AgeGradeTable
+	if err := json.NewDecoder(r).Decode(&t); err != nil {
+		return nil, fmt.Errorf("error decoding age grade table: %w", err)
+	}
+	if len(t.Standards) == 0 {
+		return nil, fmt.Errorf("age grade table must have at least one standard")
+	}
+	for _, s := range t.Standards {
+		if !IsValidGender(s.Gender) {
+			return nil, fmt.Errorf("age grade standard has invalid gender %q", s.Gender)
+		}
+		if s.DistanceInMeters <= 0 || s.OpenStandardSec <= 0 {
+			return nil, fmt.Errorf("age grade standard for %s %dm must have positive distance and open standard", s.Gender, s.DistanceInMeters)
+		}
+		if len(s.Factors) == 0 {
+			return nil, fmt.Errorf("age grade standard for %s %dm has no factors", s.Gender, s.DistanceInMeters)
+		}
+		for _, f := range s.Factors {
+			if f <= 0 || f > 1 {
+				return nil, fmt.Errorf("age grade standard for %s %dm has factor %v out of range (0, 1]", s.Gender, s.DistanceInMeters, f)
+			}
+		}
+	}
+	slices.SortFunc(t.Standards, func(a, b *AgeGradeStandard) int {
+		return cmp.Or(cmp.Compare(a.Gender, b.Gender), cmp.Compare(a.DistanceInMeters, b.DistanceInMeters))
+	})
+	return &t, nil
+}
+
+func (s *AgeGradeStandard) factor(age int) (float64, bool) {
+	i := age - s.MinAge
+	if i < 0 || i >= len(s.Factors) {
+		return 0, false
+	}
+	return s.Factors[i], true
+}
+
+// Factor returns age factor and open standard for gender, age and distance.
+// Distances between two standards are linearly interpolated, distances outside of the table are not graded.
+func (t *AgeGradeTable) Factor(gender CategoryGender, age, distance int) (float64, time.Duration, bool) {
+	var lower, upper *AgeGradeStandard
+	for _, s := range t.Standards {
+		if s.Gender != gender {
+			continue
+		}
+		if s.DistanceInMeters <= distance {
+			lower = s
+		}
+		if s.DistanceInMeters >= distance && upper == nil {
+			upper = s
+		}
+	}
+	if lower == nil || upper == nil {
+		return 0, 0, false
+	}
+	lf, ok := lower.factor(age)
+	if !ok {
+		return 0, 0, false
+	}
+	uf, ok := upper.factor(age)
+	if !ok {
+		return 0, 0, false
+	}
+	if lower == upper {
+		return lf, secToDuration(lower.OpenStandardSec), true
+	}
+	ratio := float64(distance-lower.DistanceInMeters) / float64(upper.DistanceInMeters-lower.DistanceInMeters)
+	factor := lf + (uf-lf)*ratio
+	standard := lower.OpenStandardSec + (upper.OpenStandardSec-lower.OpenStandardSec)*ratio
+	return factor, secToDuration(standard), true
+}
+
+// Grade calculates age graded time and percentage for athlete's time on the distance.
+func (t *AgeGradeTable) Grade(gender CategoryGender, age, distance int, d time.Duration) (AgeGrade, bool) {
+	if d <= 0 {
+		return AgeGrade{}, false
+	}
+	factor, standard, ok := t.Factor(gender, age, distance)
+	if !ok {
+		return AgeGrade{}, false
+	}
+	graded := time.Duration(float64(d) * factor).Round(time.Millisecond)
+	percent := float64(standard) / factor / float64(d) * 100
+	return AgeGrade{
+		Age:           age,
+		Factor:        factor,
+		AgeGradedTime: graded,
+		Percent:       math.Round(percent*100) / 100,
+	}, true
+}
+
+// AgeOn returns full years of athlete born at dob on the date.
+func AgeOn(dob, date time.Time) int {
+	age := date.Year() - dob.Year()
+	if date.Month() < dob.Month() || (date.Month() == dob.Month() && date.Day() < dob.Day()) {
+		age--
+	}
+	return age
+}
+
+// RankAgeGraded sorts results by age grade percentage and assigns ranks, equal percentage gets equal rank.
+func RankAgeGraded(results []*AgeGradedResult) {
+	slices.SortStableFunc(results, func(a, b *AgeGradedResult) int {
+		return cmp.Compare(b.Percent, a.Percent)
+	})
+	for i, r := range results {
+		if i > 0 && results[i-1].Percent == r.Percent {
+			r.Rank = results[i-1].Rank
+			continue
+		}
+		r.Rank = i + 1
+	}
+}
+
+func secToDuration(sec float64) time.Duration {
+	return time.Duration(sec * float64(time.Second))
+}
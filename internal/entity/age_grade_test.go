@@ -0,0 +1,63 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAgeFactors = `{
+	"version": "test",
+	"standards": [
+		{"gender": "male", "distance_in_meters": 5000, "open_standard_sec": 800, "min_age": 40, "factors": [1.0, 0.9, 0.8]},
+		{"gender": "male", "distance_in_meters": 10000, "open_standard_sec": 1700, "min_age": 40, "factors": [1.0, 0.8, 0.6]}
+	]
+}`
+
+func TestAgeGradeTable(t *testing.T) {
+	table, err := NewAgeGradeTable(strings.NewReader(testAgeFactors))
+	assert.NoError(t, err)
+
+	t.Run("exact distance", func(t *testing.T) {
+		g, ok := table.Grade(CategoryGenderMale, 41, 5000, 1000*time.Second)
+		assert.True(t, ok)
+		assert.Equal(t, 0.9, g.Factor)
+		assert.Equal(t, 900*time.Second, g.AgeGradedTime)
+		assert.Equal(t, 88.89, g.Percent)
+	})
+
+	t.Run("interpolated distance", func(t *testing.T) {
+		f, std, ok := table.Factor(CategoryGenderMale, 42, 7500)
+		assert.True(t, ok)
+		assert.InDelta(t, 0.7, f, 1e-9)
+		assert.Equal(t, 1250*time.Second, std)
+	})
+
+	t.Run("not graded outside of table", func(t *testing.T) {
+		_, ok := table.Grade(CategoryGenderMale, 43, 5000, time.Hour)
+		assert.False(t, ok)
+		_, ok = table.Grade(CategoryGenderMale, 40, 42195, time.Hour)
+		assert.False(t, ok)
+		_, ok = table.Grade(CategoryGenderFemale, 40, 5000, time.Hour)
+		assert.False(t, ok)
+	})
+}
+
+func TestAgeOn(t *testing.T) {
+	dob := time.Date(1980, time.May, 20, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 44, AgeOn(dob, time.Date(2025, time.May, 19, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 45, AgeOn(dob, time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)))
+}
+
+func TestRankAgeGraded(t *testing.T) {
+	res := []*AgeGradedResult{
+		{AgeGrade: AgeGrade{Percent: 70}},
+		{AgeGrade: AgeGrade{Percent: 80}},
+		{AgeGrade: AgeGrade{Percent: 70}},
+	}
+	RankAgeGraded(res)
+	assert.Equal(t, []int{1, 2, 2}, []int{res[0].Rank, res[1].Rank, res[2].Rank})
+	assert.Equal(t, 80.0, res[0].Percent)
+}
@@ -1,27 +1,31 @@
 package entity
 
 import (
-	"fmt"
 	"slices"
 	"time"
 
+	"github.com/ecoarchie/timeit/pkg/validator"
 	"github.com/google/uuid"
 )
 
 type Athlete struct {
-	ID          uuid.UUID      `json:"athlete_id"`
-	RaceID      uuid.UUID      `json:"race_id"`
-	EventID     uuid.UUID      `json:"event_id"`
-	WaveID      uuid.UUID      `json:"wave_id"`
-	Bib         int            `json:"bib"`
-	Chip        int            `json:"chip"`
-	FirstName   string         `json:"first_name"`
-	LastName    string         `json:"last_name"`
-	Gender      CategoryGender `json:"gender"`
-	DateOfBirth time.Time      `json:"date_of_birth"`
-	CategoryID  uuid.NullUUID  `json:"category_id"`
-	Phone       string         `json:"phone"`
-	Comments    string         `json:"comments"`
+	ID             uuid.UUID      `json:"athlete_id"`
+	RaceID         uuid.UUID      `json:"race_id"`
+	EventID        uuid.UUID      `json:"event_id"`
+	WaveID         uuid.UUID      `json:"wave_id"`
+	Bib            int            `json:"bib"`
+	Chip           int            `json:"chip"`
+	FirstName      string         `json:"first_name"`
+	LastName       string         `json:"last_name"`
+	Gender         CategoryGender `json:"gender"`
+	DateOfBirth    time.Time      `json:"date_of_birth"`
+	CategoryID     uuid.NullUUID  `json:"category_id"`
+	CategoryLocked bool           `json:"category_locked"`
+	Categories     []uuid.UUID    `json:"categories"`
+	StartTime      time.Time      `json:"start_time"`
+	Handicap       *time.Duration `json:"handicap"` // nil if handicap of athlete's category applies
+	Phone          string         `json:"phone"`
+	Comments       string         `json:"comments"`
 }
 
 type AthleteCreateRequest struct {
@@ -36,6 +40,9 @@ type AthleteCreateRequest struct {
 	Gender      CategoryGender `json:"gender"`
 	DateOfBirth time.Time      `json:"date_of_birth"`
 	CategoryID  uuid.NullUUID  `json:"category_id"`
+	Categories  []uuid.UUID    `json:"categories"`
+	StartTime   time.Time      `json:"start_time"`
+	Handicap    *time.Duration `json:"handicap"`
 	Phone       string         `json:"phone"`
 	Comments    string         `json:"comments"`
 }
@@ -58,8 +65,8 @@ const (
 )
 
 var StatusAutoTransitionMap = map[Status][]Status{
-	NYS: {RUN, FIN},
-	RUN: {FIN, NYS},
+	NYS: {RUN, FIN, DNF},
+	RUN: {FIN, NYS, DNF},
 	FIN: {RUN, NYS},
 	DSQ: {},
 	QRT: {},
@@ -71,22 +78,36 @@ func ValidStatusTransition(src Status, dst Status) bool {
 	return slices.Contains(StatusAutoTransitionMap[src], dst)
 }
 
-func NewAthlete(req AthleteCreateRequest) (*Athlete, error) {
-	if req.RaceID == uuid.Nil {
-		return nil, fmt.Errorf("athlete race must be assigned")
-	}
-	if req.EventID == uuid.Nil {
-		return nil, fmt.Errorf("athlete event must be assigned")
-	}
-	if req.WaveID == uuid.Nil {
-		return nil, fmt.Errorf("athlete wave be assigned")
-	}
-	if req.Bib <= 0 {
-		return nil, fmt.Errorf("athlete bib must be greater than 0")
-	}
-	if req.Chip <= 0 {
-		return nil, fmt.Errorf("athlete chip must be greater than 0")
-	}
+func IsValidStatus(s Status) bool {
+	_, ok := StatusAutoTransitionMap[s]
+	return ok
+}
+
+// AthleteStatus is the status of athlete in event. Locked status is set by officials
+// and is not changed by results calculation.
+type AthleteStatus struct {
+	AthleteID uuid.UUID `json:"athlete_id"`
+	EventID   uuid.UUID `json:"event_id"`
+	Status    Status    `json:"status"`
+	Reason    string    `json:"reason"`
+	Locked    bool      `json:"locked"`
+}
+
+type StatusOverrideRequest struct {
+	Status Status `json:"status"`
+	Reason string `json:"reason"`
+	Locked *bool  `json:"locked"`
+}
+
+// NewAthlete builds athlete from request, missing names, gender and date of birth are set to defaults.
+// Returns nil if request is invalid.
+func NewAthlete(req AthleteCreateRequest, v *validator.Validator) *Athlete {
+	v.CheckField(req.RaceID != uuid.Nil, "race_id", validator.CodeRequired, "athlete race must be assigned")
+	v.CheckField(req.EventID != uuid.Nil, "event_id", validator.CodeRequired, "athlete event must be assigned")
+	v.CheckField(req.WaveID != uuid.Nil, "wave_id", validator.CodeRequired, "athlete wave must be assigned")
+	v.CheckField(req.Bib > 0, "bib", validator.CodeOutOfRange, "must be greater than 0")
+	v.CheckField(req.Chip > 0, "chip", validator.CodeOutOfRange, "must be greater than 0")
+	v.CheckField(req.Handicap == nil || *req.Handicap >= 0, "handicap", validator.CodeOutOfRange, "must not be negative")
 	if req.FirstName == "" {
 		req.FirstName = "athlete"
 	}
@@ -98,21 +119,16 @@ func NewAthlete(req AthleteCreateRequest) (*Athlete, error) {
 	if req.Gender == "" {
 		req.Gender = "unknown"
 	}
-	if !IsValidGender(req.Gender) {
-		return nil, fmt.Errorf("invalid gender for athlete")
-	}
+	v.CheckField(IsValidGender(req.Gender), "gender", validator.CodeInvalid, "must be male, female, mixed or unknown")
 
 	// birth date check
-	const zeroBirthDate = "1900-01-01"
-	zbd, err := time.Parse(time.DateOnly, zeroBirthDate)
-	if err != nil {
-		return nil, fmt.Errorf("error parsing zero birth date")
-	}
+	zbd := time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
 	if req.DateOfBirth.IsZero() {
 		req.DateOfBirth = zbd
 	}
-	if req.DateOfBirth.Before(zbd) || req.DateOfBirth.After(time.Now()) {
-		return nil, fmt.Errorf("athlete's birth date is incorrect")
+	v.CheckField(!req.DateOfBirth.Before(zbd) && !req.DateOfBirth.After(time.Now()), "date_of_birth", validator.CodeOutOfRange, "must be between 1900-01-01 and today")
+	if !v.Valid() {
+		return nil
 	}
 
 	var id uuid.UUID
@@ -133,9 +149,12 @@ func NewAthlete(req AthleteCreateRequest) (*Athlete, error) {
 		Gender:      req.Gender,
 		DateOfBirth: req.DateOfBirth,
 		CategoryID:  req.CategoryID,
+		Categories:  req.Categories,
+		StartTime:   req.StartTime,
+		Handicap:    req.Handicap,
 		Phone:       req.Phone,
 		Comments:    req.Comments,
-	}, nil
+	}
 }
 
 func IsValidGender(c CategoryGender) bool {
@@ -21,16 +21,45 @@ const (
 	CategoryGenderUnknown CategoryGender = "unknown"
 )
 
+// CategoryKind defines how athletes become members of a category. Athletes are matched to age categories
+// by gender and date of birth, membership in custom categories is imported or set manually.
+type CategoryKind string
+
+const (
+	CategoryKindAge    CategoryKind = "age"
+	CategoryKindCustom CategoryKind = "custom"
+)
+
 type Category struct {
 	ID       uuid.UUID      `json:"category_id"`
 	RaceID   uuid.UUID      `json:"race_id"`
 	EventID  uuid.UUID      `json:"event_id"`
 	Name     string         `json:"category_name"`
+	Kind     CategoryKind   `json:"kind"`
 	Gender   CategoryGender `json:"gender"`
 	AgeFrom  int            `json:"age_from"`
 	DateFrom time.Time      `json:"date_from"`
 	AgeTo    int            `json:"age_to"`
 	DateTo   time.Time      `json:"date_to"`
+	Handicap time.Duration  `json:"handicap"`
+}
+
+func IsValidCategoryKind(k CategoryKind) bool {
+	return k == CategoryKindAge || k == CategoryKindCustom
+}
+
+// CategoryResult is athlete's result at a split ranked within a category
+type CategoryResult struct {
+	AthleteID uuid.UUID     `json:"athlete_id"`
+	Bib       int           `json:"bib"`
+	FirstName string        `json:"first_name"`
+	LastName  string        `json:"last_name"`
+	Status    Status        `json:"status"`
+	TOD       time.Time     `json:"tod"`
+	GunTime   time.Duration `json:"gun_time"`
+	NetTime   time.Duration `json:"net_time"`
+	GunRank   int           `json:"gun_rank,omitempty"`
+	NetRank   int           `json:"net_rank,omitempty"`
 }
 
 func GenderFrom(g string) CategoryGender {
@@ -49,10 +78,36 @@ func GenderFrom(g string) CategoryGender {
 }
 
 func NewCategory(dto *dto.CategoryDTO, eventDate time.Time, v *validator.Validator) *Category {
-	v.Check(IsValidGender(CategoryGender(dto.Gender)), "gender", "must be male, female or mixed")
-	v.Check(dto.AgeFrom >= 0, "category age from", "must be greater or equal to 0")
-	v.Check(dto.AgeTo > 0, "category age to", "must be greater than 0")
-	v.Check(dto.AgeFrom < dto.AgeTo, "category age", "upper age limit must be greater than lower age limit")
+	kind := CategoryKind(dto.Kind)
+	if kind == "" {
+		kind = CategoryKindAge
+	}
+	v.CheckField(IsValidCategoryKind(kind), "kind", validator.CodeInvalid, "must be age or custom")
+	var handicap time.Duration
+	if dto.Handicap != "" {
+		var err error
+		handicap, err = time.ParseDuration(dto.Handicap)
+		v.CheckField(err == nil, "handicap", validator.CodeFormat, "must be duration string")
+	}
+	v.CheckField(handicap >= 0, "handicap", validator.CodeOutOfRange, "must not be negative")
+	if kind == CategoryKindCustom {
+		if !v.Valid() {
+			return nil
+		}
+		return &Category{
+			ID:       dto.ID,
+			RaceID:   dto.RaceID,
+			EventID:  dto.EventID,
+			Name:     dto.Name,
+			Kind:     kind,
+			Gender:   CategoryGenderMixed,
+			Handicap: handicap,
+		}
+	}
+	v.CheckField(IsValidGender(CategoryGender(dto.Gender)), "category_gender", validator.CodeInvalid, "must be male, female or mixed")
+	v.CheckField(dto.AgeFrom >= 0, "age_from", validator.CodeOutOfRange, "must be greater or equal to 0")
+	v.CheckField(dto.AgeTo > 0, "age_to", validator.CodeOutOfRange, "must be greater than 0")
+	v.CheckField(dto.AgeFrom < dto.AgeTo, "age_to", validator.CodeOutOfRange, "upper age limit must be greater than lower age limit")
 	if !v.Valid() {
 		return nil
 	}
@@ -63,16 +118,42 @@ func NewCategory(dto *dto.CategoryDTO, eventDate time.Time, v *validator.Validat
 		RaceID:   dto.RaceID,
 		EventID:  dto.EventID,
 		Name:     dto.Name,
+		Kind:     kind,
 		Gender:   CategoryGender(dto.Gender),
 		AgeFrom:  dto.AgeFrom,
 		DateFrom: dateFrom,
 		AgeTo:    dto.AgeTo,
 		DateTo:   dateTo,
+		Handicap: handicap,
 	}
 }
 
+// DTO returns category in the form it is configured in. Whether age limits are counted from race date
+// is restored from date range, it is counted from the end or the beginning of the year otherwise.
+func (c *Category) DTO() *dto.CategoryDTO {
+	cd := &dto.CategoryDTO{
+		ID:       c.ID,
+		RaceID:   c.RaceID,
+		EventID:  c.EventID,
+		Name:     c.Name,
+		Kind:     string(c.Kind),
+		Gender:   string(c.Gender),
+		AgeFrom:  c.AgeFrom,
+		AgeTo:    c.AgeTo,
+		Handicap: c.Handicap.String(),
+	}
+	if c.Kind == CategoryKindAge {
+		cd.FromRaceDate = c.DateTo.Month() != time.December || c.DateTo.Day() != 31
+		cd.ToRaceDate = c.DateFrom.Month() != time.January || c.DateFrom.Day() != 1
+	}
+	return cd
+}
+
 // TEST
 func (c *Category) Valid(gender CategoryGender, dob time.Time) bool {
+	if c.Kind == CategoryKindCustom {
+		return false
+	}
 	return c.Gender == gender && ((dob.Before(c.DateTo) || dob.Equal(c.DateTo)) && (dob.After(c.DateFrom) || dob.Equal(c.DateFrom)))
 }
 
@@ -90,32 +171,86 @@ func GetDateRange(dto *dto.CategoryDTO, eventDate time.Time) (dateFrom, dateTo t
 	return dateFrom, dateTo
 }
 
+// CheckCategoriesBoundary checks that there are no gaps and overlaps between neighbouring age categories
+// of every gender. Ages below the youngest and above the oldest category are not checked, event has no
+// expected age range, athletes of these ages get no category. Custom and nil categories are skipped,
+// errors are reported at index of the older category of the pair.
 func CheckCategoriesBoundary(cats []*Category, v *validator.Validator) {
-	slices.SortFunc(cats, func(a, b *Category) int {
-		return cmp.Compare(a.AgeFrom, b.AgeFrom)
-	})
-	genderMap := map[CategoryGender][]*Category{}
-	for _, c := range cats {
-		genderMap[c.Gender] = append(genderMap[c.Gender], c)
-	}
-	for _, cc := range genderMap {
-		if len(cc) > 2 {
-			for i := 1; i < len(cc)-1; i++ {
-				if cc[i].DateTo.After(cc[i-1].DateFrom) || cc[i-1].DateFrom.Sub(cc[i].DateTo) > time.Duration(time.Hour*24) {
-					v.AddError(cc[i-1].Name+" and "+cc[i].Name, "not consequent dates")
-				} else if cc[i].DateFrom.Before(cc[i+1].DateTo) || cc[i].DateFrom.Sub(cc[i+1].DateTo) > time.Duration(time.Hour*24) {
-					v.AddError(cc[i].Name+" and "+cc[i+1].Name, "not consequent dates")
-				}
-			}
+	genderMap := map[CategoryGender][]int{}
+	for i, c := range cats {
+		if c != nil && c.Kind == CategoryKindAge {
+			genderMap[c.Gender] = append(genderMap[c.Gender], i)
 		}
-		if len(cc) == 2 {
-			if cc[0].DateFrom.Before(cc[1].DateTo) || cc[0].DateFrom.Sub(cc[1].DateTo) > time.Duration(time.Hour*24) {
-				v.AddError(cc[0].Name+" and "+cc[1].Name, "not consequent dates")
+	}
+	for _, idx := range genderMap {
+		slices.SortStableFunc(idx, func(a, b int) int {
+			return cmp.Compare(cats[a].AgeFrom, cats[b].AgeFrom)
+		})
+		for k := 1; k < len(idx); k++ {
+			younger, older := cats[idx[k-1]], cats[idx[k]]
+			cv := v.Item("categories", idx[k])
+			switch {
+			case !younger.DateFrom.After(older.DateTo):
+				cv.AddFieldError("age_from", validator.CodeOverlap, fmt.Sprintf("not consequent dates, age range overlaps category %q", younger.Name))
+			case younger.DateFrom.Sub(older.DateTo) > 24*time.Hour:
+				cv.AddFieldError("age_from", validator.CodeGap, fmt.Sprintf("not consequent dates, ages %d to %d after category %q are not covered", younger.AgeTo+1, older.AgeFrom-1, younger.Name))
 			}
 		}
 	}
 }
 
+// CategoryFor returns the first of categories matching athlete's gender and date of birth.
+func CategoryFor(categories []*Category, gender CategoryGender, dob time.Time) uuid.NullUUID {
+	idx := slices.IndexFunc(categories, func(c *Category) bool {
+		return c.Valid(gender, dob)
+	})
+	if idx == -1 {
+		return uuid.NullUUID{}
+	}
+	return uuid.NullUUID{UUID: categories[idx].ID, Valid: true}
+}
+
+// HandicapsChanged reports whether start offset of any category present in both old and updated categories changed
+func HandicapsChanged(old, updated []*Category) bool {
+	for _, u := range updated {
+		idx := slices.IndexFunc(old, func(o *Category) bool {
+			return o.ID == u.ID
+		})
+		if idx != -1 && old[idx].Handicap != u.Handicap {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoriesChanged reports whether athletes may match different categories after event categories
+// were replaced with updated ones.
+func CategoriesChanged(old, updated []*Category) bool {
+	if len(old) != len(updated) {
+		return true
+	}
+	for _, u := range updated {
+		idx := slices.IndexFunc(old, func(o *Category) bool {
+			return o.ID == u.ID
+		})
+		if idx == -1 {
+			return true
+		}
+		o := old[idx]
+		if o.Kind != u.Kind || o.Gender != u.Gender || !o.DateFrom.Equal(u.DateFrom) || !o.DateTo.Equal(u.DateTo) {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryReassignment reports athletes moved to other categories after event categories changed.
+type CategoryReassignment struct {
+	EventID uuid.UUID `json:"event_id"`
+	Checked int       `json:"checked"`
+	Moved   int       `json:"moved"`
+}
+
 func (c Category) String() string {
 	return fmt.Sprintf(
 		"Category {\n"+
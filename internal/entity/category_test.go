@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryFor(t *testing.T) {
+	m18 := &Category{
+		ID:       uuid.New(),
+		Gender:   CategoryGenderMale,
+		DateFrom: time.Date(1986, time.January, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:   time.Date(2007, time.December, 31, 23, 59, 59, 0, time.UTC),
+	}
+	m40 := &Category{
+		ID:       uuid.New(),
+		Gender:   CategoryGenderMale,
+		DateFrom: time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:   time.Date(1985, time.December, 31, 23, 59, 59, 0, time.UTC),
+	}
+	cats := []*Category{m18, m40}
+
+	got := CategoryFor(cats, CategoryGenderMale, time.Date(1990, time.May, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, uuid.NullUUID{UUID: m18.ID, Valid: true}, got)
+
+	got = CategoryFor(cats, CategoryGenderMale, time.Date(1985, time.May, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, uuid.NullUUID{UUID: m40.ID, Valid: true}, got)
+
+	got = CategoryFor(cats, CategoryGenderFemale, time.Date(1990, time.May, 1, 0, 0, 0, 0, time.UTC))
+	assert.False(t, got.Valid)
+}
+
+func TestCategoriesChanged(t *testing.T) {
+	c := &Category{
+		ID:       uuid.New(),
+		Gender:   CategoryGenderFemale,
+		DateFrom: time.Date(1986, time.January, 1, 0, 0, 0, 0, time.UTC),
+		DateTo:   time.Date(2007, time.December, 31, 23, 59, 59, 0, time.UTC),
+	}
+	renamed := *c
+	renamed.Name = "F18"
+	assert.False(t, CategoriesChanged([]*Category{c}, []*Category{&renamed}))
+
+	moved := *c
+	moved.DateFrom = moved.DateFrom.AddDate(-5, 0, 0)
+	assert.True(t, CategoriesChanged([]*Category{c}, []*Category{&moved}))
+
+	other := *c
+	other.ID = uuid.New()
+	assert.True(t, CategoriesChanged([]*Category{c}, []*Category{&other}))
+	assert.True(t, CategoriesChanged([]*Category{c}, nil))
+}
+
+func TestCustomCategory(t *testing.T) {
+	eventDate := time.Date(2025, time.May, 20, 0, 0, 0, 0, time.UTC)
+	v := validator.New()
+	elite := NewCategory(&dto.CategoryDTO{ID: uuid.New(), Name: "Elite", Kind: "custom"}, eventDate, v)
+	assert.True(t, v.Valid())
+	assert.Equal(t, CategoryKindCustom, elite.Kind)
+
+	// custom categories are never matched by gender and age
+	got := CategoryFor([]*Category{elite}, CategoryGenderMixed, time.Time{})
+	assert.False(t, got.Valid)
+
+	v = validator.New()
+	NewCategory(&dto.CategoryDTO{Name: "Corporate", Kind: "club"}, eventDate, v)
+	assert.False(t, v.Valid())
+}
+
+func TestCategoryHandicapFormat(t *testing.T) {
+	eventDate := time.Date(2025, time.May, 20, 0, 0, 0, 0, time.UTC)
+	v := validator.New()
+	NewCategory(&dto.CategoryDTO{Name: "Elite", Kind: "custom", Handicap: "5 minutes"}, eventDate, v)
+	assert.Equal(t, []validator.FieldError{{Path: "handicap", Code: validator.CodeFormat, Message: "must be duration string"}}, v.FieldErrors())
+
+	v = validator.New()
+	c := NewCategory(&dto.CategoryDTO{Name: "Elite", Kind: "custom"}, eventDate, v)
+	assert.True(t, v.Valid())
+	assert.Zero(t, c.Handicap)
+}
+
+func TestCategoryDTO(t *testing.T) {
+	eventDate := time.Date(2025, time.May, 20, 0, 0, 0, 0, time.UTC)
+	for _, cd := range []*dto.CategoryDTO{
+		{Name: "M18", Kind: "age", Gender: "male", AgeFrom: 18, AgeTo: 39},
+		{Name: "M18", Kind: "age", Gender: "male", AgeFrom: 18, FromRaceDate: true, AgeTo: 39},
+		{Name: "M18", Kind: "age", Gender: "male", AgeFrom: 18, AgeTo: 39, ToRaceDate: true},
+		{Name: "M18", Kind: "age", Gender: "male", AgeFrom: 18, FromRaceDate: true, AgeTo: 39, ToRaceDate: true, Handicap: "5m0s"},
+	} {
+		v := validator.New()
+		c := NewCategory(cd, eventDate, v)
+		assert.True(t, v.Valid())
+		got := NewCategory(c.DTO(), eventDate, v)
+		assert.True(t, v.Valid())
+		assert.Equal(t, c, got)
+		assert.Equal(t, cd.FromRaceDate, c.DTO().FromRaceDate)
+		assert.Equal(t, cd.ToRaceDate, c.DTO().ToRaceDate)
+	}
+}
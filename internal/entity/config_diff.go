@@ -0,0 +1,226 @@
+package entity
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// ConfigAction is a change of single item of race config
+type ConfigAction string
+
+const (
+	ConfigActionAdd    ConfigAction = "add"
+	ConfigActionUpdate ConfigAction = "update"
+	ConfigActionDelete ConfigAction = "delete"
+)
+
+// ConfigItemKind is kind of race config item
+type ConfigItemKind string
+
+const (
+	ConfigItemRace       ConfigItemKind = "race"
+	ConfigItemTimeReader ConfigItemKind = "time_reader"
+	ConfigItemEvent      ConfigItemKind = "event"
+	ConfigItemSplit      ConfigItemKind = "split"
+	ConfigItemWave       ConfigItemKind = "wave"
+	ConfigItemCategory   ConfigItemKind = "category"
+)
+
+// ConfigChange is a change of single item between saved and incoming race config. Warning describes
+// what is lost when destructive change is applied. Blocking change can't be applied at all.
+type ConfigChange struct {
+	Action   ConfigAction   `json:"action"`
+	Kind     ConfigItemKind `json:"kind"`
+	ID       uuid.UUID      `json:"id"`
+	EventID  uuid.UUID      `json:"event_id,omitempty"`
+	Name     string         `json:"name"`
+	Warning  string         `json:"warning,omitempty"`
+	Blocking bool           `json:"blocking,omitempty"`
+}
+
+// RaceConfigDiff lists changes between saved and incoming race config
+type RaceConfigDiff struct {
+	RaceID  uuid.UUID       `json:"race_id"`
+	Changes []*ConfigChange `json:"changes"`
+}
+
+// ConfigUsage counts athletes and results depending on saved items of race config
+type ConfigUsage struct {
+	SplitResults     map[uuid.UUID]int
+	WaveAthletes     map[uuid.UUID]int
+	CategoryAthletes map[uuid.UUID]int
+}
+
+// Deleted returns deleted items of kind
+func (d *RaceConfigDiff) Deleted(kind ConfigItemKind) []*ConfigChange {
+	var res []*ConfigChange
+	for _, c := range d.Changes {
+		if c.Action == ConfigActionDelete && c.Kind == kind {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// Warnings returns changes with warnings
+func (d *RaceConfigDiff) Warnings() []*ConfigChange {
+	var res []*ConfigChange
+	for _, c := range d.Changes {
+		if c.Warning != "" {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// DiffRaceConfig compares saved race config prev with incoming config next. Items of deleted events are not
+// listed separately, they are deleted together with the event. prev is nil for a new race.
+func DiffRaceConfig(prev, next *RaceModel, usage *ConfigUsage) *RaceConfigDiff {
+	d := &RaceConfigDiff{RaceID: next.ID, Changes: []*ConfigChange{}}
+	if prev == nil {
+		prev = &RaceModel{}
+	}
+	if usage == nil {
+		usage = &ConfigUsage{}
+	}
+	if prev.Race == nil {
+		d.add(ConfigActionAdd, ConfigItemRace, next.ID, uuid.Nil, next.Name)
+	} else if prev.Name != next.Name || prev.Timezone != next.Timezone {
+		d.add(ConfigActionUpdate, ConfigItemRace, next.ID, uuid.Nil, next.Name)
+	}
+
+	diffItems(d, ConfigItemTimeReader, uuid.Nil, prev.TimeReaders, next.TimeReaders,
+		func(tr *TimeReader) (uuid.UUID, string) { return tr.ID, tr.ReaderName },
+		func(a, b *TimeReader) bool { return a.ReaderName == b.ReaderName },
+	)
+	diffItems(d, ConfigItemEvent, uuid.Nil, prev.Events, next.Events,
+		func(e *Event) (uuid.UUID, string) { return e.ID, e.Name },
+		func(a, b *Event) bool {
+			return a.Name == b.Name && a.DistanceInMeters == b.DistanceInMeters && a.EventDate.Equal(b.EventDate)
+		},
+	)
+	for _, ne := range next.Events {
+		pe := &Event{}
+		if idx := slices.IndexFunc(prev.Events, func(e *Event) bool { return e.ID == ne.ID }); idx != -1 {
+			pe = prev.Events[idx]
+		}
+		diffItems(d, ConfigItemSplit, ne.ID, pe.Splits, ne.Splits,
+			func(s *Split) (uuid.UUID, string) { return s.ID, s.Name },
+			splitsEqual,
+		)
+		diffItems(d, ConfigItemWave, ne.ID, pe.Waves, ne.Waves,
+			func(w *Wave) (uuid.UUID, string) { return w.ID, w.Name },
+			wavesEqual,
+		)
+		diffItems(d, ConfigItemCategory, ne.ID, pe.Categories, ne.Categories,
+			func(c *Category) (uuid.UUID, string) { return c.ID, c.Name },
+			categoriesEqual,
+		)
+	}
+	d.warn(prev, usage)
+	return d
+}
+
+func (d *RaceConfigDiff) add(action ConfigAction, kind ConfigItemKind, id, eventID uuid.UUID, name string) {
+	d.Changes = append(d.Changes, &ConfigChange{
+		Action:  action,
+		Kind:    kind,
+		ID:      id,
+		EventID: eventID,
+		Name:    name,
+	})
+}
+
+func diffItems[T any](d *RaceConfigDiff, kind ConfigItemKind, eventID uuid.UUID, prev, next []T, key func(T) (uuid.UUID, string), equal func(a, b T) bool) {
+	for _, n := range next {
+		id, name := key(n)
+		idx := slices.IndexFunc(prev, func(p T) bool {
+			pid, _ := key(p)
+			return pid == id
+		})
+		switch {
+		case idx == -1:
+			d.add(ConfigActionAdd, kind, id, eventID, name)
+		case !equal(prev[idx], n):
+			d.add(ConfigActionUpdate, kind, id, eventID, name)
+		}
+	}
+	for _, p := range prev {
+		id, name := key(p)
+		if !slices.ContainsFunc(next, func(n T) bool {
+			nid, _ := key(n)
+			return nid == id
+		}) {
+			d.add(ConfigActionDelete, kind, id, eventID, name)
+		}
+	}
+}
+
+// warn describes athletes and results lost by deletes
+func (d *RaceConfigDiff) warn(prev *RaceModel, usage *ConfigUsage) {
+	for _, c := range d.Changes {
+		if c.Action != ConfigActionDelete {
+			continue
+		}
+		switch c.Kind {
+		case ConfigItemEvent:
+			athletes := 0
+			if idx := slices.IndexFunc(prev.Events, func(e *Event) bool { return e.ID == c.ID }); idx != -1 {
+				for _, w := range prev.Events[idx].Waves {
+					athletes += usage.WaveAthletes[w.ID]
+				}
+			}
+			if athletes > 0 {
+				c.Warning = fmt.Sprintf("event has %d athletes, they are removed with their results", athletes)
+			}
+		case ConfigItemSplit:
+			if n := usage.SplitResults[c.ID]; n > 0 {
+				c.Warning = fmt.Sprintf("split has %d results, they are removed", n)
+			}
+		case ConfigItemWave:
+			if n := usage.WaveAthletes[c.ID]; n > 0 {
+				c.Warning = fmt.Sprintf("wave has %d athletes, move them to another wave first", n)
+				c.Blocking = true
+			}
+		case ConfigItemCategory:
+			if n := usage.CategoryAthletes[c.ID]; n > 0 {
+				c.Warning = fmt.Sprintf("category has %d athletes, they are matched to remaining categories", n)
+			}
+		}
+	}
+}
+
+func splitsEqual(a, b *Split) bool {
+	return a.Name == b.Name &&
+		a.Type == b.Type &&
+		a.DistanceFromStart == b.DistanceFromStart &&
+		a.TimeReaderID == b.TimeReaderID &&
+		a.MinTime == b.MinTime &&
+		a.MaxTime == b.MaxTime &&
+		a.MinLapTime == b.MinLapTime &&
+		a.PreviousLapSplitID == b.PreviousLapSplitID &&
+		a.CutoffTime == b.CutoffTime &&
+		a.CutoffTOD.Equal(b.CutoffTOD)
+}
+
+func wavesEqual(a, b *Wave) bool {
+	return a.Name == b.Name &&
+		a.StartTime.Equal(b.StartTime) &&
+		a.IsLaunched == b.IsLaunched &&
+		a.TriggerChip == b.TriggerChip &&
+		a.TriggerReaderID == b.TriggerReaderID &&
+		a.AutoStart == b.AutoStart
+}
+
+func categoriesEqual(a, b *Category) bool {
+	return a.Name == b.Name &&
+		a.Kind == b.Kind &&
+		a.Gender == b.Gender &&
+		a.AgeFrom == b.AgeFrom &&
+		a.AgeTo == b.AgeTo &&
+		a.DateFrom.Equal(b.DateFrom) &&
+		a.DateTo.Equal(b.DateTo) &&
+		a.Handicap == b.Handicap
+}
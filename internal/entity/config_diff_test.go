@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffRaceConfig(t *testing.T) {
+	raceID, eventID := uuid.New(), uuid.New()
+	reader := &TimeReader{ID: uuid.New(), RaceID: raceID, ReaderName: "finish"}
+	finish := &Split{ID: uuid.New(), Name: "Finish", Type: SplitTypeFinish, TimeReaderID: reader.ID}
+	km5 := &Split{ID: uuid.New(), Name: "5K", Type: SplitTypeStandard, TimeReaderID: reader.ID}
+	wave := &Wave{ID: uuid.New(), Name: "Elite", StartTime: time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)}
+	prev := &RaceModel{
+		Race:        &Race{ID: raceID, Name: "Spring run", Timezone: "UTC"},
+		TimeReaders: []*TimeReader{reader},
+		Events: []*Event{{
+			ID: eventID, Name: "10K", DistanceInMeters: 10000,
+			Splits: []*Split{km5, finish},
+			Waves:  []*Wave{wave},
+		}},
+	}
+
+	movedWave := *wave
+	movedWave.StartTime = wave.StartTime.In(time.FixedZone("CEST", 2*3600))
+	otherWave := &Wave{ID: uuid.New(), Name: "Open"}
+	next := &RaceModel{
+		Race:        prev.Race,
+		TimeReaders: []*TimeReader{reader},
+		Events: []*Event{{
+			ID: eventID, Name: "10K", DistanceInMeters: 10000,
+			Splits: []*Split{finish},
+			Waves:  []*Wave{&movedWave, otherWave},
+		}},
+	}
+	usage := &ConfigUsage{SplitResults: map[uuid.UUID]int{km5.ID: 12}}
+
+	d := DiffRaceConfig(prev, next, usage)
+	assert.Equal(t, []*ConfigChange{
+		{Action: ConfigActionDelete, Kind: ConfigItemSplit, ID: km5.ID, EventID: eventID, Name: "5K", Warning: "split has 12 results, they are removed"},
+		{Action: ConfigActionAdd, Kind: ConfigItemWave, ID: otherWave.ID, EventID: eventID, Name: "Open"},
+	}, d.Changes, "same instant in other timezone is not a change")
+	assert.Len(t, d.Warnings(), 1)
+
+	t.Run("wave with athletes can't be deleted", func(t *testing.T) {
+		next.Events[0].Waves = []*Wave{otherWave}
+		usage.WaveAthletes = map[uuid.UUID]int{wave.ID: 3}
+		d := DiffRaceConfig(prev, next, usage)
+		deleted := d.Deleted(ConfigItemWave)
+		assert.Len(t, deleted, 1)
+		assert.True(t, deleted[0].Blocking)
+	})
+
+	t.Run("previous lap split changed", func(t *testing.T) {
+		lap := *finish
+		lap.PreviousLapSplitID = uuid.NullUUID{UUID: km5.ID, Valid: true}
+		lapNext := &RaceModel{
+			Race:        prev.Race,
+			TimeReaders: prev.TimeReaders,
+			Events: []*Event{{
+				ID: eventID, Name: "10K", DistanceInMeters: 10000,
+				Splits: []*Split{km5, &lap},
+				Waves:  []*Wave{wave},
+			}},
+		}
+		d := DiffRaceConfig(prev, lapNext, usage)
+		if assert.Len(t, d.Changes, 1) {
+			assert.Equal(t, ConfigActionUpdate, d.Changes[0].Action)
+			assert.Equal(t, finish.ID, d.Changes[0].ID)
+		}
+	})
+
+	t.Run("items of deleted event are not listed", func(t *testing.T) {
+		d := DiffRaceConfig(prev, &RaceModel{Race: prev.Race, TimeReaders: prev.TimeReaders}, usage)
+		assert.Len(t, d.Changes, 1)
+		assert.Equal(t, ConfigItemEvent, d.Changes[0].Kind)
+		assert.Equal(t, "event has 3 athletes, they are removed with their results", d.Changes[0].Warning)
+	})
+
+	t.Run("new race is added", func(t *testing.T) {
+		d := DiffRaceConfig(nil, next, nil)
+		assert.Equal(t, ConfigItemRace, d.Changes[0].Kind)
+		assert.Equal(t, ConfigActionAdd, d.Changes[0].Action)
+	})
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReadDecision is the result of checking a read against one of the splits using its reader.
+type ReadDecision struct {
+	SplitID   uuid.UUID  `json:"split_id"`
+	SplitName string     `json:"split_name"`
+	Rule      RecordRule `json:"rule"`
+	// read is the one assigned to the split after calculation
+	Used bool `json:"used"`
+}
+
+type ReadDiagnostic struct {
+	ID           int             `json:"id"`
+	TOD          time.Time       `json:"tod"`
+	ReaderName   string          `json:"reader_name"`
+	TimeReaderID uuid.NullUUID   `json:"time_reader_id"`
+	CanUse       bool            `json:"can_use"`
+	Used         bool            `json:"used"`
+	Note         string          `json:"note,omitempty"`
+	Candidates   []*ReadDecision `json:"candidates"`
+}
+
+type SplitAssignment struct {
+	SplitID   uuid.UUID     `json:"split_id"`
+	SplitName string        `json:"split_name"`
+	SplitType SplitType     `json:"split_type"`
+	TOD       time.Time     `json:"tod"`
+	GunTime   time.Duration `json:"gun_time"`
+	NetTime   time.Duration `json:"net_time"`
+	IsManual  bool          `json:"is_manual"`
+}
+
+// AthleteDiagnostics explains how athlete's reads were assigned to splits by results calculation.
+type AthleteDiagnostics struct {
+	AthleteID uuid.UUID          `json:"athlete_id"`
+	EventID   uuid.UUID          `json:"event_id"`
+	Bib       int                `json:"bib"`
+	Chip      int                `json:"chip"`
+	WaveStart time.Time          `json:"wave_start"`
+	Status    Status             `json:"calculated_status"`
+	Reads     []*ReadDiagnostic  `json:"reads"`
+	Splits    []*SplitAssignment `json:"splits"`
+}
+
+// InLocation converts times of day of diagnostics to race location loc
+func (d *AthleteDiagnostics) InLocation(loc *time.Location) {
+	d.WaveStart = InLocation(d.WaveStart, loc)
+	for _, r := range d.Reads {
+		r.TOD = InLocation(r.TOD, loc)
+	}
+	for _, s := range d.Splits {
+		s.TOD = InLocation(s.TOD, loc)
+	}
+}
@@ -22,64 +22,50 @@ type Event struct {
 	Categories       []*Category `json:"categories"`
 }
 
-func NewEvent(e *dto.EventDTO, ss []*dto.SplitDTO, trs []*dto.TimeReaderDTO, ww []*dto.WaveDTO, cc []*dto.CategoryDTO, v *validator.Validator) *Event {
-	v.Check(e.DistanceInMeters > 0, "event distance", "must be greater than 0")
-	eventDate, _ := time.Parse(time.RFC3339, e.EventDate)
+// NewEvent builds event of race in loc from its config. Every split, wave and category is checked even if
+// some of them are invalid, then they are checked against each other, so all problems are reported at once.
+// Errors of items are reported at their index in config, e.g. splits[1].min_time_sec.
+func NewEvent(e *dto.EventDTO, ss []*dto.SplitDTO, trs []*dto.TimeReaderDTO, ww []*dto.WaveDTO, cc []*dto.CategoryDTO, loc *time.Location, v *validator.Validator) *Event {
+	v.CheckField(e.DistanceInMeters > 0, "distance_in_meters", validator.CodeOutOfRange, "must be greater than 0")
+	eventDate, _ := ParseWallClock(e.EventDate, loc)
 
-	// Splits
-	v.Check(len(ss) != 0, "splits", "event must have at least one split")
-	if !v.Valid() {
-		return nil
-	}
-	splits := make([]*Split, 0, len(ss))
-	for _, s := range ss {
-		spl := NewSplit(s, trs, v)
-		if !v.Valid() {
-			return nil
-		}
-		splits = append(splits, spl)
+	// Splits. Invalid items are kept as nil, so cross checks report errors at index of split in config
+	v.CheckField(len(ss) != 0, "splits", validator.CodeRequired, "event must have at least one split")
+	splits := make([]*Split, len(ss))
+	for i, s := range ss {
+		splits[i] = newItem(v.Item("splits", i), func(iv *validator.Validator) *Split { return NewSplit(s, trs, loc, iv) })
 	}
-	var splitsNames []string
+	checkUniqueNames(v, "splits", "split_name", splits, func(s *Split) string { return s.Name })
 	splitTypeQty := make(map[SplitType]int)
 	for _, split := range splits {
-		splitsNames = append(splitsNames, split.Name)
-		splitTypeQty[split.Type]++
+		if split != nil {
+			splitTypeQty[split.Type]++
+		}
 	}
-	v.Check(validator.Unique(splitsNames), "splits", "must have unique names for event")
-	v.Check(splitTypeQty[SplitTypeStart] < 2, "split with type start", "must be 0 or 1")
-	v.Check(splitTypeQty[SplitTypeFinish] == 1, "split with type finish", "must be only 1")
+	v.CheckField(splitTypeQty[SplitTypeStart] < 2, "splits", validator.CodeNotUnique, "split with type start must be 0 or 1")
+	v.CheckField(splitTypeQty[SplitTypeFinish] < 2, "splits", validator.CodeNotUnique, "split with type finish must be only 1")
+	v.CheckField(splitTypeQty[SplitTypeFinish] > 0, "splits", validator.CodeRequired, "event must have split with type finish")
+	CheckSplitDistances(splits, e.DistanceInMeters, v)
+	CheckLapSplits(splits, v)
 
 	// Waves
-	v.Check(len(ww) > 0, "waves", "must be at least one for event")
-	if !v.Valid() {
-		return nil
+	v.CheckField(len(ww) > 0, "waves", validator.CodeRequired, "must be at least one for event")
+	waves := make([]*Wave, len(ww))
+	for i, w := range ww {
+		waves[i] = newItem(v.Item("waves", i), func(iv *validator.Validator) *Wave { return NewWave(w, trs, loc, iv) })
 	}
-	waves := make([]*Wave, 0, len(ww))
-	var wavesNames []string
-	for _, w := range ww {
-		wave := NewWave(w, v)
-		if !v.Valid() {
-			return nil
-		}
-		wavesNames = append(wavesNames, w.Name)
-		waves = append(waves, wave)
-	}
-	v.Check(validator.Unique(wavesNames), "waves", "must have unique names for event")
+	checkUniqueNames(v, "waves", "wave_name", waves, func(w *Wave) string { return w.Name })
+	CheckWaveDates(waves, eventDate, loc, v)
 
 	// Categories. Event may have no categories at all
-	categories := make([]*Category, 0, len(cc))
-	if len(cc) > 0 {
-		var categoryNames []string
-		for _, c := range cc {
-			cat := NewCategory(c, eventDate, v)
-			if !v.Valid() {
-				return nil
-			}
-			categoryNames = append(categoryNames, c.Name)
-			categories = append(categories, cat)
-		}
-		v.Check(validator.Unique(categoryNames), "categories", "must have unique names for event")
-		CheckCategoriesBoundary(categories, v)
+	categories := make([]*Category, len(cc))
+	for i, c := range cc {
+		categories[i] = newItem(v.Item("categories", i), func(iv *validator.Validator) *Category { return NewCategory(c, eventDate, iv) })
+	}
+	checkUniqueNames(v, "categories", "category_name", categories, func(c *Category) string { return c.Name })
+	CheckCategoriesBoundary(categories, v)
+	if !v.Valid() {
+		return nil
 	}
 
 	event := &Event{
@@ -97,6 +83,123 @@ func NewEvent(e *dto.EventDTO, ss []*dto.SplitDTO, trs []*dto.TimeReaderDTO, ww
 	return event
 }
 
+// newItem builds split, wave or category with its own validator, so the item is checked regardless of errors
+// of previous items. Errors are added to v, nil is returned for invalid item.
+func newItem[T any](v *validator.Validator, build func(iv *validator.Validator) *T) *T {
+	iv := validator.New()
+	item := build(iv)
+	v.Merge(iv)
+	return item
+}
+
+// checkUniqueNames reports items of list having the name of previous item. Invalid items are nil and skipped.
+func checkUniqueNames[T any](v *validator.Validator, list, key string, items []*T, name func(*T) string) {
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+		n := name(item)
+		v.Item(list, i).CheckField(!seen[n], key, validator.CodeNotUnique, "must be unique for event")
+		seen[n] = true
+	}
+}
+
+// CheckSplitDistances checks that distances of splits strictly increase along the course, start split is
+// the first one and finish split is at the distance of event. Nil splits are skipped.
+func CheckSplitDistances(splits []*Split, distance int, v *validator.Validator) {
+	var order []int
+	for i, s := range splits {
+		if s != nil {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(splits[a].DistanceFromStart, splits[b].DistanceFromStart)
+	})
+	for k := 1; k < len(order); k++ {
+		prev, s := splits[order[k-1]], splits[order[k]]
+		if s.DistanceFromStart == prev.DistanceFromStart {
+			v.Item("splits", order[k]).AddFieldError("distance_from_start", validator.CodeNotUnique, fmt.Sprintf("must differ from distance of split %q", prev.Name))
+		}
+	}
+	for k, i := range order {
+		s, sv := splits[i], v.Item("splits", i)
+		switch s.Type {
+		case SplitTypeStart:
+			sv.CheckField(k == 0, "distance_from_start", validator.CodeOutOfRange, "start split must be before other splits")
+		case SplitTypeFinish:
+			sv.CheckField(s.DistanceFromStart == distance, "distance_from_start", validator.CodeOutOfRange, fmt.Sprintf("finish split must be at event distance %d", distance))
+		default:
+			sv.CheckField(s.DistanceFromStart < distance, "distance_from_start", validator.CodeOutOfRange, fmt.Sprintf("must be before event distance %d", distance))
+		}
+	}
+}
+
+// CheckLapSplits checks that splits sharing time reader have min lap time, otherwise a single pass
+// by the reader would be taken for all of them. Nil splits are skipped.
+func CheckLapSplits(splits []*Split, v *validator.Validator) {
+	byReader := make(map[ReaderID]int, len(splits))
+	for _, s := range splits {
+		if s != nil {
+			byReader[s.TimeReaderID]++
+		}
+	}
+	for i, s := range splits {
+		if s != nil && byReader[s.TimeReaderID] > 1 {
+			v.Item("splits", i).CheckField(s.MinLapTime > 0, "min_lap_time_sec", validator.CodeRequired, "must be greater than 0 for splits sharing time reader")
+		}
+	}
+}
+
+// CheckWaveDates checks that waves start on the date of event in race location loc. Nil waves are skipped.
+func CheckWaveDates(waves []*Wave, eventDate time.Time, loc *time.Location, v *validator.Validator) {
+	for i, w := range waves {
+		if w == nil {
+			continue
+		}
+		y, m, d := w.StartTime.In(loc).Date()
+		ey, em, ed := eventDate.Date()
+		v.Item("waves", i).CheckField(y == ey && m == em && d == ed, "wave_start_time", validator.CodeOutOfRange, "must be on event date "+eventDate.Format(time.DateOnly))
+	}
+}
+
+// DTO returns event with its splits, waves and categories in the form they are configured in
+func (e *Event) DTO() *dto.EventModelDTO {
+	em := &dto.EventModelDTO{
+		EventDTO: &dto.EventDTO{
+			ID:               e.ID,
+			RaceID:           e.RaceID,
+			Name:             e.Name,
+			DistanceInMeters: e.DistanceInMeters,
+			EventDate:        e.EventDate.Format(time.RFC3339),
+		},
+		Splits:     make([]*dto.SplitDTO, 0, len(e.Splits)),
+		Waves:      make([]*dto.WaveDTO, 0, len(e.Waves)),
+		Categories: make([]*dto.CategoryDTO, 0, len(e.Categories)),
+	}
+	for _, s := range e.Splits {
+		em.Splits = append(em.Splits, s.DTO())
+	}
+	for _, w := range e.Waves {
+		em.Waves = append(em.Waves, w.DTO())
+	}
+	for _, c := range e.Categories {
+		em.Categories = append(em.Categories, c.DTO())
+	}
+	return em
+}
+
+// InLocation converts wave starts and split cutoffs of the event to loc
+func (e *Event) InLocation(loc *time.Location) {
+	for _, w := range e.Waves {
+		w.StartTime = InLocation(w.StartTime, loc)
+	}
+	for _, s := range e.Splits {
+		s.CutoffTOD = InLocation(s.CutoffTOD, loc)
+	}
+}
+
 type (
 	SplitID  = uuid.UUID
 	ReaderID = uuid.UUID
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventDTO(t *testing.T) {
+	raceID, eventID := uuid.New(), uuid.New()
+	readers := []*dto.TimeReaderDTO{{ID: uuid.New(), RaceID: raceID, ReaderName: "finish"}}
+	ed := &dto.EventDTO{ID: eventID, RaceID: raceID, Name: "10K", DistanceInMeters: 10000, EventDate: "2025-05-20T00:00:00Z"}
+	splits := []*dto.SplitDTO{{
+		ID: uuid.New(), RaceID: raceID, EventID: eventID, Name: "Finish", Type: "finish", DistanceFromStart: 10000,
+		TimeReaderID: readers[0].ID, MinTime: "30m", MaxTime: "3h", MinLapTime: "0s", CutoffTOD: "2025-05-20T12:00:00+02:00",
+	}}
+	waves := []*dto.WaveDTO{{
+		ID: uuid.New(), RaceID: raceID, EventID: eventID, Name: "Elite", StartTime: "2025-05-20T09:00:00.5+02:00", IsLaunched: true,
+	}}
+	cats := []*dto.CategoryDTO{{
+		ID: uuid.New(), RaceID: raceID, EventID: eventID, Name: "M18", Kind: "age", Gender: "male", AgeFrom: 18, AgeTo: 39,
+	}}
+
+	v := validator.New()
+	e := NewEvent(ed, splits, readers, waves, cats, time.UTC, v)
+	assert.True(t, v.Valid())
+
+	em := e.DTO()
+	got := NewEvent(em.EventDTO, em.Splits, readers, em.Waves, em.Categories, time.UTC, v)
+	assert.True(t, v.Valid())
+	assert.Equal(t, e.Name, got.Name)
+	assert.True(t, e.EventDate.Equal(got.EventDate))
+	assert.True(t, e.Waves[0].StartTime.Equal(got.Waves[0].StartTime))
+	assert.Equal(t, 500*time.Millisecond, time.Duration(got.Waves[0].StartTime.Nanosecond()))
+	assert.True(t, e.Splits[0].CutoffTOD.Equal(got.Splits[0].CutoffTOD))
+	assert.Equal(t, e.Splits[0].MaxTime, got.Splits[0].MaxTime)
+	assert.Equal(t, e.Categories, got.Categories)
+}
+
+func TestNewEventChecksSplitsWavesAndCategoriesTogether(t *testing.T) {
+	raceID, eventID := uuid.New(), uuid.New()
+	loc, _ := time.LoadLocation("Europe/Moscow")
+	start := &dto.TimeReaderDTO{ID: uuid.New(), RaceID: raceID, ReaderName: "start"}
+	finish := &dto.TimeReaderDTO{ID: uuid.New(), RaceID: raceID, ReaderName: "finish"}
+	readers := []*dto.TimeReaderDTO{start, finish}
+	ed := &dto.EventDTO{ID: eventID, RaceID: raceID, Name: "Marathon", DistanceInMeters: 42195, EventDate: "2025-04-15T00:00:00Z"}
+	split := func(name, tp string, distance int, reader uuid.UUID, minTime, maxTime, minLapTime string) *dto.SplitDTO {
+		return &dto.SplitDTO{
+			ID: uuid.New(), RaceID: raceID, EventID: eventID, Name: name, Type: tp, DistanceFromStart: distance,
+			TimeReaderID: reader, MinTime: minTime, MaxTime: maxTime, MinLapTime: minLapTime,
+		}
+	}
+	splits := []*dto.SplitDTO{
+		split("Start", "start", 0, start.ID, "0s", "0s", "0s"),
+		split("21 km", "standard", 21000, finish.ID, "0s", "0s", "0s"),
+		split("Half", "standard", 21000, start.ID, "0s", "0s", "0s"),
+		split("30 km", "standard", 30000, start.ID, "3h", "2h", "10m"),
+		split("Finish", "finish", 42190, finish.ID, "2h", "6h", "0s"),
+	}
+	wave := func(name, startTime string) *dto.WaveDTO {
+		return &dto.WaveDTO{ID: uuid.New(), RaceID: raceID, EventID: eventID, Name: name, StartTime: startTime}
+	}
+	waves := []*dto.WaveDTO{
+		wave("Elite", "2025-04-15T09:00:00+03:00"),
+		// wall clock time of the race sent with Z is 02:30 of 15 April in Moscow
+		wave("Night", "2025-04-15T02:30:00Z"),
+		wave("Late", "2025-04-16T09:00:00+03:00"),
+	}
+	category := func(name string, from, to int) *dto.CategoryDTO {
+		return &dto.CategoryDTO{ID: uuid.New(), RaceID: raceID, EventID: eventID, Name: name, Kind: "age", Gender: "male", AgeFrom: from, AgeTo: to}
+	}
+	cats := []*dto.CategoryDTO{category("M18", 18, 39), category("M45", 45, 59), category("M55", 55, 99)}
+
+	v := validator.New()
+	assert.Nil(t, NewEvent(ed, splits, readers, waves, cats, loc, v))
+	assert.Contains(t, v.Errors, "splits[2].distance_from_start", "distances must increase")
+	assert.Contains(t, v.Errors, "splits[4].distance_from_start", "finish must be at event distance")
+	assert.Contains(t, v.Errors, "splits[3].min_time_sec", "min time above max time")
+	assert.Contains(t, v.Errors, "splits[0].min_lap_time_sec", "start reader is used twice")
+	assert.Contains(t, v.Errors, "splits[4].min_lap_time_sec", "finish reader is used twice")
+	assert.Contains(t, v.Errors, "waves[2].wave_start_time")
+	assert.NotContains(t, v.Errors, "waves[0].wave_start_time")
+	assert.NotContains(t, v.Errors, "waves[1].wave_start_time", "start is on event date in race timezone")
+	assert.Contains(t, v.FieldErrors(), validator.FieldError{
+		Path: "categories[1].age_from", Code: validator.CodeGap, Message: `not consequent dates, ages 40 to 44 after category "M18" are not covered`,
+	})
+	assert.Contains(t, v.FieldErrors(), validator.FieldError{
+		Path: "categories[2].age_from", Code: validator.CodeOverlap, Message: `not consequent dates, age range overlaps category "M45"`,
+	})
+}
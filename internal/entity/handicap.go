@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// HandicapBasis is the time handicap results are ranked on
+type HandicapBasis string
+
+const (
+	// HandicapBasisHandicap ranks by time from the wave start, that is by the order athletes cross the line
+	HandicapBasisHandicap HandicapBasis = "handicap"
+	// HandicapBasisScratch ranks by time actually spent on course from athlete's own start
+	HandicapBasisScratch HandicapBasis = "scratch"
+)
+
+func IsValidHandicapBasis(b HandicapBasis) bool {
+	return b == HandicapBasisHandicap || b == HandicapBasisScratch
+}
+
+// HandicapResult is finish result of athlete started with handicap offset after the wave start.
+// Gun and net times of such athlete are counted from athlete's own start.
+type HandicapResult struct {
+	*FinishResult
+	HandicapTime time.Duration `json:"handicap_time"`
+	ScratchTime  time.Duration `json:"scratch_time"`
+	Rank         int           `json:"rank"`
+}
+
+func NewHandicapResult(f *FinishResult) *HandicapResult {
+	return &HandicapResult{
+		FinishResult: f,
+		HandicapTime: f.GunTime + f.Handicap,
+		ScratchTime:  f.NetTime,
+	}
+}
+
+// RankHandicap sorts results and assigns ranks on the given basis, equal times share the rank
+func RankHandicap(results []*HandicapResult, basis HandicapBasis) {
+	timeOf := func(r *HandicapResult) time.Duration {
+		if basis == HandicapBasisScratch {
+			return r.ScratchTime
+		}
+		return r.HandicapTime
+	}
+	slices.SortStableFunc(results, func(a, b *HandicapResult) int {
+		return cmp.Compare(timeOf(a), timeOf(b))
+	})
+	for i, r := range results {
+		if i > 0 && timeOf(results[i-1]) == timeOf(r) {
+			r.Rank = results[i-1].Rank
+			continue
+		}
+		r.Rank = i + 1
+	}
+}
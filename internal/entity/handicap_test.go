@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankHandicap(t *testing.T) {
+	// slow runner starts with the wave, fast runners start later and have to catch up
+	slow := &FinishResult{Bib: 1, GunTime: 50 * time.Minute, NetTime: 50 * time.Minute}
+	fast := &FinishResult{Bib: 2, GunTime: 42 * time.Minute, NetTime: 42 * time.Minute, Handicap: 10 * time.Minute}
+	mid := &FinishResult{Bib: 3, GunTime: 45 * time.Minute, NetTime: 45 * time.Minute, Handicap: 5 * time.Minute}
+	newResults := func() []*HandicapResult {
+		return []*HandicapResult{NewHandicapResult(slow), NewHandicapResult(fast), NewHandicapResult(mid)}
+	}
+	bibs := func(rr []*HandicapResult) []int {
+		var res []int
+		for _, r := range rr {
+			res = append(res, r.Bib)
+		}
+		return res
+	}
+
+	t.Run("handicap basis ranks by crossing order", func(t *testing.T) {
+		res := newResults()
+		RankHandicap(res, HandicapBasisHandicap)
+		assert.Equal(t, []int{1, 3, 2}, bibs(res))
+		assert.Equal(t, 50*time.Minute, res[0].HandicapTime)
+		assert.Equal(t, 50*time.Minute, res[1].HandicapTime)
+		assert.Equal(t, 1, res[1].Rank, "equal times share the rank")
+		assert.Equal(t, 3, res[2].Rank)
+	})
+
+	t.Run("scratch basis ranks by own time", func(t *testing.T) {
+		res := newResults()
+		RankHandicap(res, HandicapBasisScratch)
+		assert.Equal(t, []int{2, 3, 1}, bibs(res))
+		assert.Equal(t, 42*time.Minute, res[0].ScratchTime)
+		assert.Equal(t, []int{1, 2, 3}, []int{res[0].Rank, res[1].Rank, res[2].Rank})
+	})
+}
@@ -0,0 +1,187 @@
+package entity
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OverdueBasis string
+
+const (
+	OverdueBasisMaxTime OverdueBasis = "max_time"
+	OverdueBasisMedian  OverdueBasis = "field_median"
+	OverdueBasisPace    OverdueBasis = "athlete_pace"
+)
+
+type OverdueAthlete struct {
+	AthleteID     uuid.UUID     `json:"athlete_id"`
+	EventID       uuid.UUID     `json:"event_id"`
+	Bib           int           `json:"bib"`
+	FirstName     string        `json:"first_name"`
+	LastName      string        `json:"last_name"`
+	Phone         string        `json:"phone"`
+	LastSplitID   uuid.UUID     `json:"last_split_id"`
+	LastSplitName string        `json:"last_split_name"`
+	LastTOD       time.Time     `json:"last_tod"`
+	NextSplitID   uuid.UUID     `json:"next_split_id"`
+	NextSplitName string        `json:"next_split_name"`
+	ExpectedBy    time.Time     `json:"expected_by"`
+	OverdueBy     time.Duration `json:"overdue_by"`
+	Basis         OverdueBasis  `json:"basis"`
+}
+
+type OverdueReport struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Tolerance   float64           `json:"tolerance"`
+	Athletes    []*OverdueAthlete `json:"athletes"`
+}
+
+type segmentKey struct {
+	from, to uuid.UUID
+}
+
+// FindOverdue returns running athletes who have not reached the split following the last one they were seen at in time.
+// Expected arrival is taken from split max time if configured, otherwise from the median duration of the segment
+// among athletes who already passed it multiplied by tolerance, otherwise from athlete's own pace.
+// Field holds progress of all athletes of the event used for medians, running athletes included.
+func FindOverdue(running, field []*AthleteProgress, splits []*Split, now time.Time, tolerance float64) []*OverdueAthlete {
+	ordered := slices.Clone(splits)
+	slices.SortFunc(ordered, func(a, b *Split) int {
+		return cmp.Compare(a.DistanceFromStart, b.DistanceFromStart)
+	})
+	splitsMap := make(map[uuid.UUID]*Split, len(ordered))
+	for _, s := range ordered {
+		splitsMap[s.ID] = s
+	}
+	medians := segmentMedians(field, ordered, splitsMap)
+
+	var res []*OverdueAthlete
+	for _, p := range running {
+		last, lastTOD := lastVisitedSplit(p, splitsMap)
+		if last == nil {
+			continue
+		}
+		next := nextSplit(ordered, last)
+		if next == nil {
+			continue
+		}
+
+		var expectedBy time.Time
+		var basis OverdueBasis
+		if next.MaxTime != 0 {
+			expectedBy = p.WaveStart.Add(next.MaxTime)
+			basis = OverdueBasisMaxTime
+		} else if m, ok := medians[segmentKey{last.ID, next.ID}]; ok {
+			expectedBy = lastTOD.Add(time.Duration(float64(m) * tolerance))
+			basis = OverdueBasisMedian
+		} else if pr, ok := PredictArrivals(p, splits); ok {
+			idx := slices.IndexFunc(pr.Splits, func(sp *SplitPrediction) bool {
+				return sp.SplitID == next.ID
+			})
+			if idx == -1 {
+				continue
+			}
+			expected := pr.Splits[idx].ExpectedTOD
+			expectedBy = lastTOD.Add(time.Duration(float64(expected.Sub(lastTOD)) * tolerance))
+			basis = OverdueBasisPace
+		} else {
+			continue
+		}
+		if !now.After(expectedBy) {
+			continue
+		}
+		res = append(res, &OverdueAthlete{
+			AthleteID:     p.AthleteID,
+			EventID:       p.EventID,
+			Bib:           p.Bib,
+			FirstName:     p.FirstName,
+			LastName:      p.LastName,
+			Phone:         p.Phone,
+			LastSplitID:   last.ID,
+			LastSplitName: last.Name,
+			LastTOD:       lastTOD,
+			NextSplitID:   next.ID,
+			NextSplitName: next.Name,
+			ExpectedBy:    expectedBy,
+			OverdueBy:     now.Sub(expectedBy).Round(time.Second),
+			Basis:         basis,
+		})
+	}
+	return res
+}
+
+func lastVisitedSplit(p *AthleteProgress, splitsMap map[uuid.UUID]*Split) (*Split, time.Time) {
+	var last *Split
+	var lastTOD time.Time
+	for _, v := range p.Visited {
+		s, ok := splitsMap[v.SplitID]
+		if !ok || !v.IsVisited() {
+			continue
+		}
+		if last == nil || s.DistanceFromStart > last.DistanceFromStart {
+			last = s
+			lastTOD = v.TOD
+		}
+	}
+	return last, lastTOD
+}
+
+// nextSplit returns the closest split after s, ordered must be sorted by distance
+func nextSplit(ordered []*Split, s *Split) *Split {
+	for _, n := range ordered {
+		if n.DistanceFromStart > s.DistanceFromStart && n.Type != SplitTypeStart {
+			return n
+		}
+	}
+	return nil
+}
+
+func segmentMedians(field []*AthleteProgress, ordered []*Split, splitsMap map[uuid.UUID]*Split) map[segmentKey]time.Duration {
+	durations := make(map[segmentKey][]time.Duration)
+	for _, p := range field {
+		visited := make(map[uuid.UUID]time.Time, len(p.Visited))
+		for _, v := range p.Visited {
+			if _, ok := splitsMap[v.SplitID]; ok && v.IsVisited() {
+				visited[v.SplitID] = v.TOD
+			}
+		}
+		for _, s := range ordered {
+			from, ok := visited[s.ID]
+			if !ok {
+				continue
+			}
+			next := nextSplit(ordered, s)
+			if next == nil {
+				continue
+			}
+			to, ok := visited[next.ID]
+			if !ok || !to.After(from) {
+				continue
+			}
+			key := segmentKey{s.ID, next.ID}
+			durations[key] = append(durations[key], to.Sub(from))
+		}
+	}
+	medians := make(map[segmentKey]time.Duration, len(durations))
+	for k, dd := range durations {
+		slices.Sort(dd)
+		if len(dd)%2 == 1 {
+			medians[k] = dd[len(dd)/2]
+		} else {
+			medians[k] = (dd[len(dd)/2-1] + dd[len(dd)/2]) / 2
+		}
+	}
+	return medians
+}
+
+// InLocation converts times of day of report to race location loc
+func (r *OverdueReport) InLocation(loc *time.Location) {
+	r.GeneratedAt = InLocation(r.GeneratedAt, loc)
+	for _, a := range r.Athletes {
+		a.LastTOD = InLocation(a.LastTOD, loc)
+		a.ExpectedBy = InLocation(a.ExpectedBy, loc)
+	}
+}
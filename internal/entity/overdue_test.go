@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindOverdue(t *testing.T) {
+	waveStart := time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)
+	start := &Split{ID: uuid.New(), Name: "start", Type: SplitTypeStart}
+	km5 := &Split{ID: uuid.New(), Name: "5km", Type: SplitTypeStandard, DistanceFromStart: 5000}
+	km10 := &Split{ID: uuid.New(), Name: "10km", Type: SplitTypeStandard, DistanceFromStart: 10000}
+	finish := &Split{ID: uuid.New(), Name: "finish", Type: SplitTypeFinish, DistanceFromStart: 15000, MaxTime: 2 * time.Hour}
+	splits := []*Split{finish, km10, start, km5}
+
+	progress := func(visited map[*Split]time.Duration) *AthleteProgress {
+		p := &AthleteProgress{AthleteID: uuid.New(), WaveStart: waveStart}
+		for s, d := range visited {
+			p.Visited = append(p.Visited, &AthleteSplit{SplitID: s.ID, TOD: waveStart.Add(d)})
+		}
+		return p
+	}
+	// field median for 5km -> 10km is 30 minutes
+	field := []*AthleteProgress{
+		progress(map[*Split]time.Duration{km5: 25 * time.Minute, km10: 50 * time.Minute}),
+		progress(map[*Split]time.Duration{km5: 30 * time.Minute, km10: 60 * time.Minute}),
+		progress(map[*Split]time.Duration{km5: 35 * time.Minute, km10: 70 * time.Minute}),
+	}
+	atKm5 := progress(map[*Split]time.Duration{start: 0, km5: 30 * time.Minute})
+	atKm10 := progress(map[*Split]time.Duration{start: 0, km5: 30 * time.Minute, km10: 60 * time.Minute})
+	running := []*AthleteProgress{atKm5, atKm10}
+
+	t.Run("nobody overdue", func(t *testing.T) {
+		res := FindOverdue(running, append(field, running...), splits, waveStart.Add(70*time.Minute), 1.5)
+		assert.Empty(t, res)
+	})
+
+	t.Run("overdue by field median and max time", func(t *testing.T) {
+		now := waveStart.Add(2*time.Hour + 10*time.Minute)
+		res := FindOverdue(running, append(field, running...), splits, now, 1.5)
+		assert.Len(t, res, 2)
+
+		assert.Equal(t, atKm5.AthleteID, res[0].AthleteID)
+		assert.Equal(t, OverdueBasisMedian, res[0].Basis)
+		assert.Equal(t, "10km", res[0].NextSplitName)
+		assert.Equal(t, waveStart.Add(75*time.Minute), res[0].ExpectedBy)
+
+		assert.Equal(t, atKm10.AthleteID, res[1].AthleteID)
+		assert.Equal(t, OverdueBasisMaxTime, res[1].Basis)
+		assert.Equal(t, 10*time.Minute, res[1].OverdueBy)
+	})
+}
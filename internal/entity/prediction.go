@@ -0,0 +1,195 @@
+package entity
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AthleteProgress holds splits visited so far by an athlete of the event.
+type AthleteProgress struct {
+	AthleteID uuid.UUID
+	EventID   uuid.UUID
+	Bib       int
+	FirstName string
+	LastName  string
+	Phone     string
+	Status    Status
+	WaveStart time.Time
+	Visited   []*AthleteSplit
+}
+
+type SplitPrediction struct {
+	SplitID     uuid.UUID `json:"split_id"`
+	SplitName   string    `json:"split_name"`
+	SplitType   SplitType `json:"split_type"`
+	ExpectedTOD time.Time `json:"expected_tod"`
+	EarliestTOD time.Time `json:"earliest_tod"`
+	LatestTOD   time.Time `json:"latest_tod"`
+}
+
+type AthletePrediction struct {
+	AthleteID     uuid.UUID          `json:"athlete_id"`
+	EventID       uuid.UUID          `json:"event_id"`
+	Bib           int                `json:"bib"`
+	FirstName     string             `json:"first_name"`
+	LastName      string             `json:"last_name"`
+	LastSplitID   uuid.UUID          `json:"last_split_id"`
+	LastSplitName string             `json:"last_split_name"`
+	LastTOD       time.Time          `json:"last_tod"`
+	PacePerKm     time.Duration      `json:"pace_per_km"`
+	Splits        []*SplitPrediction `json:"splits"`
+}
+
+const (
+	// spread of prediction when pace variation can't be measured
+	defaultPredictionSpread = 0.1
+	minPredictionSpread     = 0.03
+	maxPredictionSpread     = 0.25
+)
+
+type progressPoint struct {
+	split    *Split
+	distance int
+	tod      time.Time
+}
+
+// PredictArrivals projects arrival times at the splits not yet visited by athlete from the average pace so far.
+// Confidence range is based on the variation of pace between visited splits.
+// Predictions are kept within min and max time of the split.
+// Returns false when pace can't be calculated, e.g. athlete has been seen at start only.
+func PredictArrivals(p *AthleteProgress, splits []*Split) (*AthletePrediction, bool) {
+	splitsMap := make(map[uuid.UUID]*Split, len(splits))
+	for _, s := range splits {
+		splitsMap[s.ID] = s
+	}
+
+	origin := progressPoint{tod: p.WaveStart}
+	var points []progressPoint
+	for _, v := range p.Visited {
+		s, ok := splitsMap[v.SplitID]
+		if !ok || !v.IsVisited() {
+			continue
+		}
+		if s.Type == SplitTypeStart {
+			origin = progressPoint{split: s, distance: s.DistanceFromStart, tod: v.TOD}
+			continue
+		}
+		points = append(points, progressPoint{split: s, distance: s.DistanceFromStart, tod: v.TOD})
+	}
+	if len(points) == 0 {
+		return nil, false
+	}
+	slices.SortFunc(points, func(a, b progressPoint) int {
+		return cmp.Or(cmp.Compare(a.distance, b.distance), a.tod.Compare(b.tod))
+	})
+	last := points[len(points)-1]
+	if last.distance <= origin.distance || !last.tod.After(origin.tod) {
+		return nil, false
+	}
+	// pace in seconds per meter
+	pace := last.tod.Sub(origin.tod).Seconds() / float64(last.distance-origin.distance)
+
+	var segmentPaces []float64
+	prev := origin
+	for _, pt := range points {
+		if pt.distance > prev.distance && pt.tod.After(prev.tod) {
+			segmentPaces = append(segmentPaces, pt.tod.Sub(prev.tod).Seconds()/float64(pt.distance-prev.distance))
+		}
+		prev = pt
+	}
+	spread := paceSpread(segmentPaces, pace)
+
+	res := &AthletePrediction{
+		AthleteID:     p.AthleteID,
+		EventID:       p.EventID,
+		Bib:           p.Bib,
+		FirstName:     p.FirstName,
+		LastName:      p.LastName,
+		LastSplitID:   last.split.ID,
+		LastSplitName: last.split.Name,
+		LastTOD:       last.tod,
+		PacePerKm:     time.Duration(pace * 1000 * float64(time.Second)).Round(time.Second),
+		Splits:        []*SplitPrediction{},
+	}
+	for _, s := range splits {
+		if s.DistanceFromStart <= last.distance || s.Type == SplitTypeStart {
+			continue
+		}
+		remaining := time.Duration(pace * float64(s.DistanceFromStart-last.distance) * float64(time.Second))
+		res.Splits = append(res.Splits, &SplitPrediction{
+			SplitID:     s.ID,
+			SplitName:   s.Name,
+			SplitType:   s.Type,
+			ExpectedTOD: s.clampTOD(p.WaveStart, last.tod.Add(remaining)).Round(time.Second),
+			EarliestTOD: s.clampTOD(p.WaveStart, last.tod.Add(time.Duration(float64(remaining)*(1-spread)))).Round(time.Second),
+			LatestTOD:   s.clampTOD(p.WaveStart, last.tod.Add(time.Duration(float64(remaining)*(1+spread)))).Round(time.Second),
+		})
+	}
+	slices.SortFunc(res.Splits, func(a, b *SplitPrediction) int {
+		return a.ExpectedTOD.Compare(b.ExpectedTOD)
+	})
+	return res, true
+}
+
+// clampTOD moves tod into min and max time of the split since wave start, reads outside of them
+// are not taken as the split result anyway
+func (s *Split) clampTOD(waveStart, tod time.Time) time.Time {
+	if earliest := waveStart.Add(s.MinTime); tod.Before(earliest) {
+		return earliest
+	}
+	if latest := waveStart.Add(s.MaxTime); s.MaxTime != 0 && tod.After(latest) {
+		return latest
+	}
+	return tod
+}
+
+// paceSpread returns relative standard deviation of segment paces
+func paceSpread(paces []float64, mean float64) float64 {
+	if len(paces) < 2 || mean <= 0 {
+		return defaultPredictionSpread
+	}
+	var sum float64
+	for _, p := range paces {
+		sum += (p - mean) * (p - mean)
+	}
+	spread := math.Sqrt(sum/float64(len(paces)-1)) / mean
+	return min(max(spread, minPredictionSpread), maxPredictionSpread)
+}
+
+// ExpectedAt returns prediction for split if athlete may arrive there between from and to.
+func (ap *AthletePrediction) ExpectedAt(splitID uuid.UUID, from, to time.Time) (*SplitPrediction, bool) {
+	for _, s := range ap.Splits {
+		if s.SplitID != splitID {
+			continue
+		}
+		if s.EarliestTOD.After(to) || s.LatestTOD.Before(from) {
+			return nil, false
+		}
+		return s, true
+	}
+	return nil, false
+}
+
+type ExpectedArrival struct {
+	AthleteID     uuid.UUID `json:"athlete_id"`
+	Bib           int       `json:"bib"`
+	FirstName     string    `json:"first_name"`
+	LastName      string    `json:"last_name"`
+	LastSplitName string    `json:"last_split_name"`
+	LastTOD       time.Time `json:"last_tod"`
+	*SplitPrediction
+}
+
+// InLocation converts times of day of prediction to race location loc
+func (p *AthletePrediction) InLocation(loc *time.Location) {
+	p.LastTOD = InLocation(p.LastTOD, loc)
+	for _, s := range p.Splits {
+		s.ExpectedTOD = InLocation(s.ExpectedTOD, loc)
+		s.EarliestTOD = InLocation(s.EarliestTOD, loc)
+		s.LatestTOD = InLocation(s.LatestTOD, loc)
+	}
+}
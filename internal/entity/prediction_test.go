@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredictArrivals(t *testing.T) {
+	waveStart := time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)
+	reader := uuid.New()
+	split := func(name string, tp SplitType, distance int, minTime, maxTime time.Duration) *Split {
+		return &Split{ID: uuid.New(), Name: name, Type: tp, DistanceFromStart: distance, TimeReaderID: reader, MinTime: minTime, MaxTime: maxTime}
+	}
+	start := split("start", SplitTypeStart, 0, 0, 0)
+	km5 := split("5km", SplitTypeStandard, 5000, 0, 0)
+	km10 := split("10km", SplitTypeStandard, 10000, 0, 0)
+	finish := split("finish", SplitTypeFinish, 15000, 0, 0)
+	lap1 := split("lap 1", SplitTypeStandard, 5000, 0, 0)
+	lap2 := split("lap 2", SplitTypeStandard, 10000, 0, 0)
+	lapFinish := split("finish", SplitTypeFinish, 15000, 0, 0)
+	fastKm10 := split("10km", SplitTypeStandard, 10000, 40*time.Minute, 0)
+	slowFinish := split("finish", SplitTypeFinish, 15000, 0, 80*time.Minute)
+
+	at := func(d time.Duration) time.Time { return waveStart.Add(d) }
+	type prediction struct {
+		split                      *Split
+		expected, earliest, latest time.Duration
+	}
+	tests := []struct {
+		name     string
+		splits   []*Split
+		visited  map[*Split]time.Duration
+		ok       bool
+		last     *Split
+		pace     time.Duration
+		expected []prediction
+	}{
+		{
+			name:    "no splits passed",
+			splits:  []*Split{start, km5, km10, finish},
+			visited: map[*Split]time.Duration{},
+		},
+		{
+			name:    "seen at start only",
+			splits:  []*Split{start, km5, km10, finish},
+			visited: map[*Split]time.Duration{start: 30 * time.Second},
+		},
+		{
+			name:    "steady pace",
+			splits:  []*Split{finish, km10, start, km5},
+			visited: map[*Split]time.Duration{start: 0, km5: 30 * time.Minute, km10: 60 * time.Minute},
+			ok:      true,
+			last:    km10,
+			pace:    6 * time.Minute,
+			// equal segment paces give minimal spread of 3%
+			expected: []prediction{{finish, 90 * time.Minute, 89*time.Minute + 6*time.Second, 90*time.Minute + 54*time.Second}},
+		},
+		{
+			name:    "laps",
+			splits:  []*Split{lap1, lap2, lapFinish},
+			visited: map[*Split]time.Duration{lap1: 20 * time.Minute},
+			ok:      true,
+			last:    lap1,
+			pace:    4 * time.Minute,
+			// pace from wave start, single segment gives default spread of 10%
+			expected: []prediction{
+				{lap2, 40 * time.Minute, 38 * time.Minute, 42 * time.Minute},
+				{lapFinish, 60 * time.Minute, 56 * time.Minute, 64 * time.Minute},
+			},
+		},
+		{
+			name:    "pace below min time",
+			splits:  []*Split{start, km5, fastKm10, finish},
+			visited: map[*Split]time.Duration{start: 0, km5: 15 * time.Minute},
+			ok:      true,
+			last:    km5,
+			pace:    3 * time.Minute,
+			expected: []prediction{
+				{fastKm10, 40 * time.Minute, 40 * time.Minute, 40 * time.Minute},
+				{finish, 45 * time.Minute, 42 * time.Minute, 48 * time.Minute},
+			},
+		},
+		{
+			name:    "pace above max time",
+			splits:  []*Split{start, km5, km10, slowFinish},
+			visited: map[*Split]time.Duration{start: 0, km5: 50 * time.Minute},
+			ok:      true,
+			last:    km5,
+			pace:    10 * time.Minute,
+			// finish is cut to its max time, so it is expected before 10km
+			expected: []prediction{
+				{slowFinish, 80 * time.Minute, 80 * time.Minute, 80 * time.Minute},
+				{km10, 100 * time.Minute, 95 * time.Minute, 105 * time.Minute},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &AthleteProgress{AthleteID: uuid.New(), WaveStart: waveStart}
+			for s, d := range tt.visited {
+				p.Visited = append(p.Visited, &AthleteSplit{SplitID: s.ID, TOD: at(d)})
+			}
+			res, ok := PredictArrivals(p, tt.splits)
+			assert.Equal(t, tt.ok, ok)
+			if !ok {
+				assert.Nil(t, res)
+				return
+			}
+			assert.Equal(t, tt.last.ID, res.LastSplitID)
+			assert.Equal(t, tt.pace, res.PacePerKm)
+			if !assert.Len(t, res.Splits, len(tt.expected)) {
+				return
+			}
+			for i, e := range tt.expected {
+				assert.Equal(t, e.split.ID, res.Splits[i].SplitID)
+				assert.Equal(t, at(e.expected), res.Splits[i].ExpectedTOD, "expected at %s", e.split.Name)
+				assert.Equal(t, at(e.earliest), res.Splits[i].EarliestTOD, "earliest at %s", e.split.Name)
+				assert.Equal(t, at(e.latest), res.Splits[i].LatestTOD, "latest at %s", e.split.Name)
+			}
+		})
+	}
+}
@@ -9,13 +9,14 @@ import (
 )
 
 type Race struct {
-	ID       uuid.UUID `json:"race_id"`
-	Name     string    `json:"race_name"`
-	Timezone string    `json:"timezone"`
+	ID       uuid.UUID  `json:"race_id"`
+	Name     string     `json:"race_name"`
+	Timezone string     `json:"timezone"`
+	Status   RaceStatus `json:"status"`
 }
 
 func NewRace(req *dto.RaceDTO, v *validator.Validator) *Race {
-	v.Check(IsIANATimezone(req.Timezone), "timezone", "must be valid IANA timezone")
+	v.CheckField(IsIANATimezone(req.Timezone), "timezone", validator.CodeInvalid, "must be valid IANA timezone")
 	if !v.Valid() {
 		return nil
 	}
@@ -24,6 +25,7 @@ func NewRace(req *dto.RaceDTO, v *validator.Validator) *Race {
 		ID:       req.ID,
 		Name:     req.Name,
 		Timezone: req.Timezone,
+		Status:   RaceStatusSetup,
 	}
 }
 
@@ -32,8 +34,74 @@ func IsIANATimezone(tz string) bool {
 	return err == nil
 }
 
+// Location returns location of race timezone, UTC if timezone is unknown
+func (r *Race) Location() *time.Location {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// WallClock returns time with date and clock of t in loc, offset t is given with is ignored.
+// Clients configure times of the race as wall clock time of race timezone and may send them with Z suffix.
+// Zero time is kept as is.
+func WallClock(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
+
+// ParseWallClock parses RFC 3339 time as wall clock time in loc
+func ParseWallClock(s string, loc *time.Location) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return WallClock(t, loc), nil
+}
+
+// InLocation returns t in loc for rendering time of day in race timezone, zero time is kept as is
+func InLocation(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.In(loc)
+}
+
 type RaceModel struct {
 	*Race       `json:"race"`
 	TimeReaders []*TimeReader `json:"time_readers"`
 	Events      []*Event      `json:"events"`
 }
+
+// DTO returns race config in the form it is configured in, times are rendered in race timezone
+// so they are read back as the same wall clock time
+func (rm *RaceModel) DTO() *dto.RaceModelDTO {
+	rm.InLocation()
+	rd := &dto.RaceModelDTO{
+		RaceDTO: &dto.RaceDTO{
+			ID:       rm.ID,
+			Name:     rm.Name,
+			Timezone: rm.Timezone,
+		},
+		TimeReaders: make([]*dto.TimeReaderDTO, 0, len(rm.TimeReaders)),
+		Events:      make([]*dto.EventModelDTO, 0, len(rm.Events)),
+	}
+	for _, tr := range rm.TimeReaders {
+		rd.TimeReaders = append(rd.TimeReaders, tr.DTO())
+	}
+	for _, e := range rm.Events {
+		rd.Events = append(rd.Events, e.DTO())
+	}
+	return rd
+}
+
+// InLocation converts wave starts and split cutoffs of the race to race timezone
+func (rm *RaceModel) InLocation() {
+	loc := rm.Location()
+	for _, e := range rm.Events {
+		e.InLocation(loc)
+	}
+}
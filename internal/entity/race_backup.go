@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RaceBackupVersion is version of backup archives. It changes when tables of the race change, so archive
+// is restored only into the schema it was taken from.
+const RaceBackupVersion = 2
+
+var (
+	ErrRaceBackup         = errors.New("invalid race backup")
+	ErrRaceBackupConflict = errors.New("race backup conflicts with existing data")
+)
+
+// RaceBackupTables are tables stored in backup archive in order of restore. Watermark of results is not stored,
+// it is seeded again by live results.
+var RaceBackupTables = []string{
+	"statuses",
+	"races",
+	"race_status_history",
+	"time_readers",
+	"events",
+	"splits",
+	"waves",
+	"wave_audit",
+	"categories",
+	"athletes",
+	"event_athlete",
+	"chip_bib",
+	"athlete_category",
+	"reader_records",
+	"athlete_split",
+	"athlete_category_rank",
+}
+
+const raceBackupManifest = "manifest.json"
+
+type RaceBackupManifest struct {
+	Version   int            `json:"version"`
+	RaceID    uuid.UUID      `json:"race_id"`
+	RaceName  string         `json:"race_name"`
+	CreatedAt time.Time      `json:"created_at"`
+	Rows      map[string]int `json:"rows"`
+}
+
+// RaceBackup is snapshot of all rows of the race. Tables hold rows of each table as JSON array.
+type RaceBackup struct {
+	RaceBackupManifest
+	Tables map[string]json.RawMessage
+}
+
+func NewRaceBackup(r *Race) *RaceBackup {
+	return &RaceBackup{
+		RaceBackupManifest: RaceBackupManifest{
+			Version:   RaceBackupVersion,
+			RaceID:    r.ID,
+			RaceName:  r.Name,
+			CreatedAt: time.Now(),
+			Rows:      make(map[string]int, len(RaceBackupTables)),
+		},
+		Tables: make(map[string]json.RawMessage, len(RaceBackupTables)),
+	}
+}
+
+// WriteZip writes backup as zip archive with manifest and a JSON file for every table
+func (b *RaceBackup) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := writeZipJSON(zw, raceBackupManifest, b.RaceBackupManifest)
+	if err != nil {
+		return err
+	}
+	for _, t := range RaceBackupTables {
+		err = writeZipJSON(zw, t+".json", b.Tables[t])
+		if err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(f).Encode(v)
+}
+
+// ReadRaceBackup reads backup archive written by WriteZip. Archive of other version or without some
+// of the tables is rejected with ErrRaceBackup.
+func ReadRaceBackup(r io.ReaderAt, size int64) (*RaceBackup, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrRaceBackup, err)
+	}
+	b := &RaceBackup{Tables: make(map[string]json.RawMessage, len(RaceBackupTables))}
+	err = readZipJSON(zr, raceBackupManifest, &b.RaceBackupManifest)
+	if err != nil {
+		return nil, err
+	}
+	if b.Version != RaceBackupVersion {
+		return nil, fmt.Errorf("%w: version %d is not supported, must be %d", ErrRaceBackup, b.Version, RaceBackupVersion)
+	}
+	if b.RaceID == uuid.Nil {
+		return nil, fmt.Errorf("%w: race_id is missing", ErrRaceBackup)
+	}
+	for _, t := range RaceBackupTables {
+		var rows json.RawMessage
+		err = readZipJSON(zr, t+".json", &rows)
+		if err != nil {
+			return nil, err
+		}
+		b.Tables[t] = rows
+	}
+	return b, nil
+}
+
+func readZipJSON(zr *zip.Reader, name string, v any) error {
+	f, err := zr.Open(name)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrRaceBackup, err)
+	}
+	defer f.Close()
+	err = json.NewDecoder(f).Decode(v)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrRaceBackup, name, err)
+	}
+	return nil
+}
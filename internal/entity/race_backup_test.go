@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaceBackupZip(t *testing.T) {
+	b := NewRaceBackup(&Race{ID: uuid.New(), Name: "Spring run"})
+	for _, table := range RaceBackupTables {
+		b.Tables[table] = json.RawMessage(`[]`)
+		b.Rows[table] = 0
+	}
+	b.Tables["reader_records"] = json.RawMessage(`[{"ID":7,"Chip":1001}]`)
+	b.Rows["reader_records"] = 1
+
+	var buf bytes.Buffer
+	assert.NoError(t, b.WriteZip(&buf))
+
+	got, err := ReadRaceBackup(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, b.RaceID, got.RaceID)
+	assert.Equal(t, "Spring run", got.RaceName)
+	assert.Equal(t, 1, got.Rows["reader_records"])
+	assert.JSONEq(t, `[{"ID":7,"Chip":1001}]`, string(got.Tables["reader_records"]))
+	assert.Len(t, got.Tables, len(RaceBackupTables))
+
+	t.Run("missing table", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		assert.NoError(t, writeZipJSON(zw, raceBackupManifest, b.RaceBackupManifest))
+		assert.NoError(t, zw.Close())
+		_, err := ReadRaceBackup(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.ErrorIs(t, err, ErrRaceBackup)
+	})
+
+	t.Run("other version", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		m := b.RaceBackupManifest
+		m.Version = RaceBackupVersion + 1
+		assert.NoError(t, writeZipJSON(zw, raceBackupManifest, m))
+		assert.NoError(t, zw.Close())
+		_, err := ReadRaceBackup(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.ErrorIs(t, err, ErrRaceBackup)
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		_, err := ReadRaceBackup(bytes.NewReader([]byte("race")), 4)
+		assert.ErrorIs(t, err, ErrRaceBackup)
+	})
+}
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RaceCloneRequest copies race config under Name with all dates moved by OffsetDays, e.g. for next year's edition
+type RaceCloneRequest struct {
+	Name       string `json:"race_name"`
+	OffsetDays int    `json:"offset_days"`
+}
+
+// Clone returns deep copy of race config with new IDs under name. Event dates, wave starts and split cutoffs
+// are moved by offsetDays keeping time of day in race timezone, date ranges of age categories are recomputed
+// for the new event dates. Waves of the copy are not launched.
+func (rm *RaceModel) Clone(name string, offsetDays int) *RaceModel {
+	loc := rm.Location()
+	shift := func(t time.Time) time.Time {
+		if t.IsZero() {
+			return t
+		}
+		return t.In(loc).AddDate(0, 0, offsetDays)
+	}
+
+	raceID := uuid.New()
+	c := &RaceModel{
+		Race: &Race{
+			ID:       raceID,
+			Name:     name,
+			Timezone: rm.Timezone,
+			Status:   RaceStatusSetup,
+		},
+		TimeReaders: make([]*TimeReader, 0, len(rm.TimeReaders)),
+		Events:      make([]*Event, 0, len(rm.Events)),
+	}
+	readerIDs := make(map[uuid.UUID]uuid.UUID, len(rm.TimeReaders))
+	for _, tr := range rm.TimeReaders {
+		readerIDs[tr.ID] = uuid.New()
+		c.TimeReaders = append(c.TimeReaders, &TimeReader{
+			ID:         readerIDs[tr.ID],
+			RaceID:     raceID,
+			ReaderName: tr.ReaderName,
+		})
+	}
+
+	for _, e := range rm.Events {
+		ce := &Event{
+			ID:               uuid.New(),
+			RaceID:           raceID,
+			Name:             e.Name,
+			DistanceInMeters: e.DistanceInMeters,
+			EventDate:        e.EventDate.AddDate(0, 0, offsetDays),
+			Splits:           make([]*Split, 0, len(e.Splits)),
+			Waves:            make([]*Wave, 0, len(e.Waves)),
+			Categories:       make([]*Category, 0, len(e.Categories)),
+		}
+		splitIDs := make(map[uuid.UUID]uuid.UUID, len(e.Splits))
+		for _, s := range e.Splits {
+			splitIDs[s.ID] = uuid.New()
+		}
+		for _, s := range e.Splits {
+			cs := *s
+			cs.ID, cs.RaceID, cs.EventID = splitIDs[s.ID], raceID, ce.ID
+			cs.TimeReaderID = readerIDs[s.TimeReaderID]
+			if s.PreviousLapSplitID.Valid {
+				cs.PreviousLapSplitID.UUID = splitIDs[s.PreviousLapSplitID.UUID]
+			}
+			cs.CutoffTOD = shift(s.CutoffTOD)
+			ce.Splits = append(ce.Splits, &cs)
+		}
+		for _, w := range e.Waves {
+			cw := *w
+			cw.ID, cw.RaceID, cw.EventID = uuid.New(), raceID, ce.ID
+			cw.StartTime = shift(w.StartTime)
+			cw.IsLaunched = false
+			if w.TriggerReaderID.Valid {
+				cw.TriggerReaderID.UUID = readerIDs[w.TriggerReaderID.UUID]
+			}
+			ce.Waves = append(ce.Waves, &cw)
+		}
+		for _, cat := range e.Categories {
+			cc := *cat
+			cc.ID, cc.RaceID, cc.EventID = uuid.New(), raceID, ce.ID
+			if cat.Kind == CategoryKindAge {
+				cc.DateFrom, cc.DateTo = GetDateRange(cat.DTO(), ce.EventDate)
+			}
+			ce.Categories = append(ce.Categories, &cc)
+		}
+		c.Events = append(c.Events, ce)
+	}
+	return c
+}
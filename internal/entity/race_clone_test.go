@@ -0,0 +1,70 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaceModelClone(t *testing.T) {
+	raceID, eventID := uuid.New(), uuid.New()
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	eventDate := time.Date(2025, time.March, 29, 0, 0, 0, 0, time.UTC)
+	start := &TimeReader{ID: uuid.New(), RaceID: raceID, ReaderName: "start"}
+	finish := &TimeReader{ID: uuid.New(), RaceID: raceID, ReaderName: "finish"}
+	lap := &Split{ID: uuid.New(), Name: "Lap 1", Type: SplitTypeStandard, TimeReaderID: finish.ID}
+	finishSplit := &Split{
+		ID: uuid.New(), Name: "Finish", Type: SplitTypeFinish, TimeReaderID: finish.ID,
+		PreviousLapSplitID: uuid.NullUUID{UUID: lap.ID, Valid: true},
+	}
+	// 09:00 in Berlin before DST change on 30 March 2025
+	wave := &Wave{
+		ID: uuid.New(), Name: "Elite", StartTime: time.Date(2025, time.March, 29, 8, 0, 0, 0, time.UTC), IsLaunched: true,
+		TriggerChip: 1, TriggerReaderID: uuid.NullUUID{UUID: start.ID, Valid: true},
+	}
+	category := &Category{ID: uuid.New(), Name: "M18-39", Kind: CategoryKindAge, Gender: CategoryGenderMale, AgeFrom: 18, AgeTo: 39}
+	category.DateFrom, category.DateTo = GetDateRange(category.DTO(), eventDate)
+	rm := &RaceModel{
+		Race:        &Race{ID: raceID, Name: "Spring run 2025", Timezone: loc.String(), Status: RaceStatusArchived},
+		TimeReaders: []*TimeReader{start, finish},
+		Events: []*Event{{
+			ID: eventID, RaceID: raceID, Name: "10K", DistanceInMeters: 10000, EventDate: eventDate,
+			Splits:     []*Split{lap, finishSplit},
+			Waves:      []*Wave{wave},
+			Categories: []*Category{category},
+		}},
+	}
+
+	c := rm.Clone("Spring run 2026", 364)
+
+	assert.NotEqual(t, raceID, c.ID)
+	assert.Equal(t, "Spring run 2026", c.Name)
+	assert.Equal(t, RaceStatusSetup, c.Status)
+	assert.Len(t, c.TimeReaders, 2)
+	assert.NotEqual(t, start.ID, c.TimeReaders[0].ID)
+	assert.Equal(t, c.ID, c.TimeReaders[0].RaceID)
+
+	e := c.Events[0]
+	assert.NotEqual(t, eventID, e.ID)
+	assert.Equal(t, time.Date(2026, time.March, 28, 0, 0, 0, 0, time.UTC), e.EventDate)
+
+	assert.Equal(t, c.TimeReaders[1].ID, e.Splits[0].TimeReaderID)
+	assert.Equal(t, e.ID, e.Splits[0].EventID)
+	assert.Equal(t, e.Splits[0].ID, e.Splits[1].PreviousLapSplitID.UUID, "lap split points to the copy")
+
+	w := e.Waves[0]
+	assert.False(t, w.IsLaunched)
+	assert.Equal(t, c.TimeReaders[0].ID, w.TriggerReaderID.UUID)
+	assert.Equal(t, "09:00", w.StartTime.In(loc).Format("15:04"), "time of day is kept across DST change")
+	assert.Equal(t, "2026-03-28", w.StartTime.In(loc).Format(time.DateOnly))
+
+	cat := e.Categories[0]
+	assert.NotEqual(t, category.ID, cat.ID)
+	assert.Equal(t, 1987, cat.DateFrom.Year())
+	assert.Equal(t, 2008, cat.DateTo.Year())
+
+	assert.True(t, wave.IsLaunched, "source race is not changed")
+	assert.Equal(t, eventDate, rm.Events[0].EventDate)
+}
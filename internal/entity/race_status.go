@@ -0,0 +1,156 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// RaceStatus is stage of race lifecycle
+type RaceStatus string
+
+const (
+	// RaceStatusSetup race is being configured, timing has not begun
+	RaceStatusSetup RaceStatus = "setup"
+	// RaceStatusLive reads are turned into results
+	RaceStatusLive RaceStatus = "live"
+	// RaceStatusProvisional timing is over, results are published but can still be corrected
+	RaceStatusProvisional RaceStatus = "provisional"
+	// RaceStatusOfficial results are final and read-only
+	RaceStatusOfficial RaceStatus = "official"
+	// RaceStatusArchived race is kept for history, read-only
+	RaceStatusArchived RaceStatus = "archived"
+)
+
+// ErrRaceStatus is returned when operation is not allowed in current status of the race
+var ErrRaceStatus = errors.New("not allowed in current race status")
+
+// raceStatusTransitions lists statuses race can move to, true if the move must be forced
+var raceStatusTransitions = map[RaceStatus]map[RaceStatus]bool{
+	RaceStatusSetup:       {RaceStatusLive: false},
+	RaceStatusLive:        {RaceStatusProvisional: false, RaceStatusSetup: true},
+	RaceStatusProvisional: {RaceStatusOfficial: false, RaceStatusLive: false},
+	RaceStatusOfficial:    {RaceStatusArchived: false, RaceStatusProvisional: true},
+	RaceStatusArchived:    {RaceStatusOfficial: true},
+}
+
+var raceStatuses = []RaceStatus{RaceStatusSetup, RaceStatusLive, RaceStatusProvisional, RaceStatusOfficial, RaceStatusArchived}
+
+func IsValidRaceStatus(s RaceStatus) bool {
+	return slices.Contains(raceStatuses, s)
+}
+
+// ReadOnly reports whether config of the race, its athletes and results can't be changed
+func (s RaceStatus) ReadOnly() bool {
+	return s == RaceStatusOfficial || s == RaceStatusArchived
+}
+
+// TimingStarted reports whether results of the race are being calculated from reads
+func (s RaceStatus) TimingStarted() bool {
+	return s == RaceStatusLive || s == RaceStatusProvisional
+}
+
+// CheckChange returns error if config, athletes or results of the race can't be changed
+func (s RaceStatus) CheckChange() error {
+	if s.ReadOnly() {
+		return fmt.Errorf("%w: race is %s, it is read-only", ErrRaceStatus, s)
+	}
+	return nil
+}
+
+// CheckReadersChange returns error if readers of splits can't be changed. Once timing has begun the change
+// moves reads to other splits, so it must be forced.
+func (s RaceStatus) CheckReadersChange(force bool) error {
+	if err := s.CheckChange(); err != nil {
+		return err
+	}
+	if s.TimingStarted() && !force {
+		return fmt.Errorf("%w: race is %s, changing readers of splits must be forced", ErrRaceStatus, s)
+	}
+	return nil
+}
+
+// CheckDelete returns error if race can't be deleted. Race which is timed or has results must be deleted with force.
+func (s RaceStatus) CheckDelete(force bool) error {
+	if (s.TimingStarted() || s == RaceStatusOfficial) && !force {
+		return fmt.Errorf("%w: race is %s, deleting it must be forced", ErrRaceStatus, s)
+	}
+	return nil
+}
+
+// RaceStatusRequest moves race to Status. Moves back, e.g. from live to setup, must be forced and have a reason.
+type RaceStatusRequest struct {
+	Status RaceStatus `json:"status"`
+	Reason string     `json:"reason"`
+	Force  bool       `json:"force"`
+}
+
+// RaceStatusChange records transition of race between statuses
+type RaceStatusChange struct {
+	ID        int64      `json:"id"`
+	RaceID    uuid.UUID  `json:"race_id"`
+	OldStatus RaceStatus `json:"old_status"`
+	NewStatus RaceStatus `json:"new_status"`
+	Forced    bool       `json:"forced"`
+	Reason    string     `json:"reason"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NewRaceStatusChange validates moving race r to requested status
+func NewRaceStatusChange(r *Race, req RaceStatusRequest, v *validator.Validator) *RaceStatusChange {
+	v.CheckField(IsValidRaceStatus(req.Status), "status", validator.CodeInvalid, "must be one of setup, live, provisional, official, archived")
+	if !v.Valid() {
+		return nil
+	}
+	forced, ok := raceStatusTransitions[r.Status][req.Status]
+	v.CheckField(ok, "status", validator.CodeConflict, fmt.Sprintf("race can't move from %s to %s", r.Status, req.Status))
+	if !v.Valid() {
+		return nil
+	}
+	if forced {
+		v.CheckField(req.Force, "force", validator.CodeRequired, fmt.Sprintf("moving race from %s back to %s must be forced", r.Status, req.Status))
+		v.CheckField(req.Reason != "", "reason", validator.CodeRequired, "must be provided for forced change")
+	}
+	if !v.Valid() {
+		return nil
+	}
+	return &RaceStatusChange{
+		RaceID:    r.ID,
+		OldStatus: r.Status,
+		NewStatus: req.Status,
+		Forced:    forced,
+		Reason:    req.Reason,
+	}
+}
+
+// SplitReadersChanged reports whether reads of saved config prev are turned into different results by next,
+// i.e. a split is deleted or moved to another reader or its reader is renamed
+func SplitReadersChanged(prev, next *RaceModel) bool {
+	readerNames := func(rm *RaceModel) map[uuid.UUID]string {
+		names := make(map[uuid.UUID]string, len(rm.TimeReaders))
+		for _, tr := range rm.TimeReaders {
+			names[tr.ID] = tr.ReaderName
+		}
+		return names
+	}
+	prevNames, nextNames := readerNames(prev), readerNames(next)
+	nextSplits := make(map[uuid.UUID]*Split)
+	for _, e := range next.Events {
+		for _, s := range e.Splits {
+			nextSplits[s.ID] = s
+		}
+	}
+	for _, e := range prev.Events {
+		for _, s := range e.Splits {
+			ns, ok := nextSplits[s.ID]
+			if !ok || ns.TimeReaderID != s.TimeReaderID || nextNames[ns.TimeReaderID] != prevNames[s.TimeReaderID] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRaceStatusChange(t *testing.T) {
+	r := &Race{ID: uuid.New(), Status: RaceStatusSetup}
+
+	v := validator.New()
+	c := NewRaceStatusChange(r, RaceStatusRequest{Status: RaceStatusLive}, v)
+	assert.True(t, v.Valid())
+	assert.Equal(t, &RaceStatusChange{RaceID: r.ID, OldStatus: RaceStatusSetup, NewStatus: RaceStatusLive}, c)
+
+	t.Run("statuses can't be skipped", func(t *testing.T) {
+		v := validator.New()
+		assert.Nil(t, NewRaceStatusChange(r, RaceStatusRequest{Status: RaceStatusOfficial}, v))
+		assert.Contains(t, v.Errors, "status")
+	})
+
+	t.Run("unknown status", func(t *testing.T) {
+		v := validator.New()
+		assert.Nil(t, NewRaceStatusChange(r, RaceStatusRequest{Status: "finished"}, v))
+		assert.Contains(t, v.Errors, "status")
+	})
+
+	t.Run("moving back must be forced with reason", func(t *testing.T) {
+		official := &Race{ID: r.ID, Status: RaceStatusOfficial}
+		v := validator.New()
+		assert.Nil(t, NewRaceStatusChange(official, RaceStatusRequest{Status: RaceStatusProvisional}, v))
+		assert.Contains(t, v.Errors, "force")
+		assert.Contains(t, v.Errors, "reason")
+
+		v = validator.New()
+		c := NewRaceStatusChange(official, RaceStatusRequest{Status: RaceStatusProvisional, Force: true, Reason: "protest upheld"}, v)
+		assert.True(t, v.Valid())
+		assert.True(t, c.Forced)
+	})
+
+	t.Run("provisional results can go back to live", func(t *testing.T) {
+		v := validator.New()
+		c := NewRaceStatusChange(&Race{Status: RaceStatusProvisional}, RaceStatusRequest{Status: RaceStatusLive}, v)
+		assert.True(t, v.Valid())
+		assert.False(t, c.Forced)
+	})
+}
+
+func TestRaceStatusChecks(t *testing.T) {
+	assert.NoError(t, RaceStatusSetup.CheckChange())
+	assert.NoError(t, RaceStatusProvisional.CheckChange())
+	assert.ErrorIs(t, RaceStatusOfficial.CheckChange(), ErrRaceStatus)
+	assert.ErrorIs(t, RaceStatusArchived.CheckChange(), ErrRaceStatus)
+
+	assert.NoError(t, RaceStatusSetup.CheckReadersChange(false))
+	assert.ErrorIs(t, RaceStatusLive.CheckReadersChange(false), ErrRaceStatus)
+	assert.NoError(t, RaceStatusLive.CheckReadersChange(true))
+	assert.ErrorIs(t, RaceStatusOfficial.CheckReadersChange(true), ErrRaceStatus, "official results are read-only even with force")
+
+	assert.NoError(t, RaceStatusSetup.CheckDelete(false))
+	assert.NoError(t, RaceStatusArchived.CheckDelete(false))
+	assert.ErrorIs(t, RaceStatusLive.CheckDelete(false), ErrRaceStatus)
+	assert.ErrorIs(t, RaceStatusOfficial.CheckDelete(false), ErrRaceStatus)
+	assert.NoError(t, RaceStatusLive.CheckDelete(true))
+}
+
+func TestSplitReadersChanged(t *testing.T) {
+	start := &TimeReader{ID: uuid.New(), ReaderName: "start"}
+	finish := &TimeReader{ID: uuid.New(), ReaderName: "finish"}
+	split := &Split{ID: uuid.New(), Name: "Finish", TimeReaderID: finish.ID}
+	model := func(readers []*TimeReader, splits ...*Split) *RaceModel {
+		return &RaceModel{TimeReaders: readers, Events: []*Event{{Splits: splits}}}
+	}
+	prev := model([]*TimeReader{start, finish}, split)
+
+	renamed := *split
+	renamed.Name = "Finish line"
+	assert.False(t, SplitReadersChanged(prev, model([]*TimeReader{start, finish}, &renamed)))
+	assert.False(t, SplitReadersChanged(prev, model([]*TimeReader{finish}, split)), "unused reader is removed")
+
+	moved := *split
+	moved.TimeReaderID = start.ID
+	assert.True(t, SplitReadersChanged(prev, model([]*TimeReader{start, finish}, &moved)))
+	assert.True(t, SplitReadersChanged(prev, model([]*TimeReader{start, finish})), "split is deleted")
+
+	box := *finish
+	box.ReaderName = "finish-2"
+	assert.True(t, SplitReadersChanged(prev, model([]*TimeReader{start, &box}, split)), "reader of split is renamed")
+}
@@ -0,0 +1,31 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaceLocation(t *testing.T) {
+	r := &Race{Timezone: "Europe/Berlin"}
+	loc := r.Location()
+	assert.Equal(t, "Europe/Berlin", loc.String())
+
+	t.Run("unknown timezone falls back to UTC", func(t *testing.T) {
+		assert.Equal(t, time.UTC, (&Race{Timezone: "Nowhere/City"}).Location())
+	})
+
+	t.Run("zero time is kept", func(t *testing.T) {
+		assert.True(t, InLocation(time.Time{}, loc).IsZero())
+	})
+
+	t.Run("times across DST change", func(t *testing.T) {
+		// clocks go forward from 02:00 to 03:00 on 30 March 2025
+		start, _ := time.Parse(time.RFC3339, "2025-03-30T01:30:00+01:00")
+		read, _ := time.Parse(time.RFC3339, "2025-03-30T01:30:00Z")
+		assert.Equal(t, time.Hour, read.Sub(start))
+		assert.Equal(t, "01:30:00", InLocation(start, loc).Format(time.TimeOnly))
+		assert.Equal(t, "03:30:00", InLocation(read, loc).Format(time.TimeOnly))
+	})
+}
@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecalcStatus describes periodic results recalculation of the race.
+type RecalcStatus struct {
+	RaceID       uuid.UUID     `json:"race_id"`
+	Paused       bool          `json:"paused"`
+	Running      bool          `json:"running"`
+	Interval     time.Duration `json:"interval"`
+	Runs         int           `json:"runs"`
+	LastRunAt    time.Time     `json:"last_run_at"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+}
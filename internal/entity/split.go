@@ -1,7 +1,10 @@
 package entity
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
@@ -29,24 +32,34 @@ type Split struct {
 	MaxTime            time.Duration `json:"max_time"`
 	MinLapTime         time.Duration `json:"min_lap_time"`
 	PreviousLapSplitID uuid.NullUUID `json:"previous_lap_split"`
+	CutoffTime         time.Duration `json:"cutoff_time"`
+	CutoffTOD          time.Time     `json:"cutoff_tod"`
 }
 
-func NewSplit(dto *dto.SplitDTO, trs []*dto.TimeReaderDTO, v *validator.Validator) *Split {
-	v.Check(IsValidSplitType(SplitType(dto.Type)), "split type", "must be start, standard or finish")
+// NewSplit builds split from config, cutoff time of day is wall clock time in loc of race timezone
+func NewSplit(dto *dto.SplitDTO, trs []*dto.TimeReaderDTO, loc *time.Location, v *validator.Validator) *Split {
+	v.CheckField(IsValidSplitType(SplitType(dto.Type)), "split_type", validator.CodeInvalid, "must be start, standard or finish")
 	var tpIDsForLocs []uuid.UUID
 	for _, l := range trs {
 		tpIDsForLocs = append(tpIDsForLocs, l.ID)
 	}
 
-	v.Check(validator.PermittedValue(dto.TimeReaderID, tpIDsForLocs...), "split ID", "must have valid corresponded time reader")
+	v.CheckField(validator.PermittedValue(dto.TimeReaderID, tpIDsForLocs...), "time_reader_id", validator.CodeNotFound, "must have valid corresponded time reader")
 
-	v.Check(dto.DistanceFromStart >= 0, "split distance from start", "must be greater or equal to 0")
+	v.CheckField(dto.DistanceFromStart >= 0, "distance_from_start", validator.CodeOutOfRange, "must be greater or equal to 0")
 	minTime, _ := time.ParseDuration(dto.MinTime)
 	maxTime, _ := time.ParseDuration(dto.MaxTime)
 	minLapTime, _ := time.ParseDuration(dto.MinLapTime)
-	v.Check(minTime >= 0, "split min time", "must be greater or equal to 0")
-	v.Check(maxTime >= 0, "split max time", "must be greater or equal to 0")
-	v.Check(minLapTime >= 0, "split min lap time", "must be greater or equal to 0")
+	v.CheckField(minTime >= 0, "min_time_sec", validator.CodeOutOfRange, "must be greater or equal to 0")
+	v.CheckField(maxTime >= 0, "max_time_sec", validator.CodeOutOfRange, "must be greater or equal to 0")
+	v.CheckField(minLapTime >= 0, "min_lap_time_sec", validator.CodeOutOfRange, "must be greater or equal to 0")
+	v.CheckField(minTime == 0 || maxTime == 0 || minTime <= maxTime, "min_time_sec", validator.CodeOutOfRange, "must not be greater than max time")
+	cutoffTime, _ := time.ParseDuration(dto.CutoffTime)
+	v.CheckField(cutoffTime >= 0, "cutoff_time_sec", validator.CodeOutOfRange, "must be greater or equal to 0")
+	var cutoffTOD time.Time
+	if dto.CutoffTOD != "" {
+		cutoffTOD, _ = ParseWallClock(dto.CutoffTOD, loc)
+	}
 
 	if !v.Valid() {
 		return nil
@@ -63,6 +76,31 @@ func NewSplit(dto *dto.SplitDTO, trs []*dto.TimeReaderDTO, v *validator.Validato
 		MaxTime:            maxTime,
 		MinLapTime:         minLapTime,
 		PreviousLapSplitID: uuid.NullUUID{},
+		CutoffTime:         cutoffTime,
+		CutoffTOD:          cutoffTOD,
+	}
+}
+
+// DTO returns split in the form it is configured in, so it can be validated again with the rest of event
+func (s *Split) DTO() *dto.SplitDTO {
+	var cutoffTOD string
+	if !s.CutoffTOD.IsZero() {
+		cutoffTOD = s.CutoffTOD.Format(time.RFC3339Nano)
+	}
+	return &dto.SplitDTO{
+		ID:                 s.ID,
+		RaceID:             s.RaceID,
+		EventID:            s.EventID,
+		Name:               s.Name,
+		Type:               string(s.Type),
+		DistanceFromStart:  s.DistanceFromStart,
+		TimeReaderID:       s.TimeReaderID,
+		MinTime:            s.MinTime.String(),
+		MaxTime:            s.MaxTime.String(),
+		MinLapTime:         s.MinLapTime.String(),
+		PreviousLapSplitID: s.PreviousLapSplitID,
+		CutoffTime:         s.CutoffTime.String(),
+		CutoffTOD:          cutoffTOD,
 	}
 }
 
@@ -75,24 +113,96 @@ func IsValidSplitType(tp SplitType) bool {
 	}
 }
 
+type RecordRule string
+
+const (
+	RecordAccepted         RecordRule = "accepted"
+	RecordBeforeWaveStart  RecordRule = "before_wave_start"
+	RecordUnderMinTime     RecordRule = "under_min_time"
+	RecordOverMaxTime      RecordRule = "over_max_time"
+	RecordWithinMinLapTime RecordRule = "within_min_lap_time"
+	// split already has earlier valid read, only start split is overwritten by later reads
+	RecordSplitAlreadyRecorded RecordRule = "split_already_recorded"
+	// read wasn't checked against split because finish was found for the same read
+	RecordNotEvaluated RecordRule = "not_evaluated"
+)
+
 func (s *Split) IsValidForRecord(waveStart time.Time, tod time.Time, prev *AthleteSplit) bool {
+	return s.CheckRecord(waveStart, tod, prev) == RecordAccepted
+}
+
+// CheckRecord returns the rule which rejects the read for the split or RecordAccepted if read is valid.
+func (s *Split) CheckRecord(waveStart time.Time, tod time.Time, prev *AthleteSplit) RecordRule {
 	if tod.Before(waveStart) {
-		return false
+		return RecordBeforeWaveStart
 	}
 	validMinTime := waveStart.Add(time.Duration(s.MinTime))
 
 	if !(tod.After(validMinTime) || tod.Equal(validMinTime)) {
-		return false
+		return RecordUnderMinTime
 	}
 	if s.MaxTime != 0 && !(tod.Before(waveStart.Add(time.Duration(s.MaxTime))) || tod.Equal(waveStart.Add(time.Duration(s.MaxTime)))) {
-		return false
+		return RecordOverMaxTime
 	}
 	if prev != nil {
 		if prev.TOD.Add(time.Duration(s.MinLapTime)).After(tod) {
-			return false
+			return RecordWithinMinLapTime
+		}
+	}
+	return RecordAccepted
+}
+
+// Cutoff returns time of day after which athletes who have not reached the split are out of the race.
+// If both absolute and relative cutoffs are set the earliest one is used.
+func (s *Split) Cutoff(waveStart time.Time) (time.Time, bool) {
+	var cutoff time.Time
+	if s.CutoffTime != 0 {
+		cutoff = waveStart.Add(s.CutoffTime)
+	}
+	if !s.CutoffTOD.IsZero() && (cutoff.IsZero() || s.CutoffTOD.Before(cutoff)) {
+		cutoff = s.CutoffTOD
+	}
+	return cutoff, !cutoff.IsZero()
+}
+
+const cutoffReasonPrefix = "missed cutoff at "
+
+func CutoffReason(s *Split) string {
+	return cutoffReasonPrefix + s.Name
+}
+
+// IsCutoffReason reports whether status reason was set by calculation for missed cutoff.
+func IsCutoffReason(reason string) bool {
+	return strings.HasPrefix(reason, cutoffReasonPrefix)
+}
+
+// MissedCutoff returns the first split with passed cutoff which athlete hasn't reached in time.
+// Split counts as reached if athlete has valid read at it or any further split before the cutoff.
+func MissedCutoff(splits []*Split, athleteSplits []*AthleteSplit, waveStart, now time.Time) (*Split, bool) {
+	visited := make(map[uuid.UUID]time.Time, len(athleteSplits))
+	for _, as := range athleteSplits {
+		if as != nil && as.IsVisited() {
+			visited[as.SplitID] = as.TOD
+		}
+	}
+	ordered := slices.Clone(splits)
+	slices.SortFunc(ordered, func(a, b *Split) int {
+		return cmp.Compare(a.DistanceFromStart, b.DistanceFromStart)
+	})
+	for i, s := range ordered {
+		cutoff, ok := s.Cutoff(waveStart)
+		if !ok || now.Before(cutoff) {
+			continue
+		}
+		reached := slices.ContainsFunc(ordered[i:], func(next *Split) bool {
+			tod, ok := visited[next.ID]
+			return ok && !tod.After(cutoff)
+		})
+		if !reached {
+			return s, true
 		}
 	}
-	return true
+	return nil, false
 }
 
 func (s Split) String() string {
@@ -109,6 +219,8 @@ func (s Split) String() string {
 			"  MaxTime: %s\n"+
 			"  MinLapTime: %s\n"+
 			"  PreviousLapSplitID: %s\n"+
+			"  CutoffTime: %s\n"+
+			"  CutoffTOD: %s\n"+
 			"}",
 		s.ID,
 		s.RaceID,
@@ -121,6 +233,8 @@ func (s Split) String() string {
 		formatDuration(s.MaxTime),
 		formatDuration(s.MinLapTime),
 		formatNullUUID(s.PreviousLapSplitID),
+		formatDuration(s.CutoffTime),
+		s.CutoffTOD.Format(time.DateTime),
 	)
 }
 
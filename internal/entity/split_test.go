@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitCutoff(t *testing.T) {
+	waveStart := time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)
+
+	s := &Split{CutoffTime: 2 * time.Hour}
+	cutoff, ok := s.Cutoff(waveStart)
+	assert.True(t, ok)
+	assert.Equal(t, waveStart.Add(2*time.Hour), cutoff)
+
+	s.CutoffTOD = waveStart.Add(90 * time.Minute)
+	cutoff, _ = s.Cutoff(waveStart)
+	assert.Equal(t, s.CutoffTOD, cutoff)
+
+	_, ok = (&Split{}).Cutoff(waveStart)
+	assert.False(t, ok)
+}
+
+func TestMissedCutoff(t *testing.T) {
+	waveStart := time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)
+	km10 := &Split{ID: uuid.New(), Name: "10km", DistanceFromStart: 10000, CutoffTime: time.Hour}
+	km20 := &Split{ID: uuid.New(), Name: "20km", DistanceFromStart: 20000, CutoffTime: 2 * time.Hour}
+	splits := []*Split{km20, km10}
+	visit := func(s *Split, d time.Duration) *AthleteSplit {
+		return &AthleteSplit{SplitID: s.ID, TOD: waveStart.Add(d)}
+	}
+
+	t.Run("cutoff not passed yet", func(t *testing.T) {
+		_, missed := MissedCutoff(splits, nil, waveStart, waveStart.Add(59*time.Minute))
+		assert.False(t, missed)
+	})
+
+	t.Run("no read before cutoff", func(t *testing.T) {
+		s, missed := MissedCutoff(splits, []*AthleteSplit{visit(km10, 61*time.Minute)}, waveStart, waveStart.Add(90*time.Minute))
+		assert.True(t, missed)
+		assert.Equal(t, km10, s)
+		assert.Equal(t, "missed cutoff at 10km", CutoffReason(s))
+		assert.True(t, IsCutoffReason(CutoffReason(s)))
+	})
+
+	t.Run("missed read covered by further split", func(t *testing.T) {
+		_, missed := MissedCutoff(splits, []*AthleteSplit{visit(km20, 55*time.Minute)}, waveStart, waveStart.Add(3*time.Hour))
+		assert.False(t, missed)
+	})
+}
+
+func TestSplitCheckRecord(t *testing.T) {
+	waveStart := time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)
+	s := &Split{MinTime: 10 * time.Minute, MaxTime: time.Hour, MinLapTime: 20 * time.Minute}
+	prev := &AthleteSplit{TOD: waveStart.Add(15 * time.Minute)}
+
+	tests := []struct {
+		name string
+		tod  time.Time
+		prev *AthleteSplit
+		want RecordRule
+	}{
+		{"before wave start", waveStart.Add(-time.Second), nil, RecordBeforeWaveStart},
+		{"under min time", waveStart.Add(9 * time.Minute), nil, RecordUnderMinTime},
+		{"over max time", waveStart.Add(61 * time.Minute), nil, RecordOverMaxTime},
+		{"within min lap time", waveStart.Add(30 * time.Minute), prev, RecordWithinMinLapTime},
+		{"accepted", waveStart.Add(35 * time.Minute), prev, RecordAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.CheckRecord(waveStart, tt.tod, tt.prev))
+			assert.Equal(t, tt.want == RecordAccepted, s.IsValidForRecord(waveStart, tt.tod, tt.prev))
+		})
+	}
+}
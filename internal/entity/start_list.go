@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StartListEntry is athlete's line in start list. StartTime is the individual start
+// if set, otherwise the start of athlete's wave, delayed by athlete's handicap.
+type StartListEntry struct {
+	AthleteID  uuid.UUID     `json:"athlete_id"`
+	EventID    uuid.UUID     `json:"event_id"`
+	WaveID     uuid.UUID     `json:"wave_id"`
+	Bib        int           `json:"bib"`
+	FirstName  string        `json:"first_name"`
+	LastName   string        `json:"last_name"`
+	EventName  string        `json:"event_name"`
+	WaveName   string        `json:"wave_name"`
+	StartTime  time.Time     `json:"start_time"`
+	Handicap   time.Duration `json:"handicap,omitempty"`
+	Individual bool          `json:"individual"`
+}
+
+// StartTimesRequest generates individual start times for athletes of event (and optionally
+// of one wave) starting at FirstStart every IntervalSec seconds in bib order.
+type StartTimesRequest struct {
+	EventID     uuid.UUID     `json:"event_id"`
+	WaveID      uuid.NullUUID `json:"wave_id"`
+	FirstStart  string        `json:"first_start"`
+	IntervalSec int           `json:"interval_sec"`
+}
+
+// AthleteStartTime is individual start time assigned to athlete.
+type AthleteStartTime struct {
+	AthleteID uuid.UUID `json:"athlete_id"`
+	Bib       int       `json:"bib"`
+	StartTime time.Time `json:"start_time"`
+}
+
+// GenerateStartTimes assigns start times to entries in bib order, first athlete starts at first
+// and every next one interval later.
+func GenerateStartTimes(entries []*StartListEntry, first time.Time, interval time.Duration) []*AthleteStartTime {
+	sorted := make([]*StartListEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Bib < sorted[j].Bib
+	})
+	res := make([]*AthleteStartTime, 0, len(sorted))
+	for i, e := range sorted {
+		res = append(res, &AthleteStartTime{
+			AthleteID: e.AthleteID,
+			Bib:       e.Bib,
+			StartTime: first.Add(time.Duration(i) * interval),
+		})
+	}
+	return res
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateStartTimes(t *testing.T) {
+	first := time.Date(2025, time.May, 20, 10, 0, 0, 0, time.UTC)
+	entries := []*StartListEntry{
+		{AthleteID: uuid.New(), Bib: 12},
+		{AthleteID: uuid.New(), Bib: 3},
+		{AthleteID: uuid.New(), Bib: 7},
+	}
+
+	st := GenerateStartTimes(entries, first, 30*time.Second)
+	assert.Len(t, st, 3)
+	assert.Equal(t, []int{3, 7, 12}, []int{st[0].Bib, st[1].Bib, st[2].Bib})
+	assert.Equal(t, entries[1].AthleteID, st[0].AthleteID)
+	assert.Equal(t, first, st[0].StartTime)
+	assert.Equal(t, first.Add(30*time.Second), st[1].StartTime)
+	assert.Equal(t, first.Add(time.Minute), st[2].StartTime)
+	assert.Equal(t, 12, entries[0].Bib, "entries must keep their order")
+
+	assert.Empty(t, GenerateStartTimes(nil, first, time.Second))
+}
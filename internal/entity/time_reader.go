@@ -13,9 +13,9 @@ type TimeReader struct {
 }
 
 func NewTimeReader(dto *dto.TimeReaderDTO, v *validator.Validator) *TimeReader {
-	v.Check(dto.ID != uuid.Nil, "time_reader_id", "must be valid UUID")
-	v.Check(dto.RaceID != uuid.Nil, "reader_race_id", "must be valid UUID")
-	v.Check(dto.ReaderName != "", "reader_name", "must not be empty")
+	v.CheckField(dto.ID != uuid.Nil, "time_reader_id", validator.CodeRequired, "must be valid UUID")
+	v.CheckField(dto.RaceID != uuid.Nil, "race_id", validator.CodeRequired, "must be valid UUID")
+	v.CheckField(dto.ReaderName != "", "reader_name", validator.CodeRequired, "must not be empty")
 	if !v.Valid() {
 		return nil
 	}
@@ -25,3 +25,12 @@ func NewTimeReader(dto *dto.TimeReaderDTO, v *validator.Validator) *TimeReader {
 		ReaderName: dto.ReaderName,
 	}
 }
+
+// DTO returns time reader in the form it is configured in
+func (tr *TimeReader) DTO() *dto.TimeReaderDTO {
+	return &dto.TimeReaderDTO{
+		ID:         tr.ID,
+		RaceID:     tr.RaceID,
+		ReaderName: tr.ReaderName,
+	}
+}
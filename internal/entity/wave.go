@@ -16,6 +16,11 @@ type Wave struct {
 	Name       string    `json:"wave_name"`
 	StartTime  time.Time `json:"start_time"`
 	IsLaunched bool      `json:"is_launched"`
+	// first read of TriggerChip by TriggerReaderID launches the wave at the read's TOD
+	TriggerChip     int           `json:"trigger_chip,omitempty"`
+	TriggerReaderID uuid.NullUUID `json:"trigger_reader_id"`
+	// wave is launched automatically at StartTime in race timezone
+	AutoStart bool `json:"auto_start"`
 }
 
 type WaveStart struct {
@@ -23,18 +28,54 @@ type WaveStart struct {
 	StartTime time.Time `json:"wave_start_time"`
 }
 
-func NewWave(dto *dto.WaveDTO, v *validator.Validator) *Wave {
-	startTime, _ := time.Parse(time.RFC3339, dto.StartTime)
+// NewWave builds wave from config, start time is wall clock time in loc of race timezone
+func NewWave(dto *dto.WaveDTO, trs []*dto.TimeReaderDTO, loc *time.Location, v *validator.Validator) *Wave {
+	startTime, _ := ParseWallClock(dto.StartTime, loc)
+	v.CheckField(dto.TriggerChip >= 0, "trigger_chip", validator.CodeOutOfRange, "must not be negative")
+	v.CheckField((dto.TriggerChip > 0) == dto.TriggerReaderID.Valid, "trigger_reader_id", validator.CodeRequired, "trigger chip and trigger reader must be set together")
+	if dto.TriggerReaderID.Valid {
+		var readerIDs []uuid.UUID
+		for _, tr := range trs {
+			readerIDs = append(readerIDs, tr.ID)
+		}
+		v.CheckField(validator.PermittedValue(dto.TriggerReaderID.UUID, readerIDs...), "trigger_reader_id", validator.CodeNotFound, "must be one of race time readers")
+	}
+	if !v.Valid() {
+		return nil
+	}
 	return &Wave{
-		ID:         dto.ID,
-		RaceID:     dto.RaceID,
-		EventID:    dto.EventID,
-		Name:       dto.Name,
-		StartTime:  startTime,
-		IsLaunched: dto.IsLaunched,
+		ID:              dto.ID,
+		RaceID:          dto.RaceID,
+		EventID:         dto.EventID,
+		Name:            dto.Name,
+		StartTime:       startTime,
+		IsLaunched:      dto.IsLaunched,
+		TriggerChip:     dto.TriggerChip,
+		TriggerReaderID: dto.TriggerReaderID,
+		AutoStart:       dto.AutoStart,
 	}
 }
 
+// DTO returns wave in the form it is configured in
+func (w *Wave) DTO() *dto.WaveDTO {
+	return &dto.WaveDTO{
+		ID:              w.ID,
+		RaceID:          w.RaceID,
+		EventID:         w.EventID,
+		Name:            w.Name,
+		StartTime:       w.StartTime.Format(time.RFC3339Nano),
+		IsLaunched:      w.IsLaunched,
+		TriggerChip:     w.TriggerChip,
+		TriggerReaderID: w.TriggerReaderID,
+		AutoStart:       w.AutoStart,
+	}
+}
+
+// HasTrigger reports whether wave is launched by trigger chip read
+func (w Wave) HasTrigger() bool {
+	return w.TriggerChip > 0 && w.TriggerReaderID.Valid
+}
+
 func (w Wave) String() string {
 	return fmt.Sprintf(
 		"Wave {\n"+
@@ -44,6 +85,8 @@ func (w Wave) String() string {
 			"  Name: %q\n"+
 			"  StartTime: %s\n"+
 			"  IsLaunched: %t\n"+
+			"  TriggerChip: %d\n"+
+			"  AutoStart: %t\n"+
 			"}",
 		w.ID,
 		w.RaceID,
@@ -51,5 +94,7 @@ func (w Wave) String() string {
 		w.Name,
 		w.StartTime.Format(time.DateTime),
 		w.IsLaunched,
+		w.TriggerChip,
+		w.AutoStart,
 	)
 }
@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WaveAction is manual operation on wave start recorded in wave audit
+type WaveAction string
+
+const (
+	WaveActionLaunch WaveAction = "launch"
+	// WaveActionReset returns launched wave to not launched, e.g. after false start
+	WaveActionReset WaveAction = "reset"
+	// WaveActionAdjust corrects start time of launched wave
+	WaveActionAdjust WaveAction = "adjust"
+)
+
+// ErrWaveChanged is returned when wave is launched or reset concurrently with another change of it
+var ErrWaveChanged = errors.New("wave has been changed meanwhile")
+
+// WaveAuditEntry records manual change of wave start
+type WaveAuditEntry struct {
+	ID           int64      `json:"id"`
+	RaceID       uuid.UUID  `json:"race_id"`
+	WaveID       uuid.UUID  `json:"wave_id"`
+	Action       WaveAction `json:"action"`
+	OldStartTime time.Time  `json:"old_start_time"`
+	NewStartTime time.Time  `json:"new_start_time"`
+	WasLaunched  bool       `json:"was_launched"`
+	IsLaunched   bool       `json:"is_launched"`
+	Reason       string     `json:"reason"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// NewWaveAuditEntry describes applying action to wave w. Reset keeps start time of the wave.
+func NewWaveAuditEntry(w *Wave, action WaveAction, startTime time.Time, reason string) *WaveAuditEntry {
+	e := &WaveAuditEntry{
+		RaceID:       w.RaceID,
+		WaveID:       w.ID,
+		Action:       action,
+		OldStartTime: w.StartTime,
+		NewStartTime: startTime,
+		WasLaunched:  w.IsLaunched,
+		IsLaunched:   true,
+		Reason:       reason,
+	}
+	if action == WaveActionReset {
+		e.NewStartTime = w.StartTime
+		e.IsLaunched = false
+	}
+	return e
+}
+
+// WavesLaunch launches all listed waves at StartTime, at current time of the race if not set
+type WavesLaunch struct {
+	WaveIDs   []uuid.UUID `json:"wave_ids"`
+	StartTime time.Time   `json:"start_time"`
+	Reason    string      `json:"reason"`
+}
+
+// WaveReset undoes launch of the wave
+type WaveReset struct {
+	Reason string `json:"reason"`
+}
+
+// WaveAdjust corrects start time of launched wave
+type WaveAdjust struct {
+	StartTime time.Time `json:"start_time"`
+	Reason    string    `json:"reason"`
+}
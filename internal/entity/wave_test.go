@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWaveTrigger(t *testing.T) {
+	reader := &dto.TimeReaderDTO{ID: uuid.New(), ReaderName: "start"}
+	trs := []*dto.TimeReaderDTO{reader}
+	newDTO := func(chip int, readerID uuid.NullUUID) *dto.WaveDTO {
+		return &dto.WaveDTO{
+			ID:              uuid.New(),
+			Name:            "wave 1",
+			StartTime:       "2025-05-20T09:00:00Z",
+			TriggerChip:     chip,
+			TriggerReaderID: readerID,
+		}
+	}
+
+	t.Run("trigger chip and reader", func(t *testing.T) {
+		v := validator.New()
+		w := NewWave(newDTO(9999, uuid.NullUUID{UUID: reader.ID, Valid: true}), trs, time.UTC, v)
+		assert.True(t, v.Valid())
+		assert.True(t, w.HasTrigger())
+	})
+
+	t.Run("no trigger", func(t *testing.T) {
+		v := validator.New()
+		w := NewWave(newDTO(0, uuid.NullUUID{}), trs, time.UTC, v)
+		assert.True(t, v.Valid())
+		assert.False(t, w.HasTrigger())
+	})
+
+	t.Run("chip without reader", func(t *testing.T) {
+		v := validator.New()
+		assert.Nil(t, NewWave(newDTO(9999, uuid.NullUUID{}), trs, time.UTC, v))
+		assert.Contains(t, v.Errors, "trigger_reader_id")
+	})
+
+	t.Run("unknown reader", func(t *testing.T) {
+		v := validator.New()
+		assert.Nil(t, NewWave(newDTO(9999, uuid.NullUUID{UUID: uuid.New(), Valid: true}), trs, time.UTC, v))
+		assert.Equal(t, validator.CodeNotFound, v.FieldErrors()[0].Code)
+	})
+}
+
+func TestNewWaveStartInRaceTimezone(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	v := validator.New()
+	w := NewWave(&dto.WaveDTO{ID: uuid.New(), Name: "wave 1", StartTime: "2025-05-20T09:00:00Z"}, nil, loc, v)
+	assert.True(t, v.Valid())
+	// 09:00 of race wall clock is 07:00 UTC in summer time of Berlin
+	assert.Equal(t, time.Date(2025, 5, 20, 7, 0, 0, 0, time.UTC), w.StartTime.UTC())
+
+	// rendered start time is read back as the same instant
+	got := NewWave(w.DTO(), nil, loc, v)
+	assert.True(t, v.Valid())
+	assert.Equal(t, "2025-05-20T09:00:00+02:00", w.DTO().StartTime)
+	assert.True(t, w.StartTime.Equal(got.StartTime))
+}
+
+func TestNewWaveAuditEntry(t *testing.T) {
+	start := time.Date(2025, 5, 20, 9, 0, 0, 0, time.UTC)
+	w := &Wave{ID: uuid.New(), RaceID: uuid.New(), StartTime: start, IsLaunched: true}
+
+	t.Run("reset keeps start time", func(t *testing.T) {
+		e := NewWaveAuditEntry(w, WaveActionReset, time.Time{}, "false start")
+		assert.Equal(t, start, e.NewStartTime)
+		assert.True(t, e.WasLaunched)
+		assert.False(t, e.IsLaunched)
+	})
+
+	t.Run("adjust sets new start time", func(t *testing.T) {
+		e := NewWaveAuditEntry(w, WaveActionAdjust, start.Add(3*time.Second), "gun delay")
+		assert.Equal(t, start, e.OldStartTime)
+		assert.Equal(t, start.Add(3*time.Second), e.NewStartTime)
+		assert.True(t, e.IsLaunched)
+	})
+}
@@ -2,7 +2,10 @@ package repo
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ecoarchie/timeit/internal/database"
 	"github.com/ecoarchie/timeit/internal/entity"
@@ -34,9 +37,24 @@ type AthleteQuery interface {
 	CreateAthleteBulk(ctx context.Context, arg []database.CreateAthleteBulkParams) (int64, error)
 	AddChipBibBulk(ctx context.Context, arg []database.AddChipBibBulkParams) (int64, error)
 	AddEventAthleteBulk(ctx context.Context, arg []database.AddEventAthleteBulkParams) (int64, error)
+	AddAthleteCategoryBulk(ctx context.Context, arg []database.AddAthleteCategoryBulkParams) (int64, error)
+	DeleteAthleteCategories(ctx context.Context, athleteID uuid.UUID) error
+	GetAthleteCategories(ctx context.Context, athleteID uuid.UUID) ([]uuid.UUID, error)
+	GetCategoryResults(ctx context.Context, arg database.GetCategoryResultsParams) ([]database.GetCategoryResultsRow, error)
+	GetStartList(ctx context.Context, arg database.GetStartListParams) ([]database.GetStartListRow, error)
+	SetStartTimeBulk(ctx context.Context, arg database.SetStartTimeBulkParams) error
 	GetSplitsForRace(ctx context.Context, raceID uuid.UUID) ([]database.Split, error)
 	GetManualAthleteSplits(ctx context.Context, arg database.GetManualAthleteSplitsParams) ([]database.GetManualAthleteSplitsRow, error)
 	SetStatus(ctx context.Context, arg database.SetStatusParams) error
+	SetStatusBulk(ctx context.Context, arg database.SetStatusBulkParams) error
+	OverrideStatus(ctx context.Context, arg database.OverrideStatusParams) (int64, error)
+	GetFinishResultsForEvent(ctx context.Context, arg database.GetFinishResultsForEventParams) ([]database.GetFinishResultsForEventRow, error)
+	GetEventAthleteProgress(ctx context.Context, arg database.GetEventAthleteProgressParams) ([]database.GetEventAthleteProgressRow, error)
+	GetAthleteReaderRecords(ctx context.Context, arg database.GetAthleteReaderRecordsParams) ([]database.GetAthleteReaderRecordsRow, error)
+	GetChipsWithNewRecords(ctx context.Context, arg database.GetChipsWithNewRecordsParams) ([]int32, error)
+	GetReadsSnapshotXmin(ctx context.Context) (int64, error)
+	GetResultsWatermark(ctx context.Context, raceID uuid.UUID) (int64, error)
+	SetResultsWatermark(ctx context.Context, arg database.SetResultsWatermarkParams) error
 	WithTx(tx pgx.Tx) *database.Queries
 }
 
@@ -67,6 +85,7 @@ func (ar *AthleteRepoPG) SaveAthleteBulk(ctx context.Context, raceID uuid.UUID,
 	createPms := make([]database.CreateAthleteBulkParams, 0, len(athletes))
 	chipBibPms := make([]database.AddChipBibBulkParams, 0, len(athletes)) // FIXME if athlete has more than 1 chip, this must be rewritten
 	eventAthletePms := make([]database.AddEventAthleteBulkParams, 0, len(athletes))
+	var athleteCategoryPms []database.AddAthleteCategoryBulkParams
 	for _, a := range athletes {
 		ap := database.CreateAthleteBulkParams{
 			ID:              a.ID,
@@ -89,14 +108,18 @@ func (ar *AthleteRepoPG) SaveAthleteBulk(ctx context.Context, raceID uuid.UUID,
 		chipBibPms = append(chipBibPms, cb)
 
 		ea := database.AddEventAthleteBulkParams{
-			RaceID:     a.RaceID,
-			EventID:    a.EventID,
-			AthleteID:  a.ID,
-			WaveID:     a.WaveID,
-			CategoryID: a.CategoryID,
-			Bib:        int32(a.Bib),
+			RaceID:         a.RaceID,
+			EventID:        a.EventID,
+			AthleteID:      a.ID,
+			WaveID:         a.WaveID,
+			CategoryID:     a.CategoryID,
+			Bib:            int32(a.Bib),
+			CategoryLocked: a.CategoryLocked,
+			StartTime:      startTimeToPgxTimestamptz(a.StartTime),
+			Handicap:       handicapToPgxInterval(a.Handicap),
 		}
 		eventAthletePms = append(eventAthletePms, ea)
+		athleteCategoryPms = append(athleteCategoryPms, athleteCategoriesParams(a)...)
 	}
 
 	tx, err := ar.pg.Pool.Begin(ctx)
@@ -106,6 +129,10 @@ func (ar *AthleteRepoPG) SaveAthleteBulk(ctx context.Context, raceID uuid.UUID,
 	defer tx.Rollback(ctx)
 	qtx := ar.WithTx(tx)
 
+	_, err = tx.Exec(ctx, lockRaceConfigShared, raceID)
+	if err != nil {
+		return 0, fmt.Errorf("save athletes: lock race config: %w", err)
+	}
 	err = qtx.q.DeleteChipBibWithRaceID(ctx, raceID)
 	if err != nil {
 		return 0, fmt.Errorf("error deleting chipbib for race = %s", raceID)
@@ -131,6 +158,11 @@ func (ar *AthleteRepoPG) SaveAthleteBulk(ctx context.Context, raceID uuid.UUID,
 		return 0, fmt.Errorf("save athlete bulk: error creating event-athlete record")
 	}
 
+	_, err = qtx.q.AddAthleteCategoryBulk(ctx, athleteCategoryPms)
+	if err != nil {
+		return 0, fmt.Errorf("save athlete bulk: error creating athlete categories")
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("save athlete bulk: transaction commit error")
@@ -146,6 +178,11 @@ func (ar *AthleteRepoPG) SaveAthlete(ctx context.Context, p *entity.Athlete) err
 	}
 	defer tx.Rollback(ctx)
 	qtx := ar.WithTx(tx)
+
+	_, err = tx.Exec(ctx, lockRaceConfigShared, p.RaceID)
+	if err != nil {
+		return fmt.Errorf("save athlete: lock race config: %w", err)
+	}
 	aParams := database.CreateOrUpdateAthleteParams{
 		ID:              p.ID,
 		RaceID:          p.RaceID,
@@ -173,21 +210,91 @@ func (ar *AthleteRepoPG) SaveAthlete(ctx context.Context, p *entity.Athlete) err
 	}
 
 	eaParams := database.AddEventAthleteParams{
-		RaceID:     p.RaceID,
-		EventID:    p.EventID,
-		AthleteID:  p.ID,
-		WaveID:     p.WaveID,
-		CategoryID: p.CategoryID,
-		Bib:        int32(p.Bib),
+		RaceID:         p.RaceID,
+		EventID:        p.EventID,
+		AthleteID:      p.ID,
+		WaveID:         p.WaveID,
+		CategoryID:     p.CategoryID,
+		Bib:            int32(p.Bib),
+		CategoryLocked: p.CategoryLocked,
+		StartTime:      startTimeToPgxTimestamptz(p.StartTime),
+		Handicap:       handicapToPgxInterval(p.Handicap),
 	}
 
 	_, err = qtx.q.AddEventAthlete(ctx, eaParams)
 	if err != nil {
 		return err
 	}
+
+	err = qtx.q.DeleteAthleteCategories(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	_, err = qtx.q.AddAthleteCategoryBulk(ctx, athleteCategoriesParams(p))
+	if err != nil {
+		return err
+	}
 	return tx.Commit(ctx)
 }
 
+// SetAthleteCategories replaces custom categories athlete is member of
+func (ar *AthleteRepoPG) SetAthleteCategories(ctx context.Context, a *entity.Athlete) error {
+	tx, err := ar.pg.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := ar.WithTx(tx)
+
+	err = qtx.q.DeleteAthleteCategories(ctx, a.ID)
+	if err != nil {
+		return err
+	}
+	_, err = qtx.q.AddAthleteCategoryBulk(ctx, athleteCategoriesParams(a))
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// startTimeToPgxTimestamptz maps zero start time to NULL meaning athlete starts with the wave
+func startTimeToPgxTimestamptz(t time.Time) pgtype.Timestamptz {
+	if t.IsZero() {
+		return pgtype.Timestamptz{}
+	}
+	return pgxmapper.TimeToPgxTimestamptz(t)
+}
+
+// handicapToPgxInterval maps handicap which is not set to NULL so that handicap of athlete's category applies,
+// zero handicap overrides the category one
+func handicapToPgxInterval(d *time.Duration) pgtype.Interval {
+	if d == nil {
+		return pgtype.Interval{}
+	}
+	return pgxmapper.DurationToPgxInterval(*d)
+}
+
+func pgxIntervalToHandicap(i pgtype.Interval) *time.Duration {
+	if !i.Valid {
+		return nil
+	}
+	d := pgxmapper.PgxIntervalToDuration(i)
+	return &d
+}
+
+func athleteCategoriesParams(a *entity.Athlete) []database.AddAthleteCategoryBulkParams {
+	params := make([]database.AddAthleteCategoryBulkParams, 0, len(a.Categories))
+	for _, c := range a.Categories {
+		params = append(params, database.AddAthleteCategoryBulkParams{
+			RaceID:     a.RaceID,
+			EventID:    a.EventID,
+			AthleteID:  a.ID,
+			CategoryID: c,
+		})
+	}
+	return params
+}
+
 func (ar *AthleteRepoPG) GetEventIDsWithWavesStarted(ctx context.Context, raceID uuid.UUID) ([]uuid.UUID, error) {
 	return ar.q.GetEventIDsWithWavesStarted(ctx, raceID)
 }
@@ -219,23 +326,33 @@ func (ar *AthleteRepoPG) GetAthleteWithChip(chip int) (*entity.Athlete, error) {
 func (ar *AthleteRepoPG) GetAthleteByID(ctx context.Context, athleteID uuid.UUID) (*entity.Athlete, error) {
 	a, err := ar.q.GetAthleteByID(ctx, athleteID)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
 	athlete := &entity.Athlete{
-		ID:          a.ID,
-		RaceID:      a.RaceID,
-		EventID:     a.EventID,
-		WaveID:      a.WaveID,
-		Bib:         int(a.Bib),
-		Chip:        int(a.Chip),
-		FirstName:   a.FirstName.String,
-		LastName:    a.LastName.String,
-		Gender:      entity.CategoryGender(a.Gender),
-		DateOfBirth: a.DateOfBirth.Time,
-		CategoryID:  a.CategoryID,
-		Phone:       a.Phone.String,
-		Comments:    a.AthleteComments.String,
+		ID:             a.ID,
+		RaceID:         a.RaceID,
+		EventID:        a.EventID,
+		WaveID:         a.WaveID,
+		Bib:            int(a.Bib),
+		Chip:           int(a.Chip),
+		FirstName:      a.FirstName.String,
+		LastName:       a.LastName.String,
+		Gender:         entity.CategoryGender(a.Gender),
+		DateOfBirth:    a.DateOfBirth.Time,
+		CategoryID:     a.CategoryID,
+		CategoryLocked: a.CategoryLocked,
+		StartTime:      a.StartTime.Time,
+		Handicap:       pgxIntervalToHandicap(a.Handicap),
+		Phone:          a.Phone.String,
+		Comments:       a.AthleteComments.String,
+	}
+	athlete.Categories, err = ar.q.GetAthleteCategories(ctx, a.ID)
+	if err != nil {
+		return nil, err
 	}
 	return athlete, nil
 }
@@ -331,6 +448,9 @@ func (ar *AthleteRepoPG) SaveAthleteSplits(ctx context.Context, as []database.Cr
 	return nil
 }
 
+// lockRaceResults serializes writing of results of the same race between app instances until transaction ends
+const lockRaceResults = `select pg_advisory_xact_lock(hashtextextended($1::text, 0))`
+
 const tempTableCreate = `
 	CREATE TEMPORARY TABLE athlete_split_tmp (
 		LIKE athlete_split INCLUDING ALL, visited BOOLEAN
@@ -400,17 +520,64 @@ const mergeWithRanks = `
 		values (ats.race_id, ats.event_id, ats.split_id, ats.athlete_id, ats.tod, ats.gun_time, ats.net_time, ats.gun_rank_gender, ats.gun_rank_category, ats.gun_rank_overall, ats.net_rank_gender, ats.net_rank_category, ats.net_rank_overall)
 `
 
-func (ar *AthleteRepoPG) SaveBulkAthleteSplits(ctx context.Context, raceID uuid.UUID, as []*entity.AthleteSplit) error {
+// deleteCategoryRanks removes ranks in custom categories at splits present in athlete_split_tmp
+const deleteCategoryRanks = `
+	delete from athlete_category_rank acr
+	where (acr.race_id, acr.event_id, acr.split_id) in (
+		select distinct race_id, event_id, split_id from athlete_split_tmp
+	)
+`
+
+// insertCategoryRanks ranks athletes in each custom category they are member of at splits present in athlete_split_tmp
+const insertCategoryRanks = `
+	insert into athlete_category_rank
+		(race_id, event_id, split_id, athlete_id, category_id, gun_rank, net_rank)
+	select
+		ats.race_id,
+		ats.event_id,
+		ats.split_id,
+		ats.athlete_id,
+		ac.category_id,
+		RANK() OVER (PARTITION BY ats.race_id, ats.event_id, ats.split_id, ac.category_id ORDER BY ats.gun_time ASC) AS gun_rank,
+		RANK() OVER (PARTITION BY ats.race_id, ats.event_id, ats.split_id, ac.category_id ORDER BY ats.net_time ASC) AS net_rank
+	from athlete_split ats
+	join athlete_category ac on ac.athlete_id = ats.athlete_id and ac.race_id = ats.race_id and ac.event_id = ats.event_id
+	join event_athlete ea on ea.athlete_id = ats.athlete_id and ea.race_id = ats.race_id and ea.event_id = ats.event_id
+	where ea.status_id IN (2, 3)
+		and (ats.race_id, ats.event_id, ats.split_id) in (
+			select distinct race_id, event_id, split_id from athlete_split_tmp
+		)
+`
+
+// refreshCategoryRanks recalculates ranks in custom categories, must run after athlete_split is merged
+func refreshCategoryRanks(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, deleteCategoryRanks)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, insertCategoryRanks)
+	return err
+}
+
+func (ar *AthleteRepoPG) SaveBulkAthleteSplits(ctx context.Context, raceID uuid.UUID, as []*entity.AthleteSplit, ss []*entity.AthleteStatus) error {
 	tx, err := ar.pg.Pool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
+	_, err = tx.Exec(ctx, lockRaceResults, raceID)
+	if err != nil {
+		return fmt.Errorf("save bulk athlete splits: lock race results: %w", err)
+	}
+	// statuses are saved first since ranks are calculated for running and finished athletes only
+	err = ar.WithTx(tx).UpdateStatuses(ctx, raceID, ss)
+	if err != nil {
+		return fmt.Errorf("save bulk athlete splits: %w", err)
+	}
 	_, err = tx.Exec(ctx, tempTableCreate)
 	if err != nil {
-		fmt.Println("Error executing creation temp table: ", err)
-		return err
+		return fmt.Errorf("save bulk athlete splits: create temp table: %w", err)
 	}
 
 	var linkedParams [][]interface{}
@@ -421,21 +588,179 @@ func (ar *AthleteRepoPG) SaveBulkAthleteSplits(ctx context.Context, raceID uuid.
 	}
 	_, err = tx.CopyFrom(ctx, []string{"athlete_split_tmp"}, []string{"race_id", "event_id", "split_id", "athlete_id", "tod", "gun_time", "net_time", "visited"}, pgx.CopyFromRows(linkedParams))
 	if err != nil {
-		fmt.Println("Error executing copyfrom athlete splits: ", err)
-		return err
+		return fmt.Errorf("save bulk athlete splits: copy athlete splits: %w", err)
 	}
 
 	_, err = tx.Exec(ctx, mergeWithRanks)
 	if err != nil {
-		fmt.Println("Error executing rank query: ", err)
-		return err
+		return fmt.Errorf("save bulk athlete splits: merge with ranks: %w", err)
 	}
-	err = tx.Commit(ctx)
+	err = refreshCategoryRanks(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("refresh category ranks: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+const mergeWithoutRanks = `
+	merge into athlete_split asl
+	using athlete_split_tmp ats
+	on asl.race_id = ats.race_id and asl.event_id = ats.event_id and asl.split_id = ats.split_id and asl.athlete_id = ats.athlete_id
+	when matched and ats.visited is FALSE then
+		DELETE
+	when matched then update set
+		tod = ats.tod,
+		gun_time = ats.gun_time,
+		net_time = ats.net_time
+	when not matched and ats.visited is FALSE then DO NOTHING
+	when not matched then insert
+		(race_id, event_id, split_id, athlete_id, tod, gun_time, net_time)
+		values (ats.race_id, ats.event_id, ats.split_id, ats.athlete_id, ats.tod, ats.gun_time, ats.net_time)
+`
+
+// refreshRanks recalculates ranks for all athletes at splits present in athlete_split_tmp
+const refreshRanks = `
+	update athlete_split asl set
+		gun_rank_gender = r.gun_rank_gender,
+		gun_rank_category = r.gun_rank_category,
+		gun_rank_overall = r.gun_rank_overall,
+		net_rank_gender = r.net_rank_gender,
+		net_rank_category = r.net_rank_category,
+		net_rank_overall = r.net_rank_overall
+	from (
+		select
+			ats.race_id,
+			ats.event_id,
+			ats.split_id,
+			ats.athlete_id,
+			CASE
+				WHEN ss.status_id IN (2, 3) and a.gender <> 'unknown' THEN
+				RANK() OVER (PARTITION BY ats.race_id, ats.event_id, ats.split_id, a.gender ORDER BY ats.gun_time ASC)
+			END AS gun_rank_gender,
+			CASE
+				WHEN ea.category_id IS NOT NULL AND ss.status_id IN (2, 3) THEN
+				RANK() OVER (PARTITION BY ats.race_id, ats.event_id, ats.split_id, ea.category_id ORDER BY ats.gun_time ASC)
+			END AS gun_rank_category,
+			CASE
+				WHEN ss.status_id IN (2, 3) THEN
+				RANK() OVER (PARTITION BY ats.race_id, ats.event_id, ats.split_id ORDER BY ats.gun_time ASC)
+			END AS gun_rank_overall,
+			CASE
+				WHEN ss.status_id IN (2, 3) and a.gender <> 'unknown' THEN
+				RANK() OVER (PARTITION BY ats.race_id, ats.event_id, ats.split_id, a.gender ORDER BY ats.net_time ASC)
+			END AS net_rank_gender,
+			CASE
+				WHEN ea.category_id IS NOT NULL AND ss.status_id IN (2, 3) THEN
+				RANK() OVER (PARTITION BY ats.race_id, ats.event_id, ats.split_id, ea.category_id ORDER BY ats.net_time ASC)
+			END AS net_rank_category,
+			CASE
+				WHEN ss.status_id IN (2, 3) THEN
+				RANK() OVER (PARTITION BY ats.race_id, ats.event_id, ats.split_id ORDER BY ats.net_time ASC)
+			END AS net_rank_overall
+		from athlete_split ats
+		join athletes a on a.id = ats.athlete_id
+		join event_athlete ea on ea.athlete_id = ats.athlete_id and ea.race_id = ats.race_id and ea.event_id = ats.event_id
+		join statuses ss on ea.status_id = ss.status_id
+		where (ats.race_id, ats.event_id, ats.split_id) in (
+			select distinct race_id, event_id, split_id from athlete_split_tmp
+		)
+	) r
+	where asl.race_id = r.race_id and asl.event_id = r.event_id and asl.split_id = r.split_id and asl.athlete_id = r.athlete_id
+`
+
+// UpsertAthleteSplits saves splits and changed statuses of some athletes only and refreshes ranks
+// of all athletes at the affected splits.
+func (ar *AthleteRepoPG) UpsertAthleteSplits(ctx context.Context, raceID uuid.UUID, as []*entity.AthleteSplit, ss []*entity.AthleteStatus) error {
+	tx, err := ar.pg.Pool.Begin(ctx)
 	if err != nil {
-		fmt.Println("Error commiting transaction: ", err)
 		return err
 	}
-	return nil
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, lockRaceResults, raceID)
+	if err != nil {
+		return fmt.Errorf("upsert athlete splits: lock race results: %w", err)
+	}
+	err = ar.WithTx(tx).UpdateStatuses(ctx, raceID, ss)
+	if err != nil {
+		return fmt.Errorf("upsert athlete splits: %w", err)
+	}
+	_, err = tx.Exec(ctx, tempTableCreate)
+	if err != nil {
+		return fmt.Errorf("upsert athlete splits: create temp table: %w", err)
+	}
+
+	var linkedParams [][]interface{}
+	for _, p := range as {
+		if p != nil {
+			linkedParams = append(linkedParams, []interface{}{p.RaceID, p.EventID, p.SplitID, p.AthleteID, p.TOD, p.GunTime, p.NetTime, p.IsVisited()})
+		}
+	}
+	_, err = tx.CopyFrom(ctx, []string{"athlete_split_tmp"}, []string{"race_id", "event_id", "split_id", "athlete_id", "tod", "gun_time", "net_time", "visited"}, pgx.CopyFromRows(linkedParams))
+	if err != nil {
+		return fmt.Errorf("upsert athlete splits: copy to temp table: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, mergeWithoutRanks)
+	if err != nil {
+		return fmt.Errorf("upsert athlete splits: merge: %w", err)
+	}
+	_, err = tx.Exec(ctx, refreshRanks)
+	if err != nil {
+		return fmt.Errorf("upsert athlete splits: refresh ranks: %w", err)
+	}
+	err = refreshCategoryRanks(ctx, tx)
+	if err != nil {
+		return fmt.Errorf("upsert athlete splits: refresh category ranks: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetChipsWithNewRecords returns chips having reads added by transactions not finished by the time of results
+// watermark of the race, and the snapshot to be the next watermark. Reads are not found by serial IDs since
+// a read with lower ID may be committed after the one with higher ID. If results have never been calculated
+// for the race watermark is not valid.
+func (ar *AthleteRepoPG) GetChipsWithNewRecords(ctx context.Context, raceID uuid.UUID) (chips []int, snapshot int64, watermarkValid bool, err error) {
+	watermark, err := ar.q.GetResultsWatermark(ctx, raceID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, false, err
+	}
+	watermarkValid = err == nil
+
+	// taken before reads are queried, so reads committed after the query are found next time
+	snapshot, err = ar.q.GetReadsSnapshotXmin(ctx)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if !watermarkValid {
+		return nil, snapshot, false, nil
+	}
+	params := database.GetChipsWithNewRecordsParams{
+		RaceID: raceID,
+		Xid:    watermark,
+	}
+	cc, err := ar.q.GetChipsWithNewRecords(ctx, params)
+	if err != nil {
+		return nil, 0, false, err
+	}
+	chips = make([]int, 0, len(cc))
+	for _, c := range cc {
+		chips = append(chips, int(c))
+	}
+	return chips, snapshot, true, nil
+}
+
+// GetReadsSnapshot returns the oldest transaction still running, reads of all transactions before it are visible
+func (ar *AthleteRepoPG) GetReadsSnapshot(ctx context.Context) (int64, error) {
+	return ar.q.GetReadsSnapshotXmin(ctx)
+}
+
+func (ar *AthleteRepoPG) SetResultsWatermark(ctx context.Context, raceID uuid.UUID, snapshot int64) error {
+	params := database.SetResultsWatermarkParams{
+		RaceID:       raceID,
+		SnapshotXmin: snapshot,
+	}
+	return ar.q.SetResultsWatermark(ctx, params)
 }
 
 func (ar *AthleteRepoPG) GetManualAthleteSplits(ctx context.Context, raceID, eventID uuid.UUID) (map[uuid.UUID][]*entity.AthleteSplit, error) {
@@ -454,7 +779,7 @@ func (ar *AthleteRepoPG) GetManualAthleteSplits(ctx context.Context, raceID, eve
 			EventID:    m.EventID,
 			AthleteID:  m.AthleteID,
 			SplitID:    m.SplitID,
-			TOD:        pgxmapper.PgxTimestampToTime(m.Tod),
+			TOD:        pgxmapper.PgxTimestamptzToTime(m.Tod),
 			GunTime:    pgxmapper.PgxIntervalToDuration(m.GunTime),
 			NetTime:    pgxmapper.PgxIntervalToDuration(m.NetTime),
 			Gender:     entity.CategoryGender(m.Gender),
@@ -464,6 +789,95 @@ func (ar *AthleteRepoPG) GetManualAthleteSplits(ctx context.Context, raceID, eve
 	return res, nil
 }
 
+func (ar *AthleteRepoPG) GetFinishResultsForEvent(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.FinishResult, error) {
+	params := database.GetFinishResultsForEventParams{
+		RaceID:  raceID,
+		EventID: eventID,
+	}
+	rows, err := ar.q.GetFinishResultsForEvent(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("get finish results for event: %w", err)
+	}
+	res := make([]*entity.FinishResult, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, &entity.FinishResult{
+			AthleteID:   r.AthleteID,
+			Bib:         int(r.Bib),
+			FirstName:   r.FirstName.String,
+			LastName:    r.LastName.String,
+			Gender:      entity.CategoryGender(r.Gender),
+			DateOfBirth: r.DateOfBirth.Time,
+			GunTime:     pgxmapper.PgxIntervalToDuration(r.GunTime),
+			NetTime:     pgxmapper.PgxIntervalToDuration(r.NetTime),
+			Handicap:    pgxmapper.PgxIntervalToDuration(r.Handicap),
+		})
+	}
+	return res, nil
+}
+
+func (ar *AthleteRepoPG) GetEventAthleteProgress(ctx context.Context, raceID, eventID uuid.UUID, status entity.Status) ([]*entity.AthleteProgress, error) {
+	params := database.GetEventAthleteProgressParams{
+		RaceID:     raceID,
+		EventID:    eventID,
+		StatusFull: string(status),
+	}
+	rows, err := ar.q.GetEventAthleteProgress(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("get event athlete progress: %w", err)
+	}
+	var res []*entity.AthleteProgress
+	var current *entity.AthleteProgress
+	for _, r := range rows {
+		// rows are ordered by athlete so all splits of athlete go one after another
+		if current == nil || current.AthleteID != r.AthleteID {
+			current = &entity.AthleteProgress{
+				AthleteID: r.AthleteID,
+				EventID:   eventID,
+				Bib:       int(r.Bib),
+				FirstName: r.FirstName.String,
+				LastName:  r.LastName.String,
+				Phone:     r.Phone.String,
+				Status:    entity.Status(r.StatusFull),
+				WaveStart: pgxmapper.PgxTimestamptzToTime(r.WaveStart),
+			}
+			res = append(res, current)
+		}
+		current.Visited = append(current.Visited, &entity.AthleteSplit{
+			RaceID:    raceID,
+			EventID:   eventID,
+			AthleteID: r.AthleteID,
+			SplitID:   r.SplitID,
+			TOD:       pgxmapper.PgxTimestamptzToTime(r.Tod),
+			GunTime:   pgxmapper.PgxIntervalToDuration(r.GunTime),
+			NetTime:   pgxmapper.PgxIntervalToDuration(r.NetTime),
+		})
+	}
+	return res, nil
+}
+
+func (ar *AthleteRepoPG) GetAthleteReaderRecords(ctx context.Context, raceID uuid.UUID, chip int) ([]*entity.ReadDiagnostic, error) {
+	params := database.GetAthleteReaderRecordsParams{
+		RaceID: raceID,
+		Chip:   int32(chip),
+	}
+	rows, err := ar.q.GetAthleteReaderRecords(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*entity.ReadDiagnostic, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, &entity.ReadDiagnostic{
+			ID:           int(r.ID),
+			TOD:          r.Tod.Time,
+			ReaderName:   r.ReaderName,
+			TimeReaderID: r.TimeReaderID,
+			CanUse:       r.CanUse,
+			Candidates:   []*entity.ReadDecision{},
+		})
+	}
+	return res, nil
+}
+
 func (ar *AthleteRepoPG) GetAthleteSplitResults(ctx context.Context, raceID uuid.UUID) error {
 	splits, err := ar.q.GetSplitsForRace(ctx, raceID)
 	if err != nil {
@@ -481,7 +895,9 @@ func (ar *AthleteRepoPG) GetAthleteSplitResults(ctx context.Context, raceID uuid
 	return nil
 }
 
-func (ar *AthleteRepoPG) GetRecordsAndSplitsForEventAthlete(ctx context.Context, raceID, eventID uuid.UUID) ([]database.GetEventAthleteRecordsCRow, []*entity.Split, error) {
+// GetRecordsAndSplitsForEventAthlete returns reads of athletes from launched waves of the event.
+// If chips are provided only athletes with these chips are returned.
+func (ar *AthleteRepoPG) GetRecordsAndSplitsForEventAthlete(ctx context.Context, raceID, eventID uuid.UUID, chips []int) ([]database.GetEventAthleteRecordsCRow, []*entity.Split, error) {
 	ss, err := ar.q.GetSplitsForEvent(ctx, eventID)
 	if err != nil {
 		return nil, nil, err
@@ -491,6 +907,12 @@ func (ar *AthleteRepoPG) GetRecordsAndSplitsForEventAthlete(ctx context.Context,
 		RaceID:  raceID,
 		EventID: eventID,
 	}
+	if chips != nil {
+		eaParams.Chips = make([]int32, 0, len(chips))
+		for _, c := range chips {
+			eaParams.Chips = append(eaParams.Chips, int32(c))
+		}
+	}
 	records, err := ar.q.GetEventAthleteRecordsC(ctx, eaParams)
 	if err != nil {
 		return nil, nil, err
@@ -510,6 +932,8 @@ func (ar *AthleteRepoPG) GetRecordsAndSplitsForEventAthlete(ctx context.Context,
 			MaxTime:            pgxmapper.PgxIntervalToDuration(s.MaxTime),
 			MinLapTime:         pgxmapper.PgxIntervalToDuration(s.MinLapTime),
 			PreviousLapSplitID: s.PreviousLapSplitID,
+			CutoffTime:         pgxmapper.PgxIntervalToDuration(s.CutoffTime),
+			CutoffTOD:          s.CutoffTod.Time,
 		}
 		splits = append(splits, split)
 	}
@@ -517,7 +941,66 @@ func (ar *AthleteRepoPG) GetRecordsAndSplitsForEventAthlete(ctx context.Context,
 	return records, splits, nil
 }
 
-func (ar *AthleteRepoPG) UpdateStatus(ctx context.Context, status entity.Status, raceID, eventID, athleteID uuid.UUID) error {
+func (ar *AthleteRepoPG) UpdateStatus(ctx context.Context, status entity.Status, reason string, raceID, eventID, athleteID uuid.UUID) error {
+	statusID := statusToPgxInt4(status)
+
+	sParam := database.SetStatusParams{
+		StatusID:     statusID,
+		StatusReason: reason,
+		AthleteID:    athleteID,
+		RaceID:       raceID,
+		EventID:      eventID,
+	}
+	err := ar.q.SetStatus(ctx, sParam)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateStatuses saves calculated statuses of many athletes in one statement, statuses locked by officials are kept
+func (ar *AthleteRepoPG) UpdateStatuses(ctx context.Context, raceID uuid.UUID, ss []*entity.AthleteStatus) error {
+	if len(ss) == 0 {
+		return nil
+	}
+	params := database.SetStatusBulkParams{
+		AthleteIds:    make([]uuid.UUID, 0, len(ss)),
+		EventIds:      make([]uuid.UUID, 0, len(ss)),
+		StatusIds:     make([]int32, 0, len(ss)),
+		StatusReasons: make([]string, 0, len(ss)),
+		RaceID:        raceID,
+	}
+	for _, st := range ss {
+		params.AthleteIds = append(params.AthleteIds, st.AthleteID)
+		params.EventIds = append(params.EventIds, st.EventID)
+		params.StatusIds = append(params.StatusIds, statusToPgxInt4(st.Status).Int32)
+		params.StatusReasons = append(params.StatusReasons, st.Reason)
+	}
+	err := ar.q.SetStatusBulk(ctx, params)
+	if err != nil {
+		return fmt.Errorf("update statuses: %w", err)
+	}
+	return nil
+}
+
+// OverrideStatus sets athlete's status regardless of lock, returns false if athlete is not registered for event
+func (ar *AthleteRepoPG) OverrideStatus(ctx context.Context, st *entity.AthleteStatus, raceID uuid.UUID) (bool, error) {
+	params := database.OverrideStatusParams{
+		StatusID:     statusToPgxInt4(st.Status),
+		StatusReason: st.Reason,
+		StatusLocked: st.Locked,
+		AthleteID:    st.AthleteID,
+		RaceID:       raceID,
+		EventID:      st.EventID,
+	}
+	n, err := ar.q.OverrideStatus(ctx, params)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func statusToPgxInt4(status entity.Status) pgtype.Int4 {
 	var statusID pgtype.Int4
 	switch status {
 	case entity.NYS:
@@ -562,15 +1045,90 @@ func (ar *AthleteRepoPG) UpdateStatus(ctx context.Context, status entity.Status,
 		}
 	}
 
-	sParam := database.SetStatusParams{
-		StatusID:  statusID,
-		AthleteID: athleteID,
-		RaceID:    raceID,
-		EventID:   eventID,
+	return statusID
+}
+
+// GetCategoryResults returns results at the split of athletes in age or custom category ranked within the category
+func (ar *AthleteRepoPG) GetCategoryResults(ctx context.Context, raceID, categoryID, splitID uuid.UUID) ([]*entity.CategoryResult, error) {
+	rows, err := ar.q.GetCategoryResults(ctx, database.GetCategoryResultsParams{
+		CategoryID: categoryID,
+		RaceID:     raceID,
+		SplitID:    splitID,
+	})
+	if err != nil {
+		return nil, err
 	}
-	err := ar.q.SetStatus(ctx, sParam)
+	res := make([]*entity.CategoryResult, 0, len(rows))
+	for _, r := range rows {
+		res = append(res, &entity.CategoryResult{
+			AthleteID: r.AthleteID,
+			Bib:       int(r.Bib),
+			FirstName: r.FirstName.String,
+			LastName:  r.LastName.String,
+			Status:    entity.Status(r.StatusFull),
+			TOD:       r.Tod.Time,
+			GunTime:   pgxmapper.PgxIntervalToDuration(r.GunTime),
+			NetTime:   pgxmapper.PgxIntervalToDuration(r.NetTime),
+			GunRank:   int(r.GunRank.Int32),
+			NetRank:   int(r.NetRank.Int32),
+		})
+	}
+	return res, nil
+}
+
+// GetStartList returns athletes of race, of event and wave if given, ordered by start time and bib
+func (ar *AthleteRepoPG) GetStartList(ctx context.Context, raceID uuid.UUID, eventID, waveID uuid.NullUUID) ([]*entity.StartListEntry, error) {
+	rows, err := ar.q.GetStartList(ctx, database.GetStartListParams{
+		RaceID:  raceID,
+		EventID: eventID,
+		WaveID:  waveID,
+	})
 	if err != nil {
-		return err
+		return nil, err
+	}
+	res := make([]*entity.StartListEntry, 0, len(rows))
+	for _, r := range rows {
+		e := &entity.StartListEntry{
+			AthleteID:  r.AthleteID,
+			EventID:    r.EventID,
+			WaveID:     r.WaveID,
+			Bib:        int(r.Bib),
+			FirstName:  r.FirstName.String,
+			LastName:   r.LastName.String,
+			EventName:  r.EventName,
+			WaveName:   r.WaveName,
+			StartTime:  r.WaveStart.Time,
+			Handicap:   pgxmapper.PgxIntervalToDuration(r.Handicap),
+			Individual: r.StartTime.Valid,
+		}
+		if r.StartTime.Valid {
+			e.StartTime = r.StartTime.Time
+		}
+		if !e.StartTime.IsZero() {
+			e.StartTime = e.StartTime.Add(e.Handicap)
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+// SetStartTimes saves individual start times of athletes in one statement
+func (ar *AthleteRepoPG) SetStartTimes(ctx context.Context, raceID uuid.UUID, st []*entity.AthleteStartTime) error {
+	if len(st) == 0 {
+		return nil
+	}
+	params := database.SetStartTimeBulkParams{
+		AthleteIds: make([]uuid.UUID, 0, len(st)),
+		StartTimes: make([]pgtype.Timestamptz, 0, len(st)),
+		RaceID:     raceID,
+	}
+	for _, s := range st {
+		params.AthleteIds = append(params.AthleteIds, s.AthleteID)
+		params.StartTimes = append(params.StartTimes, startTimeToPgxTimestamptz(s.StartTime))
+	}
+	err := ar.q.SetStartTimeBulk(ctx, params)
+	if err != nil {
+		return fmt.Errorf("set start times: %w", err)
 	}
 	return nil
 }
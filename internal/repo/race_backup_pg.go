@@ -0,0 +1,179 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ecoarchie/timeit/internal/database"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// BackupRace reads all rows of the race in a single snapshot. Returns nil if race is not found.
+func (rr *RaceRepoPG) BackupRace(ctx context.Context, raceID uuid.UUID) (*entity.RaceBackup, error) {
+	tx, err := rr.pg.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	q := rr.q.WithTx(tx)
+
+	races, err := q.GetBackupRace(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("backup races: %w", err)
+	}
+	if len(races) == 0 {
+		return nil, nil
+	}
+	b := entity.NewRaceBackup(&entity.Race{ID: races[0].ID, Name: races[0].RaceName})
+
+	statuses, err := q.GetBackupStatuses(ctx)
+	err = errors.Join(
+		backupRows(b, "statuses", statuses, err),
+		backupRows(b, "races", races, nil),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range []struct {
+		table string
+		rows  func(context.Context, uuid.UUID) (int, json.RawMessage, error)
+	}{
+		{"race_status_history", dumpRows(q.GetBackupRaceStatusHistory)},
+		{"time_readers", dumpRows(q.GetBackupTimeReaders)},
+		{"events", dumpRows(q.GetBackupEvents)},
+		{"splits", dumpRows(q.GetBackupSplits)},
+		{"waves", dumpRows(q.GetBackupWaves)},
+		{"wave_audit", dumpRows(q.GetBackupWaveAudit)},
+		{"categories", dumpRows(q.GetBackupCategories)},
+		{"athletes", dumpRows(q.GetBackupAthletes)},
+		{"event_athlete", dumpRows(q.GetBackupEventAthletes)},
+		{"chip_bib", dumpRows(q.GetBackupChipBibs)},
+		{"athlete_category", dumpRows(q.GetBackupAthleteCategories)},
+		{"reader_records", dumpRows(q.GetBackupReaderRecords)},
+		{"athlete_split", dumpRows(q.GetBackupAthleteSplits)},
+		{"athlete_category_rank", dumpRows(q.GetBackupAthleteCategoryRanks)},
+	} {
+		n, data, err := t.rows(ctx, raceID)
+		if err != nil {
+			return nil, fmt.Errorf("backup %s: %w", t.table, err)
+		}
+		b.Tables[t.table], b.Rows[t.table] = data, n
+	}
+	return b, nil
+}
+
+func dumpRows[T any](get func(context.Context, uuid.UUID) ([]T, error)) func(context.Context, uuid.UUID) (int, json.RawMessage, error) {
+	return func(ctx context.Context, raceID uuid.UUID) (int, json.RawMessage, error) {
+		rows, err := get(ctx, raceID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if rows == nil {
+			rows = []T{}
+		}
+		data, err := json.Marshal(rows)
+		return len(rows), data, err
+	}
+}
+
+func backupRows[T any](b *entity.RaceBackup, table string, rows []T, err error) error {
+	if err == nil {
+		b.Tables[table], err = json.Marshal(rows)
+		b.Rows[table] = len(rows)
+	}
+	if err != nil {
+		return fmt.Errorf("backup %s: %w", table, err)
+	}
+	return nil
+}
+
+// RestoreRace replaces all rows of the race with rows of backup b keeping their IDs. Reads, wave audit
+// and status history get new serial IDs in order of backup. Statuses missing in the database are added.
+// Returns ErrRaceBackupConflict if rows of backup are used by another race, e.g. name of the race.
+func (rr *RaceRepoPG) RestoreRace(ctx context.Context, b *entity.RaceBackup) error {
+	tx, err := rr.pg.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	q := rr.q.WithTx(tx)
+
+	err = q.DeleteRace(ctx, b.RaceID)
+	if err != nil {
+		return fmt.Errorf("delete race: %w", err)
+	}
+	err = q.DeleteReaderRecordsWithRaceID(ctx, b.RaceID)
+	if err != nil {
+		return fmt.Errorf("delete reader records: %w", err)
+	}
+
+	var statuses []database.RestoreStatusesParams
+	err = restoreRows(b, "statuses", &statuses)
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		err = q.RestoreStatuses(ctx, s)
+		if err != nil {
+			return fmt.Errorf("restore statuses: %w", err)
+		}
+	}
+	for _, t := range []struct {
+		table   string
+		restore func(context.Context, *entity.RaceBackup, string) error
+	}{
+		{"races", copyRows(q.RestoreRace)},
+		{"race_status_history", copyRows(q.RestoreRaceStatusHistory)},
+		{"time_readers", copyRows(q.RestoreTimeReaders)},
+		{"events", copyRows(q.RestoreEvents)},
+		{"splits", copyRows(q.RestoreSplits)},
+		{"waves", copyRows(q.RestoreWaves)},
+		{"wave_audit", copyRows(q.RestoreWaveAudit)},
+		{"categories", copyRows(q.RestoreCategories)},
+		{"athletes", copyRows(q.RestoreAthletes)},
+		{"event_athlete", copyRows(q.RestoreEventAthletes)},
+		{"chip_bib", copyRows(q.RestoreChipBibs)},
+		{"athlete_category", copyRows(q.RestoreAthleteCategories)},
+		{"reader_records", copyRows(q.RestoreReaderRecords)},
+		{"athlete_split", copyRows(q.RestoreAthleteSplits)},
+		{"athlete_category_rank", copyRows(q.RestoreAthleteCategoryRanks)},
+	} {
+		err = t.restore(ctx, b, t.table)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return fmt.Errorf("%w: %s: %s", entity.ErrRaceBackupConflict, t.table, pgErr.Detail)
+			}
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+func copyRows[T any](copyFrom func(context.Context, []T) (int64, error)) func(context.Context, *entity.RaceBackup, string) error {
+	return func(ctx context.Context, b *entity.RaceBackup, table string) error {
+		var rows []T
+		err := restoreRows(b, table, &rows)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+		_, err = copyFrom(ctx, rows)
+		if err != nil {
+			return fmt.Errorf("restore %s: %w", table, err)
+		}
+		return nil
+	}
+}
+
+func restoreRows[T any](b *entity.RaceBackup, table string, rows *[]T) error {
+	err := json.Unmarshal(b.Tables[table], rows)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", entity.ErrRaceBackup, table, err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/ecoarchie/timeit/internal/database"
 	"github.com/ecoarchie/timeit/internal/entity"
@@ -12,13 +13,19 @@ import (
 	"github.com/ecoarchie/timeit/pkg/postgres"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type RaceQuery interface {
 	GetRaces(ctx context.Context) ([]database.Race, error)
+	GetRacesWithStatus(ctx context.Context, status string) ([]database.Race, error)
+	GetRacesWithScheduledWaves(ctx context.Context, arg database.GetRacesWithScheduledWavesParams) ([]database.Race, error)
 	GetRaceInfo(ctx context.Context, id uuid.UUID) (database.Race, error)
 	AddRace(ctx context.Context, arg database.AddRaceParams) (database.Race, error)
 	DeleteRace(ctx context.Context, id uuid.UUID) error
+	SetRaceStatus(ctx context.Context, arg database.SetRaceStatusParams) (database.Race, error)
+	AddRaceStatusHistory(ctx context.Context, arg database.AddRaceStatusHistoryParams) error
+	GetRaceStatusHistory(ctx context.Context, raceID uuid.UUID) ([]database.RaceStatusHistory, error)
 	AddOrUpdateTimeReader(ctx context.Context, arg database.AddOrUpdateTimeReaderParams) (database.TimeReader, error)
 	AddOrUpdateEvent(ctx context.Context, arg database.AddOrUpdateEventParams) (database.Event, error)
 	AddOrUpdateSplit(ctx context.Context, arg database.AddOrUpdateSplitParams) (database.Split, error)
@@ -31,7 +38,35 @@ type RaceQuery interface {
 	GetWavesForRace(ctx context.Context, raceID uuid.UUID) ([]database.Wave, error)
 	GetCategoriesForEvent(ctx context.Context, eventID uuid.UUID) ([]database.Category, error)
 	GetWaveByID(ctx context.Context, id uuid.UUID) (database.Wave, error)
+	LaunchWavesByTrigger(ctx context.Context, raceID uuid.UUID) ([]database.Wave, error)
+	LaunchWave(ctx context.Context, arg database.LaunchWaveParams) (database.Wave, error)
+	LaunchScheduledWaves(ctx context.Context, arg database.LaunchScheduledWavesParams) ([]database.Wave, error)
+	ResetWave(ctx context.Context, arg database.ResetWaveParams) (database.Wave, error)
+	SetWaveStartTime(ctx context.Context, arg database.SetWaveStartTimeParams) (database.Wave, error)
+	DeleteWaveResults(ctx context.Context, arg database.DeleteWaveResultsParams) error
+	ResetWaveStatuses(ctx context.Context, arg database.ResetWaveStatusesParams) error
+	DisableTriggerReads(ctx context.Context, arg database.DisableTriggerReadsParams) error
+	AddWaveAudit(ctx context.Context, arg database.AddWaveAuditParams) error
+	GetWaveAudit(ctx context.Context, raceID uuid.UUID) ([]database.WaveAudit, error)
 	GetEventIDsWithWavesStarted(ctx context.Context, raceID uuid.UUID) ([]uuid.UUID, error)
+	GetAthletesForCategories(ctx context.Context, arg database.GetAthletesForCategoriesParams) ([]database.GetAthletesForCategoriesRow, error)
+	SetCategoryBulk(ctx context.Context, arg database.SetCategoryBulkParams) (int64, error)
+	DeleteEvent(ctx context.Context, id uuid.UUID) error
+	DeleteEventResults(ctx context.Context, arg database.DeleteEventResultsParams) error
+	DeleteChipBibWithEventID(ctx context.Context, arg database.DeleteChipBibWithEventIDParams) error
+	DeleteAthletesWithEventID(ctx context.Context, eventID uuid.UUID) error
+	DeleteSplitByID(ctx context.Context, id uuid.UUID) error
+	DeleteSplitResults(ctx context.Context, arg database.DeleteSplitResultsParams) error
+	DeleteSplitCategoryRanks(ctx context.Context, arg database.DeleteSplitCategoryRanksParams) error
+	DeleteWaveByID(ctx context.Context, id uuid.UUID) error
+	CountWaveAthletes(ctx context.Context, arg database.CountWaveAthletesParams) (int64, error)
+	DeleteCategoryByID(ctx context.Context, id uuid.UUID) error
+	ClearCategory(ctx context.Context, arg database.ClearCategoryParams) error
+	UpdateTimeReader(ctx context.Context, arg database.UpdateTimeReaderParams) (database.TimeReader, error)
+	DeleteTimeReaderByID(ctx context.Context, id uuid.UUID) error
+	CountSplitResults(ctx context.Context, raceID uuid.UUID) ([]database.CountSplitResultsRow, error)
+	CountAthletesByWave(ctx context.Context, raceID uuid.UUID) ([]database.CountAthletesByWaveRow, error)
+	CountAthletesByCategory(ctx context.Context, raceID uuid.UUID) ([]database.CountAthletesByCategoryRow, error)
 	WithTx(tx pgx.Tx) *database.Queries
 }
 
@@ -54,7 +89,18 @@ func (rr *RaceRepoPG) WithTx(tx pgx.Tx) *RaceRepoPG {
 	}
 }
 
-func (rr *RaceRepoPG) SaveRaceConfig(ctx context.Context, r *entity.Race, trs []*entity.TimeReader, ee []*entity.Event) error {
+// lockRaceConfig serializes config changes of the race, athletes are saved under shared lock
+// so that wave or category is not deleted while athletes are assigned to it
+const (
+	lockRaceConfig       = `select pg_advisory_xact_lock(hashtextextended($1::text, 1))`
+	lockRaceConfigShared = `select pg_advisory_xact_lock_shared(hashtextextended($1::text, 1))`
+)
+
+// SaveRaceConfig saves race config rm in one transaction holding lock of race config. Saved config and its usage
+// are read within the transaction, both are nil for a new race, and passed to check which returns the diff
+// to apply. Nothing is saved if check returns error. Items deleted by diff are removed together with
+// their dependent results before the rest of config is saved.
+func (rr *RaceRepoPG) SaveRaceConfig(ctx context.Context, rm *entity.RaceModel, check func(prev *entity.RaceModel, usage *entity.ConfigUsage) (*entity.RaceConfigDiff, error)) error {
 	tx, err := rr.pg.Pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -62,7 +108,28 @@ func (rr *RaceRepoPG) SaveRaceConfig(ctx context.Context, r *entity.Race, trs []
 	defer tx.Rollback(ctx)
 	qtx := rr.WithTx(tx)
 
+	_, err = tx.Exec(ctx, lockRaceConfig, rm.ID)
+	if err != nil {
+		return fmt.Errorf("save race config: lock race config: %w", err)
+	}
+	prev, err := qtx.GetRaceConfig(ctx, rm.ID)
+	if err != nil {
+		return err
+	}
+	var usage *entity.ConfigUsage
+	if prev != nil {
+		usage, err = qtx.GetConfigUsage(ctx, rm.ID)
+		if err != nil {
+			return err
+		}
+	}
+	diff, err := check(prev, usage)
+	if err != nil {
+		return err
+	}
+
 	// Save race
+	r := rm.Race
 	addRaceParams := database.AddRaceParams{
 		ID:       r.ID,
 		RaceName: r.Name,
@@ -73,89 +140,144 @@ func (rr *RaceRepoPG) SaveRaceConfig(ctx context.Context, r *entity.Race, trs []
 		return err
 	}
 
-	// Save physical time_readers
-	for _, tr := range trs {
-		locParam := database.AddOrUpdateTimeReaderParams{
-			ID:         tr.ID,
-			RaceID:     tr.RaceID,
-			ReaderName: tr.ReaderName,
+	if diff != nil {
+		err = qtx.removeConfigItems(ctx, r.ID, diff)
+		if err != nil {
+			return err
 		}
-		_, err := qtx.q.AddOrUpdateTimeReader(ctx, locParam)
+	}
+
+	// Save physical time_readers
+	for _, tr := range rm.TimeReaders {
+		err = qtx.saveTimeReader(ctx, tr)
 		if err != nil {
-			return fmt.Errorf("error adding time_reader with ID %s: %v", locParam.ID, err)
+			return fmt.Errorf("error adding time_reader with ID %s: %v", tr.ID, err)
 		}
 	}
 
 	// Save events
-	for _, e := range ee {
-		eParams := database.AddOrUpdateEventParams{
-			ID:               e.ID,
-			RaceID:           e.RaceID,
-			EventName:        e.Name,
-			DistanceInMeters: int32(e.DistanceInMeters),
-			EventDate:        pgxmapper.TimeToPgxTimestamp(e.EventDate),
+	for _, e := range rm.Events {
+		err = qtx.saveEvent(ctx, e)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// removeConfigItems removes items deleted by diff. Items are removed before the rest of config is saved,
+// so their names can be reused by new items.
+func (rr *RaceRepoPG) removeConfigItems(ctx context.Context, raceID uuid.UUID, diff *entity.RaceConfigDiff) error {
+	for _, c := range diff.Deleted(entity.ConfigItemSplit) {
+		err := rr.deleteSplit(ctx, raceID, c.ID)
+		if err != nil {
+			return err
 		}
-		_, err := qtx.q.AddOrUpdateEvent(ctx, eParams)
+	}
+	for _, c := range diff.Deleted(entity.ConfigItemWave) {
+		err := rr.q.DeleteWaveByID(ctx, c.ID)
 		if err != nil {
-			return fmt.Errorf("error saving event with ID %s to db: %v", eParams.ID, err)
-		}
-
-		// Save splits for event
-		for _, s := range e.Splits {
-			sParams := database.AddOrUpdateSplitParams{
-				ID:                s.ID,
-				RaceID:            s.RaceID,
-				EventID:           s.EventID,
-				SplitName:         s.Name,
-				SplitType:         database.TpType(s.Type),
-				DistanceFromStart: int32(s.DistanceFromStart),
-				TimeReaderID:      s.TimeReaderID,
-				MinTime:           pgxmapper.DurationToPgxInterval(s.MinTime),
-				MaxTime:           pgxmapper.DurationToPgxInterval(s.MaxTime),
-				MinLapTime:        pgxmapper.DurationToPgxInterval(s.MinLapTime),
-			}
-			_, err := qtx.q.AddOrUpdateSplit(ctx, sParams)
-			if err != nil {
-				return fmt.Errorf("error saving split with ID %s to db: %v", sParams.ID, err)
-			}
+			return fmt.Errorf("delete wave %s: %w", c.ID, err)
 		}
+	}
+	for _, c := range diff.Deleted(entity.ConfigItemCategory) {
+		err := rr.deleteCategory(ctx, raceID, c.ID)
+		if err != nil {
+			return err
+		}
+	}
+	for _, c := range diff.Deleted(entity.ConfigItemEvent) {
+		err := rr.deleteEvent(ctx, raceID, c.ID)
+		if err != nil {
+			return err
+		}
+	}
+	// splits using the reader are deleted already
+	for _, c := range diff.Deleted(entity.ConfigItemTimeReader) {
+		err := rr.q.DeleteTimeReaderByID(ctx, c.ID)
+		if err != nil {
+			return fmt.Errorf("delete time reader %s: %w", c.ID, err)
+		}
+	}
+	return nil
+}
 
-		// Save waves
-		for _, w := range e.Waves {
-			wParams := database.AddOrUpdateWaveParams{
-				ID:         w.ID,
-				RaceID:     w.RaceID,
-				EventID:    w.EventID,
-				WaveName:   w.Name,
-				StartTime:  pgxmapper.TimeToPgxTimestamp(w.StartTime),
-				IsLaunched: w.IsLaunched,
-			}
-			_, err := qtx.q.AddOrUpdateWave(ctx, wParams)
-			if err != nil {
-				return fmt.Errorf("error saving wave with ID %s to db: %v", wParams.ID, err)
-			}
+// saveEvent saves event with its splits, waves and categories, should be called within transaction
+func (rr *RaceRepoPG) saveEvent(ctx context.Context, e *entity.Event) error {
+	eParams := database.AddOrUpdateEventParams{
+		ID:               e.ID,
+		RaceID:           e.RaceID,
+		EventName:        e.Name,
+		DistanceInMeters: int32(e.DistanceInMeters),
+		EventDate:        pgxmapper.TimeToPgxTimestamp(e.EventDate),
+	}
+	_, err := rr.q.AddOrUpdateEvent(ctx, eParams)
+	if err != nil {
+		return fmt.Errorf("error saving event with ID %s to db: %v", eParams.ID, err)
+	}
+
+	// Save splits for event
+	for _, s := range e.Splits {
+		sParams := database.AddOrUpdateSplitParams{
+			ID:                s.ID,
+			RaceID:            s.RaceID,
+			EventID:           s.EventID,
+			SplitName:         s.Name,
+			SplitType:         database.TpType(s.Type),
+			DistanceFromStart: int32(s.DistanceFromStart),
+			TimeReaderID:      s.TimeReaderID,
+			MinTime:           pgxmapper.DurationToPgxInterval(s.MinTime),
+			MaxTime:           pgxmapper.DurationToPgxInterval(s.MaxTime),
+			MinLapTime:        pgxmapper.DurationToPgxInterval(s.MinLapTime),
+			CutoffTime:        pgxmapper.DurationToPgxInterval(s.CutoffTime),
+			CutoffTod:         pgtype.Timestamptz{Time: s.CutoffTOD, Valid: !s.CutoffTOD.IsZero()},
 		}
+		_, err := rr.q.AddOrUpdateSplit(ctx, sParams)
+		if err != nil {
+			return fmt.Errorf("error saving split with ID %s to db: %v", sParams.ID, err)
+		}
+	}
 
-		// Save categories
-		for _, c := range e.Categories {
-			cParams := database.AddOrUpdateCategoryParams{
-				ID:           c.ID,
-				RaceID:       c.RaceID,
-				EventID:      c.EventID,
-				CategoryName: c.Name,
-				Gender:       database.CategoryGender(c.Gender),
-				AgeFrom:      int32(c.AgeFrom),
-				DateFrom:     pgxmapper.TimeToPgxTimestamp(c.DateFrom),
-				AgeTo:        int32(c.AgeTo),
-				DateTo:       pgxmapper.TimeToPgxTimestamp(c.DateTo),
-			}
-			_, err := qtx.q.AddOrUpdateCategory(ctx, cParams)
-			if err != nil {
-				return fmt.Errorf("error saving category with ID %s to db: %v", cParams.ID, err)
-			}
+	// Save waves
+	for _, w := range e.Waves {
+		wParams := database.AddOrUpdateWaveParams{
+			ID:              w.ID,
+			RaceID:          w.RaceID,
+			EventID:         w.EventID,
+			WaveName:        w.Name,
+			StartTime:       pgxmapper.TimeToPgxTimestamptz(w.StartTime),
+			IsLaunched:      w.IsLaunched,
+			TriggerChip:     triggerChipToPgxInt4(w.TriggerChip),
+			TriggerReaderID: w.TriggerReaderID,
+			AutoStart:       w.AutoStart,
+		}
+		_, err := rr.q.AddOrUpdateWave(ctx, wParams)
+		if err != nil {
+			return fmt.Errorf("error saving wave with ID %s to db: %v", wParams.ID, err)
 		}
 	}
-	return tx.Commit(ctx)
+
+	// Save categories
+	for _, c := range e.Categories {
+		cParams := database.AddOrUpdateCategoryParams{
+			ID:           c.ID,
+			RaceID:       c.RaceID,
+			EventID:      c.EventID,
+			CategoryName: c.Name,
+			Gender:       database.CategoryGender(c.Gender),
+			AgeFrom:      int32(c.AgeFrom),
+			DateFrom:     pgxmapper.TimeToPgxTimestamp(c.DateFrom),
+			AgeTo:        int32(c.AgeTo),
+			DateTo:       pgxmapper.TimeToPgxTimestamp(c.DateTo),
+			Kind:         string(c.Kind),
+			Handicap:     pgxmapper.DurationToPgxInterval(c.Handicap),
+		}
+		_, err := rr.q.AddOrUpdateCategory(ctx, cParams)
+		if err != nil {
+			return fmt.Errorf("error saving category with ID %s to db: %v", cParams.ID, err)
+		}
+	}
+	return nil
 }
 
 // func (rr *RaceRepoPG) GetRaceInfoForCSV(ctx context.Context, raceID uuid.UUID) ([]*entity.Event, []*entity.Wave, []*entity.Category, error) {
@@ -188,6 +310,7 @@ func (rr *RaceRepoPG) GetRaceConfig(ctx context.Context, raceID uuid.UUID) (*ent
 			ID:       r.ID,
 			Name:     r.RaceName,
 			Timezone: r.Timezone,
+			Status:   entity.RaceStatus(r.Status),
 		},
 		TimeReaders: []*entity.TimeReader{},
 		Events:      []*entity.Event{},
@@ -242,6 +365,8 @@ func (rr *RaceRepoPG) GetRaceConfig(ctx context.Context, raceID uuid.UUID) (*ent
 				MaxTime:            pgxmapper.PgxIntervalToDuration(s.MaxTime),
 				MinLapTime:         pgxmapper.PgxIntervalToDuration(s.MinLapTime),
 				PreviousLapSplitID: s.PreviousLapSplitID,
+				CutoffTime:         pgxmapper.PgxIntervalToDuration(s.CutoffTime),
+				CutoffTOD:          s.CutoffTod.Time,
 			}
 			event.Splits = append(event.Splits, split)
 		}
@@ -252,15 +377,7 @@ func (rr *RaceRepoPG) GetRaceConfig(ctx context.Context, raceID uuid.UUID) (*ent
 			return nil, err
 		}
 		for _, w := range waves {
-			wave := &entity.Wave{
-				ID:         w.ID,
-				RaceID:     w.RaceID,
-				EventID:    w.EventID,
-				Name:       w.WaveName,
-				StartTime:  w.StartTime.Time,
-				IsLaunched: w.IsLaunched,
-			}
-			event.Waves = append(event.Waves, wave)
+			event.Waves = append(event.Waves, waveFromDB(w))
 		}
 
 		// get categories for event
@@ -274,11 +391,13 @@ func (rr *RaceRepoPG) GetRaceConfig(ctx context.Context, raceID uuid.UUID) (*ent
 				RaceID:   c.RaceID,
 				EventID:  c.EventID,
 				Name:     c.CategoryName,
+				Kind:     entity.CategoryKind(c.Kind),
 				Gender:   entity.CategoryGender(c.Gender),
 				AgeFrom:  int(c.AgeFrom),
 				DateFrom: c.DateFrom.Time,
 				AgeTo:    int(c.AgeTo),
 				DateTo:   c.DateTo.Time,
+				Handicap: pgxmapper.PgxIntervalToDuration(c.Handicap),
 			}
 			event.Categories = append(event.Categories, category)
 		}
@@ -297,16 +416,58 @@ func (rr *RaceRepoPG) GetRaces(ctx context.Context) ([]*entity.Race, error) {
 		}
 		return nil, err
 	}
+	return racesFromDB(races), nil
+}
+
+// GetRacesWithStatus returns races in status, e.g. live races for background jobs
+func (rr *RaceRepoPG) GetRacesWithStatus(ctx context.Context, status entity.RaceStatus) ([]*entity.Race, error) {
+	races, err := rr.q.GetRacesWithStatus(ctx, string(status))
+	if err != nil {
+		return nil, err
+	}
+	return racesFromDB(races), nil
+}
+
+// GetRacesWithScheduledWaves returns live races having auto start waves not launched yet with start time up to now
+func (rr *RaceRepoPG) GetRacesWithScheduledWaves(ctx context.Context, now time.Time) ([]*entity.Race, error) {
+	races, err := rr.q.GetRacesWithScheduledWaves(ctx, database.GetRacesWithScheduledWavesParams{
+		Status:    string(entity.RaceStatusLive),
+		StartTime: pgxmapper.TimeToPgxTimestamptz(now),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return racesFromDB(races), nil
+}
+
+func racesFromDB(races []database.Race) []*entity.Race {
 	var res []*entity.Race
 	for _, r := range races {
 		race := &entity.Race{
 			ID:       r.ID,
 			Name:     r.RaceName,
 			Timezone: r.Timezone,
+			Status:   entity.RaceStatus(r.Status),
 		}
 		res = append(res, race)
 	}
-	return res, nil
+	return res
+}
+
+func (rr *RaceRepoPG) GetRaceInfo(ctx context.Context, raceID uuid.UUID) (*entity.Race, error) {
+	r, err := rr.q.GetRaceInfo(ctx, raceID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity.Race{
+		ID:       r.ID,
+		Name:     r.RaceName,
+		Timezone: r.Timezone,
+		Status:   entity.RaceStatus(r.Status),
+	}, nil
 }
 
 func (rr *RaceRepoPG) SaveRaceInfo(ctx context.Context, race *entity.Race) error {
@@ -327,6 +488,60 @@ func (rr *RaceRepoPG) DeleteRace(ctx context.Context, raceID uuid.UUID) error {
 	return err
 }
 
+// SetRaceStatus moves race to new status of c and records the change in status history. Returns false
+// if status of the race has been changed meanwhile.
+func (rr *RaceRepoPG) SetRaceStatus(ctx context.Context, c *entity.RaceStatusChange) (bool, error) {
+	tx, err := rr.pg.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := rr.WithTx(tx)
+
+	_, err = qtx.q.SetRaceStatus(ctx, database.SetRaceStatusParams{
+		NewStatus: string(c.NewStatus),
+		ID:        c.RaceID,
+		OldStatus: string(c.OldStatus),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("set race status: %w", err)
+	}
+	err = qtx.q.AddRaceStatusHistory(ctx, database.AddRaceStatusHistoryParams{
+		RaceID:    c.RaceID,
+		OldStatus: string(c.OldStatus),
+		NewStatus: string(c.NewStatus),
+		Forced:    c.Forced,
+		Reason:    c.Reason,
+	})
+	if err != nil {
+		return false, fmt.Errorf("set race status: history: %w", err)
+	}
+	return true, tx.Commit(ctx)
+}
+
+func (rr *RaceRepoPG) GetRaceStatusHistory(ctx context.Context, raceID uuid.UUID) ([]*entity.RaceStatusChange, error) {
+	rows, err := rr.q.GetRaceStatusHistory(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*entity.RaceStatusChange, 0, len(rows))
+	for _, r := range rows {
+		changes = append(changes, &entity.RaceStatusChange{
+			ID:        r.ID,
+			RaceID:    r.RaceID,
+			OldStatus: entity.RaceStatus(r.OldStatus),
+			NewStatus: entity.RaceStatus(r.NewStatus),
+			Forced:    r.Forced,
+			Reason:    r.Reason,
+			CreatedAt: r.CreatedAt.Time,
+		})
+	}
+	return changes, nil
+}
+
 func (rr *RaceRepoPG) GetWavesForRace(ctx context.Context, raceID uuid.UUID) ([]*entity.Wave, error) {
 	ws, err := rr.q.GetWavesForRace(ctx, raceID)
 	if err != nil {
@@ -337,15 +552,7 @@ func (rr *RaceRepoPG) GetWavesForRace(ctx context.Context, raceID uuid.UUID) ([]
 	}
 	waves := []*entity.Wave{}
 	for _, w := range ws {
-		wave := &entity.Wave{
-			ID:         w.ID,
-			RaceID:     w.RaceID,
-			EventID:    w.EventID,
-			Name:       w.WaveName,
-			StartTime:  w.StartTime.Time,
-			IsLaunched: w.IsLaunched,
-		}
-		waves = append(waves, wave)
+		waves = append(waves, waveFromDB(w))
 	}
 	return waves, nil
 }
@@ -358,25 +565,196 @@ func (rr *RaceRepoPG) GetWaveByID(ctx context.Context, waveID uuid.UUID) (*entit
 		}
 		return nil, err
 	}
-	wave := &entity.Wave{
-		ID:         w.ID,
-		RaceID:     w.RaceID,
-		EventID:    w.EventID,
-		Name:       w.WaveName,
-		StartTime:  w.StartTime.Time,
-		IsLaunched: w.IsLaunched,
+	return waveFromDB(w), nil
+}
+
+func waveFromDB(w database.Wave) *entity.Wave {
+	return &entity.Wave{
+		ID:              w.ID,
+		RaceID:          w.RaceID,
+		EventID:         w.EventID,
+		Name:            w.WaveName,
+		StartTime:       w.StartTime.Time,
+		IsLaunched:      w.IsLaunched,
+		TriggerChip:     int(w.TriggerChip.Int32),
+		TriggerReaderID: w.TriggerReaderID,
+		AutoStart:       w.AutoStart,
+	}
+}
+
+func triggerChipToPgxInt4(chip int) pgtype.Int4 {
+	return pgtype.Int4{Int32: int32(chip), Valid: chip > 0}
+}
+
+// LaunchWavesByTrigger launches not launched waves of race whose trigger chip was read by trigger reader
+// at the TOD of the first such read. Launched waves are returned.
+func (rr *RaceRepoPG) LaunchWavesByTrigger(ctx context.Context, raceID uuid.UUID) ([]*entity.Wave, error) {
+	ws, err := rr.q.LaunchWavesByTrigger(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("launch waves by trigger: %w", err)
+	}
+	waves := make([]*entity.Wave, 0, len(ws))
+	for _, w := range ws {
+		waves = append(waves, waveFromDB(w))
+	}
+	return waves, nil
+}
+
+// LaunchScheduledWaves launches not launched auto start waves of race whose start time is not after now,
+// at their configured start time. Launches are recorded in wave audit. Launched waves are returned.
+func (rr *RaceRepoPG) LaunchScheduledWaves(ctx context.Context, raceID uuid.UUID, now time.Time) ([]*entity.Wave, error) {
+	tx, err := rr.pg.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := rr.WithTx(tx)
+
+	ws, err := qtx.q.LaunchScheduledWaves(ctx, database.LaunchScheduledWavesParams{
+		RaceID:    raceID,
+		StartTime: pgxmapper.TimeToPgxTimestamptz(now),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("launch scheduled waves: %w", err)
+	}
+	waves := make([]*entity.Wave, 0, len(ws))
+	for _, w := range ws {
+		err = qtx.q.AddWaveAudit(ctx, database.AddWaveAuditParams{
+			RaceID:       raceID,
+			WaveID:       w.ID,
+			Action:       string(entity.WaveActionLaunch),
+			OldStartTime: w.StartTime,
+			NewStartTime: w.StartTime,
+			WasLaunched:  false,
+			IsLaunched:   true,
+			Reason:       "scheduled start",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("launch scheduled waves: audit of wave %s: %w", w.ID, err)
+		}
+		waves = append(waves, waveFromDB(w))
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return waves, nil
+}
+
+// SaveWaveChanges applies manual changes of waves in one transaction and records them in wave audit.
+// Results of athletes of reset waves are removed, their auto start and reads of their trigger chips
+// disabled, so the wave is not launched again by the old schedule or trigger read. Fails if any wave has been launched or reset meanwhile.
+func (rr *RaceRepoPG) SaveWaveChanges(ctx context.Context, raceID uuid.UUID, changes []*entity.WaveAuditEntry) ([]*entity.Wave, error) {
+	tx, err := rr.pg.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+	qtx := rr.WithTx(tx)
+
+	waves := make([]*entity.Wave, 0, len(changes))
+	for _, c := range changes {
+		var w database.Wave
+		switch c.Action {
+		case entity.WaveActionLaunch:
+			w, err = qtx.q.LaunchWave(ctx, database.LaunchWaveParams{
+				RaceID:    raceID,
+				ID:        c.WaveID,
+				StartTime: pgxmapper.TimeToPgxTimestamptz(c.NewStartTime),
+			})
+		case entity.WaveActionAdjust:
+			w, err = qtx.q.SetWaveStartTime(ctx, database.SetWaveStartTimeParams{
+				RaceID:    raceID,
+				ID:        c.WaveID,
+				StartTime: pgxmapper.TimeToPgxTimestamptz(c.NewStartTime),
+			})
+		case entity.WaveActionReset:
+			w, err = qtx.q.ResetWave(ctx, database.ResetWaveParams{
+				RaceID: raceID,
+				ID:     c.WaveID,
+			})
+			if err == nil {
+				err = qtx.clearWaveResults(ctx, raceID, c.WaveID)
+			}
+		default:
+			return nil, fmt.Errorf("save wave changes: unknown action %q", c.Action)
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("save wave changes: wave %s: %w", c.WaveID, entity.ErrWaveChanged)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("save wave changes: %s wave %s: %w", c.Action, c.WaveID, err)
+		}
+		err = qtx.q.AddWaveAudit(ctx, database.AddWaveAuditParams{
+			RaceID:       raceID,
+			WaveID:       c.WaveID,
+			Action:       string(c.Action),
+			OldStartTime: pgxmapper.TimeToPgxTimestamptz(c.OldStartTime),
+			NewStartTime: pgxmapper.TimeToPgxTimestamptz(c.NewStartTime),
+			WasLaunched:  c.WasLaunched,
+			IsLaunched:   c.IsLaunched,
+			Reason:       c.Reason,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("save wave changes: audit of wave %s: %w", c.WaveID, err)
+		}
+		waves = append(waves, waveFromDB(w))
+	}
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return waves, nil
+}
+
+// clearWaveResults removes calculated splits of athletes of the wave, resets their not locked statuses
+// and disables reads of wave's trigger chip
+func (rr *RaceRepoPG) clearWaveResults(ctx context.Context, raceID, waveID uuid.UUID) error {
+	err := rr.q.DeleteWaveResults(ctx, database.DeleteWaveResultsParams{RaceID: raceID, WaveID: waveID})
+	if err != nil {
+		return err
+	}
+	err = rr.q.ResetWaveStatuses(ctx, database.ResetWaveStatusesParams{RaceID: raceID, WaveID: waveID})
+	if err != nil {
+		return err
+	}
+	return rr.q.DisableTriggerReads(ctx, database.DisableTriggerReadsParams{RaceID: raceID, ID: waveID})
+}
+
+func (rr *RaceRepoPG) GetWaveAudit(ctx context.Context, raceID uuid.UUID) ([]*entity.WaveAuditEntry, error) {
+	rows, err := rr.q.GetWaveAudit(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*entity.WaveAuditEntry, 0, len(rows))
+	for _, r := range rows {
+		entries = append(entries, &entity.WaveAuditEntry{
+			ID:           r.ID,
+			RaceID:       r.RaceID,
+			WaveID:       r.WaveID,
+			Action:       entity.WaveAction(r.Action),
+			OldStartTime: r.OldStartTime.Time,
+			NewStartTime: r.NewStartTime.Time,
+			WasLaunched:  r.WasLaunched,
+			IsLaunched:   r.IsLaunched,
+			Reason:       r.Reason,
+			CreatedAt:    r.CreatedAt.Time,
+		})
 	}
-	return wave, nil
+	return entries, nil
 }
 
 func (rr *RaceRepoPG) SaveWave(ctx context.Context, wave *entity.Wave) error {
 	wParams := database.AddOrUpdateWaveParams{
-		ID:         wave.ID,
-		RaceID:     wave.RaceID,
-		EventID:    wave.EventID,
-		WaveName:   wave.Name,
-		StartTime:  pgxmapper.TimeToPgxTimestamp(wave.StartTime),
-		IsLaunched: wave.IsLaunched,
+		ID:              wave.ID,
+		RaceID:          wave.RaceID,
+		EventID:         wave.EventID,
+		WaveName:        wave.Name,
+		StartTime:       pgxmapper.TimeToPgxTimestamptz(wave.StartTime),
+		IsLaunched:      wave.IsLaunched,
+		TriggerChip:     triggerChipToPgxInt4(wave.TriggerChip),
+		TriggerReaderID: wave.TriggerReaderID,
+		AutoStart:       wave.AutoStart,
 	}
 	_, err := rr.q.AddOrUpdateWave(ctx, wParams)
 	if err != nil {
@@ -388,3 +766,275 @@ func (rr *RaceRepoPG) SaveWave(ctx context.Context, wave *entity.Wave) error {
 func (rr *RaceRepoPG) GetEventIDsWithWavesStarted(ctx context.Context, raceID uuid.UUID) ([]uuid.UUID, error) {
 	return rr.q.GetEventIDsWithWavesStarted(ctx, raceID)
 }
+
+// GetAthletesForCategories returns athletes of the event whose category is not locked
+func (rr *RaceRepoPG) GetAthletesForCategories(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.Athlete, error) {
+	rows, err := rr.q.GetAthletesForCategories(ctx, database.GetAthletesForCategoriesParams{
+		RaceID:  raceID,
+		EventID: eventID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	athletes := make([]*entity.Athlete, 0, len(rows))
+	for _, r := range rows {
+		athletes = append(athletes, &entity.Athlete{
+			ID:          r.AthleteID,
+			RaceID:      raceID,
+			EventID:     eventID,
+			Gender:      entity.CategoryGender(r.Gender),
+			DateOfBirth: r.DateOfBirth.Time,
+			CategoryID:  r.CategoryID,
+		})
+	}
+	return athletes, nil
+}
+
+// UpdateAthleteCategories saves categories of athletes of the event, categories locked meanwhile are kept
+func (rr *RaceRepoPG) UpdateAthleteCategories(ctx context.Context, raceID, eventID uuid.UUID, athletes []*entity.Athlete) (int, error) {
+	if len(athletes) == 0 {
+		return 0, nil
+	}
+	params := database.SetCategoryBulkParams{
+		AthleteIds:  make([]uuid.UUID, 0, len(athletes)),
+		CategoryIds: make([]uuid.UUID, 0, len(athletes)),
+		RaceID:      raceID,
+		EventID:     eventID,
+	}
+	for _, a := range athletes {
+		params.AthleteIds = append(params.AthleteIds, a.ID)
+		// uuid.Nil clears category
+		params.CategoryIds = append(params.CategoryIds, a.CategoryID.UUID)
+	}
+	n, err := rr.q.SetCategoryBulk(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("update athlete categories: %w", err)
+	}
+	return int(n), nil
+}
+
+// SaveEvent saves single event of the race with its splits, waves and categories. Event to save is returned
+// by check, see changeEvent.
+func (rr *RaceRepoPG) SaveEvent(ctx context.Context, raceID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) error {
+	return rr.changeEvent(ctx, raceID, func(_ *RaceRepoPG, rc *entity.RaceModel) (*entity.Event, error) {
+		return check(rc)
+	}, func(qtx *RaceRepoPG, e *entity.Event) error {
+		return qtx.saveEvent(ctx, e)
+	})
+}
+
+// DeleteEvent removes event of the race returned by check, see changeEvent, with its splits, waves and categories
+// together with athletes of the event and their results
+func (rr *RaceRepoPG) DeleteEvent(ctx context.Context, raceID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) error {
+	return rr.changeEvent(ctx, raceID, func(_ *RaceRepoPG, rc *entity.RaceModel) (*entity.Event, error) {
+		return check(rc)
+	}, func(qtx *RaceRepoPG, e *entity.Event) error {
+		return qtx.deleteEvent(ctx, raceID, e.ID)
+	})
+}
+
+// changeEvent changes single event in one transaction holding lock of race config. Saved config is read within
+// the transaction, it is nil if race is not found, and passed to check which returns the changed event.
+// Nothing is changed if check returns error or nil event, the event is passed to apply otherwise.
+func (rr *RaceRepoPG) changeEvent(ctx context.Context, raceID uuid.UUID, check func(qtx *RaceRepoPG, rc *entity.RaceModel) (*entity.Event, error), apply func(qtx *RaceRepoPG, e *entity.Event) error) error {
+	tx, err := rr.pg.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	qtx := rr.WithTx(tx)
+
+	_, err = tx.Exec(ctx, lockRaceConfig, raceID)
+	if err != nil {
+		return fmt.Errorf("change event: lock race config: %w", err)
+	}
+	rc, err := qtx.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return err
+	}
+	e, err := check(qtx, rc)
+	if err != nil || e == nil {
+		return err
+	}
+	err = apply(qtx, e)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (rr *RaceRepoPG) deleteEvent(ctx context.Context, raceID, eventID uuid.UUID) error {
+	err := rr.q.DeleteEventResults(ctx, database.DeleteEventResultsParams{RaceID: raceID, EventID: eventID})
+	if err != nil {
+		return fmt.Errorf("delete event %s: results: %w", eventID, err)
+	}
+	err = rr.q.DeleteChipBibWithEventID(ctx, database.DeleteChipBibWithEventIDParams{RaceID: raceID, EventID: eventID})
+	if err != nil {
+		return fmt.Errorf("delete event %s: chips: %w", eventID, err)
+	}
+	// athlete's event entry and custom categories are removed together with athlete
+	err = rr.q.DeleteAthletesWithEventID(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("delete event %s: athletes: %w", eventID, err)
+	}
+	// splits, waves and categories are removed in cascade
+	err = rr.q.DeleteEvent(ctx, eventID)
+	if err != nil {
+		return fmt.Errorf("delete event %s: %w", eventID, err)
+	}
+	return nil
+}
+
+// DeleteSplit removes split of event returned by check, see changeEvent, with athletes' results at it
+// and saves the rest of the event
+func (rr *RaceRepoPG) DeleteSplit(ctx context.Context, raceID, splitID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) error {
+	return rr.changeEvent(ctx, raceID, func(_ *RaceRepoPG, rc *entity.RaceModel) (*entity.Event, error) {
+		return check(rc)
+	}, func(qtx *RaceRepoPG, e *entity.Event) error {
+		err := qtx.deleteSplit(ctx, raceID, splitID)
+		if err != nil {
+			return err
+		}
+		return qtx.saveEvent(ctx, e)
+	})
+}
+
+func (rr *RaceRepoPG) deleteSplit(ctx context.Context, raceID, splitID uuid.UUID) error {
+	err := rr.q.DeleteSplitResults(ctx, database.DeleteSplitResultsParams{RaceID: raceID, SplitID: splitID})
+	if err != nil {
+		return fmt.Errorf("delete split %s: results: %w", splitID, err)
+	}
+	err = rr.q.DeleteSplitCategoryRanks(ctx, database.DeleteSplitCategoryRanksParams{RaceID: raceID, SplitID: splitID})
+	if err != nil {
+		return fmt.Errorf("delete split %s: category ranks: %w", splitID, err)
+	}
+	err = rr.q.DeleteSplitByID(ctx, splitID)
+	if err != nil {
+		return fmt.Errorf("delete split %s: %w", splitID, err)
+	}
+	return nil
+}
+
+func (rr *RaceRepoPG) countWaveAthletes(ctx context.Context, raceID, waveID uuid.UUID) (int, error) {
+	n, err := rr.q.CountWaveAthletes(ctx, database.CountWaveAthletesParams{RaceID: raceID, WaveID: waveID})
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// DeleteWave removes wave of event returned by check, see changeEvent, and saves the rest of the event.
+// Number of athletes of the wave is counted within the transaction and passed to check.
+func (rr *RaceRepoPG) DeleteWave(ctx context.Context, raceID, waveID uuid.UUID, check func(rc *entity.RaceModel, athletes int) (*entity.Event, error)) error {
+	return rr.changeEvent(ctx, raceID, func(qtx *RaceRepoPG, rc *entity.RaceModel) (*entity.Event, error) {
+		if rc == nil {
+			return check(rc, 0)
+		}
+		athletes, err := qtx.countWaveAthletes(ctx, raceID, waveID)
+		if err != nil {
+			return nil, fmt.Errorf("delete wave %s: count athletes: %w", waveID, err)
+		}
+		return check(rc, athletes)
+	}, func(qtx *RaceRepoPG, e *entity.Event) error {
+		err := qtx.q.DeleteWaveByID(ctx, waveID)
+		if err != nil {
+			return fmt.Errorf("delete wave %s: %w", waveID, err)
+		}
+		return qtx.saveEvent(ctx, e)
+	})
+}
+
+// DeleteCategory removes category of event returned by check, see changeEvent, and saves the rest of the event.
+// Athletes of the category are left without category and unlocked, so they can be matched to the remaining categories.
+func (rr *RaceRepoPG) DeleteCategory(ctx context.Context, raceID, categoryID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) error {
+	return rr.changeEvent(ctx, raceID, func(_ *RaceRepoPG, rc *entity.RaceModel) (*entity.Event, error) {
+		return check(rc)
+	}, func(qtx *RaceRepoPG, e *entity.Event) error {
+		err := qtx.deleteCategory(ctx, raceID, categoryID)
+		if err != nil {
+			return err
+		}
+		return qtx.saveEvent(ctx, e)
+	})
+}
+
+func (rr *RaceRepoPG) deleteCategory(ctx context.Context, raceID, categoryID uuid.UUID) error {
+	err := rr.q.ClearCategory(ctx, database.ClearCategoryParams{
+		RaceID:     raceID,
+		CategoryID: uuid.NullUUID{UUID: categoryID, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("delete category %s: athletes: %w", categoryID, err)
+	}
+	// custom category members and their ranks are removed in cascade
+	err = rr.q.DeleteCategoryByID(ctx, categoryID)
+	if err != nil {
+		return fmt.Errorf("delete category %s: %w", categoryID, err)
+	}
+	return nil
+}
+
+// SaveTimeReader updates name of existing time reader or adds new one
+func (rr *RaceRepoPG) SaveTimeReader(ctx context.Context, tr *entity.TimeReader) error {
+	return rr.saveTimeReader(ctx, tr)
+}
+
+// saveTimeReader updates reader by ID, so it can be renamed, and adds it if it doesn't exist yet
+func (rr *RaceRepoPG) saveTimeReader(ctx context.Context, tr *entity.TimeReader) error {
+	_, err := rr.q.UpdateTimeReader(ctx, database.UpdateTimeReaderParams{
+		RaceID:     tr.RaceID,
+		ID:         tr.ID,
+		ReaderName: tr.ReaderName,
+	})
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("update time reader %s: %w", tr.ID, err)
+	}
+	_, err = rr.q.AddOrUpdateTimeReader(ctx, database.AddOrUpdateTimeReaderParams{
+		ID:         tr.ID,
+		RaceID:     tr.RaceID,
+		ReaderName: tr.ReaderName,
+	})
+	if err != nil {
+		return fmt.Errorf("add time reader %s: %w", tr.ID, err)
+	}
+	return nil
+}
+
+// DeleteTimeReader removes time reader of the race. Reads of the reader are kept.
+func (rr *RaceRepoPG) DeleteTimeReader(ctx context.Context, readerID uuid.UUID) error {
+	return rr.q.DeleteTimeReaderByID(ctx, readerID)
+}
+
+// GetConfigUsage counts results and athletes depending on splits, waves and categories of the race
+func (rr *RaceRepoPG) GetConfigUsage(ctx context.Context, raceID uuid.UUID) (*entity.ConfigUsage, error) {
+	usage := &entity.ConfigUsage{
+		SplitResults:     map[uuid.UUID]int{},
+		WaveAthletes:     map[uuid.UUID]int{},
+		CategoryAthletes: map[uuid.UUID]int{},
+	}
+	splits, err := rr.q.CountSplitResults(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("count split results: %w", err)
+	}
+	for _, s := range splits {
+		usage.SplitResults[s.SplitID] = int(s.Count)
+	}
+	waves, err := rr.q.CountAthletesByWave(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("count wave athletes: %w", err)
+	}
+	for _, w := range waves {
+		usage.WaveAthletes[w.WaveID] = int(w.Count)
+	}
+	cats, err := rr.q.CountAthletesByCategory(ctx, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("count category athletes: %w", err)
+	}
+	for _, c := range cats {
+		usage.CategoryAthletes[c.CategoryID.UUID] += int(c.Count)
+	}
+	return usage, nil
+}
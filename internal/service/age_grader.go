@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+)
+
+//go:embed agegrade/age_factors.json
+var defaultAgeFactors []byte
+
+// LoadAgeGradeTable reads age factors table from file. Table bundled with the app is used when path is empty.
+func LoadAgeGradeTable(path string) (*entity.AgeGradeTable, error) {
+	data := defaultAgeFactors
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading age factors file %s: %w", path, err)
+		}
+	}
+	return entity.NewAgeGradeTable(bytes.NewReader(data))
+}
+
+// zero birth date is assigned to athletes without known date of birth
+var zeroBirthDate = time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+func (rs *ResultsService) GetAgeGradedResults(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.AgeGradedResult, error) {
+	if rs.AgeGrades == nil {
+		return nil, fmt.Errorf("age grade table is not loaded")
+	}
+	rm, err := rs.RaceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if rm == nil {
+		return nil, nil
+	}
+	var event *entity.Event
+	for _, e := range rm.Events {
+		if e.ID == eventID {
+			event = e
+			break
+		}
+	}
+	if event == nil {
+		return nil, nil
+	}
+
+	finishers, err := rs.AthleteRepo.GetFinishResultsForEvent(ctx, raceID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*entity.AgeGradedResult, 0, len(finishers))
+	for _, f := range finishers {
+		if f.DateOfBirth.IsZero() || f.DateOfBirth.Equal(zeroBirthDate) {
+			continue
+		}
+		age := entity.AgeOn(f.DateOfBirth, event.EventDate)
+		grade, ok := rs.AgeGrades.Grade(f.Gender, age, event.DistanceInMeters, f.NetTime)
+		if !ok {
+			continue
+		}
+		res = append(res, &entity.AgeGradedResult{
+			FinishResult: f,
+			AgeGrade:     grade,
+		})
+	}
+	entity.RankAgeGraded(res)
+	return res, nil
+}
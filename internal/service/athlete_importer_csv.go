@@ -35,6 +35,9 @@ type AthleteCSV struct {
 	DateOfBirth string `csv:"date of birth"`
 	Phone       string `csv:"phone"`
 	Comments    string `csv:"comments"`
+	Categories  string `csv:"categories"`
+	StartTime   string `csv:"start time"`
+	Handicap    string `csv:"handicap"`
 }
 
 type (
@@ -54,6 +57,9 @@ func NewAthleteImporterCSV(file, separator string) *AthleteImporterCSV {
 		"date of birth",
 		"phone",
 		"comments",
+		"categories",
+		"start time",
+		"handicap",
 	}
 	return &AthleteImporterCSV{
 		FileName:     file,
@@ -4,22 +4,28 @@ import (
 	"context"
 	"fmt"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/ecoarchie/timeit/internal/database"
 	"github.com/ecoarchie/timeit/internal/entity"
 	"github.com/ecoarchie/timeit/pkg/logger"
+	"github.com/ecoarchie/timeit/pkg/validator"
 	"github.com/google/uuid"
 )
 
 type AthleteManager interface {
 	GetAthleteByID(ctx context.Context, athleteID uuid.UUID) *entity.Athlete
-	CreateAthlete(ctx context.Context, req entity.AthleteCreateRequest) (*entity.Athlete, error)
-	CreateBulkAthletes(ctx context.Context, reqs []entity.AthleteCreateRequest) (int64, error)
-	UpdateAthlete(ctx context.Context, req entity.AthleteUpdateRequest) (*entity.Athlete, error)
+	CreateAthlete(ctx context.Context, req entity.AthleteCreateRequest, v *validator.Validator) (*entity.Athlete, error)
+	CreateBulkAthletes(ctx context.Context, reqs []entity.AthleteCreateRequest, v *validator.Validator) (int64, error)
+	UpdateAthlete(ctx context.Context, req entity.AthleteUpdateRequest, v *validator.Validator) (*entity.Athlete, error)
 	DeleteAthlete(ctx context.Context, athleteID uuid.UUID) error
 	DeleteAthletesForRace(ctx context.Context, raceID, eventID uuid.UUID) error
 	FromCSVtoRequestAthlete(ctx context.Context, raceID uuid.UUID, data []*AthleteCSV) ([]entity.AthleteCreateRequest, error)
+	OverrideStatus(ctx context.Context, raceID, athleteID uuid.UUID, req entity.StatusOverrideRequest) (*entity.AthleteStatus, error)
+	SetAthleteCategories(ctx context.Context, raceID, athleteID uuid.UUID, categories []uuid.UUID, v *validator.Validator) (*entity.Athlete, error)
+	GenerateStartTimes(ctx context.Context, raceID uuid.UUID, req entity.StartTimesRequest, v *validator.Validator) ([]*entity.AthleteStartTime, error)
+	GetStartList(ctx context.Context, raceID uuid.UUID, eventID uuid.NullUUID) ([]*entity.StartListEntry, error)
 }
 
 type AthleteRepo interface {
@@ -31,12 +37,25 @@ type AthleteRepo interface {
 	DeleteAthlete(ctx context.Context, a *entity.Athlete) error
 	DeleteAthletesForRace(ctx context.Context, raceID uuid.UUID) error
 	DeleteAthletesForRaceWithEventID(ctx context.Context, raceID, eventID uuid.UUID) error
-	GetRecordsAndSplitsForEventAthlete(ctx context.Context, raceID, eventID uuid.UUID) ([]database.GetEventAthleteRecordsCRow, []*entity.Split, error)
+	GetRecordsAndSplitsForEventAthlete(ctx context.Context, raceID, eventID uuid.UUID, chips []int) ([]database.GetEventAthleteRecordsCRow, []*entity.Split, error)
 	GetManualAthleteSplits(ctx context.Context, raceID, eventID uuid.UUID) (map[uuid.UUID][]*entity.AthleteSplit, error)
 	SaveAthleteSplits(ctx context.Context, as []database.CreateAthleteSplitsParams) error
 	GetEventIDsWithWavesStarted(ctx context.Context, raceID uuid.UUID) ([]uuid.UUID, error)
-	SaveBulkAthleteSplits(ctx context.Context, raceID uuid.UUID, as []*entity.AthleteSplit) error
-	UpdateStatus(ctx context.Context, status entity.Status, raceID, eventID, athleteID uuid.UUID) error
+	SaveBulkAthleteSplits(ctx context.Context, raceID uuid.UUID, as []*entity.AthleteSplit, ss []*entity.AthleteStatus) error
+	UpsertAthleteSplits(ctx context.Context, raceID uuid.UUID, as []*entity.AthleteSplit, ss []*entity.AthleteStatus) error
+	GetChipsWithNewRecords(ctx context.Context, raceID uuid.UUID) (chips []int, snapshot int64, watermarkValid bool, err error)
+	GetReadsSnapshot(ctx context.Context) (int64, error)
+	SetResultsWatermark(ctx context.Context, raceID uuid.UUID, snapshot int64) error
+	UpdateStatus(ctx context.Context, status entity.Status, reason string, raceID, eventID, athleteID uuid.UUID) error
+	UpdateStatuses(ctx context.Context, raceID uuid.UUID, ss []*entity.AthleteStatus) error
+	OverrideStatus(ctx context.Context, st *entity.AthleteStatus, raceID uuid.UUID) (bool, error)
+	GetFinishResultsForEvent(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.FinishResult, error)
+	GetEventAthleteProgress(ctx context.Context, raceID, eventID uuid.UUID, status entity.Status) ([]*entity.AthleteProgress, error)
+	GetAthleteReaderRecords(ctx context.Context, raceID uuid.UUID, chip int) ([]*entity.ReadDiagnostic, error)
+	SetAthleteCategories(ctx context.Context, a *entity.Athlete) error
+	GetCategoryResults(ctx context.Context, raceID, categoryID, splitID uuid.UUID) ([]*entity.CategoryResult, error)
+	GetStartList(ctx context.Context, raceID uuid.UUID, eventID, waveID uuid.NullUUID) ([]*entity.StartListEntry, error)
+	SetStartTimes(ctx context.Context, raceID uuid.UUID, st []*entity.AthleteStartTime) error
 }
 
 const TimeFormatDDMMYYYY = "02.01.2006"
@@ -45,33 +64,52 @@ type AthleteService struct {
 	log         *logger.Logger
 	athleteRepo AthleteRepo
 	raceRepo    RaceConfigurator
+	recalc      RecalcQueue
 }
 
-func NewAthleteService(logger *logger.Logger, athleteRepo AthleteRepo, raceRepo RaceConfigurator) *AthleteService {
+func NewAthleteService(logger *logger.Logger, athleteRepo AthleteRepo, raceRepo RaceConfigurator, recalc RecalcQueue) *AthleteService {
 	return &AthleteService{
 		log:         logger,
 		athleteRepo: athleteRepo,
 		raceRepo:    raceRepo,
+		recalc:      recalc,
 	}
 }
 
 func (ps *AthleteService) GetAthleteByID(ctx context.Context, athleteID uuid.UUID) *entity.Athlete {
 	p, err := ps.athleteRepo.GetAthleteByID(ctx, athleteID)
+	if err != nil || p == nil {
+		return nil
+	}
+	loc, err := ps.raceLocation(ctx, p.RaceID)
 	if err != nil {
 		return nil
 	}
+	p.StartTime = entity.InLocation(p.StartTime, loc)
 	return p
 }
 
-func (as *AthleteService) CreateBulkAthletes(ctx context.Context, reqs []entity.AthleteCreateRequest) (int64, error) {
+// CreateBulkAthletes saves athletes imported from CSV. Errors of every row are reported at athletes[i],
+// nothing is saved if any row is invalid.
+func (as *AthleteService) CreateBulkAthletes(ctx context.Context, reqs []entity.AthleteCreateRequest, v *validator.Validator) (int64, error) {
+	v.CheckField(len(reqs) > 0, "athletes", validator.CodeRequired, "must be at least one")
 	athletes := make([]*entity.Athlete, 0, len(reqs))
-	for _, r := range reqs {
-		a, err := entity.NewAthlete(r)
-		if err != nil {
-			return 0, fmt.Errorf("error creating athlete from CSV: validation error: %s", err.Error())
+	for i, r := range reqs {
+		iv := validator.New()
+		a := entity.NewAthlete(r, iv)
+		if !iv.Valid() {
+			v.Item("athletes", i).Merge(iv)
+			continue
 		}
 		athletes = append(athletes, a)
 	}
+	if !v.Valid() {
+		return 0, validator.ErrValidation
+	}
+	err := as.checkChange(ctx, athletes[0].RaceID)
+	if err != nil {
+		return 0, err
+	}
 
 	createdCount, err := as.athleteRepo.SaveAthleteBulk(ctx, athletes[0].RaceID, athletes)
 	if err != nil {
@@ -80,16 +118,30 @@ func (as *AthleteService) CreateBulkAthletes(ctx context.Context, reqs []entity.
 	return createdCount, nil
 }
 
-func (ps *AthleteService) CreateAthlete(ctx context.Context, req entity.AthleteCreateRequest) (*entity.Athlete, error) {
-	p, err := entity.NewAthlete(req)
+func (ps *AthleteService) CreateAthlete(ctx context.Context, req entity.AthleteCreateRequest, v *validator.Validator) (*entity.Athlete, error) {
+	p := entity.NewAthlete(req, v)
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	err := ps.checkChange(ctx, p.RaceID)
 	if err != nil {
 		return nil, err
 	}
 
+	p.Categories, err = ps.checkCustomCategories(ctx, p.RaceID, p.EventID, p.Categories, v)
+	if err != nil {
+		return nil, err
+	}
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+
+	// category set explicitly is kept when event categories change
+	p.CategoryLocked = req.CategoryID.Valid
 	if !req.CategoryID.Valid {
-		err := ps.assignCategory(ctx, p)
+		err = ps.assignCategory(ctx, p)
 		if err != nil {
-			fmt.Println("error assigning category", err)
+			return nil, fmt.Errorf("createAthlete: %w", err)
 		}
 	}
 
@@ -104,22 +156,44 @@ func (ps *AthleteService) CreateAthlete(ctx context.Context, req entity.AthleteC
 func (ps *AthleteService) assignCategory(ctx context.Context, p *entity.Athlete) error {
 	catID, _, err := ps.athleteRepo.GetCategoryFor(ctx, p)
 	if err != nil {
-		return fmt.Errorf("error assigning category for athlete with bib %d: %s", p.Bib, err.Error())
+		return fmt.Errorf("error assigning category for athlete with bib %d: %w", p.Bib, err)
 	}
 	p.CategoryID = catID
 	return nil
 }
 
-func (ps *AthleteService) UpdateAthlete(ctx context.Context, req entity.AthleteUpdateRequest) (*entity.Athlete, error) {
+func (ps *AthleteService) UpdateAthlete(ctx context.Context, req entity.AthleteUpdateRequest, v *validator.Validator) (*entity.Athlete, error) {
 	p, err := ps.athleteRepo.GetAthleteByID(ctx, req.ID)
 	if err != nil {
+		return nil, fmt.Errorf("updateAthlete: error getting athlete %s: %w", req.ID, err)
+	}
+	if p == nil {
 		return nil, fmt.Errorf("updateAthlete: athlete with ID %s not found", req.ID)
 	}
-	newP, err := entity.NewAthlete(req.AthleteCreateRequest)
+	newP := entity.NewAthlete(req.AthleteCreateRequest, v)
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	newP.ID = p.ID
+	err = ps.checkChange(ctx, p.RaceID)
+	if err != nil {
+		return nil, err
+	}
+	newP.Categories, err = ps.checkCustomCategories(ctx, newP.RaceID, newP.EventID, newP.Categories, v)
 	if err != nil {
 		return nil, err
 	}
-	newP.ID = p.ID
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	// category sent back unchanged is kept assigned automatically unless it has been set explicitly before
+	newP.CategoryLocked = req.CategoryID.Valid && (p.CategoryLocked || req.CategoryID != p.CategoryID)
+	if !newP.CategoryLocked {
+		err = ps.assignCategory(ctx, newP)
+		if err != nil {
+			return nil, fmt.Errorf("updateAthlete: %w", err)
+		}
+	}
 
 	err = ps.athleteRepo.SaveAthlete(ctx, newP)
 	if err != nil {
@@ -136,6 +210,10 @@ func (ps *AthleteService) DeleteAthlete(ctx context.Context, athleteID uuid.UUID
 	if a == nil {
 		return fmt.Errorf("athlete with ID %s not found", athleteID)
 	}
+	err = ps.checkChange(ctx, a.RaceID)
+	if err != nil {
+		return err
+	}
 	err = ps.athleteRepo.DeleteAthlete(ctx, a)
 	if err != nil {
 		return fmt.Errorf("delete athlete: error deleting athlete %s from DB", athleteID)
@@ -143,6 +221,93 @@ func (ps *AthleteService) DeleteAthlete(ctx context.Context, athleteID uuid.UUID
 	return nil
 }
 
+// OverrideStatus sets athlete's status by officials decision. Status is locked by default
+// so it is not changed by further results calculation until unlocked.
+func (ps *AthleteService) OverrideStatus(ctx context.Context, raceID, athleteID uuid.UUID, req entity.StatusOverrideRequest) (*entity.AthleteStatus, error) {
+	a, err := ps.athleteRepo.GetAthleteByID(ctx, athleteID)
+	if err != nil {
+		return nil, fmt.Errorf("override status: error getting athlete %s: %w", athleteID, err)
+	}
+	if a == nil || a.RaceID != raceID {
+		return nil, nil
+	}
+	err = ps.checkChange(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	st := &entity.AthleteStatus{
+		AthleteID: a.ID,
+		EventID:   a.EventID,
+		Status:    req.Status,
+		Reason:    req.Reason,
+		Locked:    req.Locked == nil || *req.Locked,
+	}
+	ok, err := ps.athleteRepo.OverrideStatus(ctx, st, raceID)
+	if err != nil {
+		return nil, fmt.Errorf("override status: error saving status for athlete %s: %w", athleteID, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+	return st, nil
+}
+
+// SetAthleteCategories replaces custom categories athlete is member of and queues recalculation of results
+// of the race so category ranks follow the membership. Age category is not affected.
+func (ps *AthleteService) SetAthleteCategories(ctx context.Context, raceID, athleteID uuid.UUID, categories []uuid.UUID, v *validator.Validator) (*entity.Athlete, error) {
+	a, err := ps.athleteRepo.GetAthleteByID(ctx, athleteID)
+	if err != nil {
+		return nil, fmt.Errorf("set athlete categories: error getting athlete %s: %w", athleteID, err)
+	}
+	if a == nil || a.RaceID != raceID {
+		return nil, nil
+	}
+	err = ps.checkChange(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	a.Categories, err = ps.checkCustomCategories(ctx, raceID, a.EventID, categories, v)
+	if err != nil {
+		return nil, err
+	}
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	err = ps.athleteRepo.SetAthleteCategories(ctx, a)
+	if err != nil {
+		return nil, fmt.Errorf("set athlete categories: error saving categories for athlete %s: %w", athleteID, err)
+	}
+	ps.recalculate(raceID)
+	return a, nil
+}
+
+// checkCustomCategories checks that all categories are custom categories of the event and returns them without duplicates
+func (ps *AthleteService) checkCustomCategories(ctx context.Context, raceID, eventID uuid.UUID, categories []uuid.UUID, v *validator.Validator) ([]uuid.UUID, error) {
+	if len(categories) == 0 {
+		return []uuid.UUID{}, nil
+	}
+	rc, err := ps.raceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	var eventCategories []*entity.Category
+	if rc != nil {
+		if idx := slices.IndexFunc(rc.Events, func(e *entity.Event) bool { return e.ID == eventID }); idx != -1 {
+			eventCategories = rc.Events[idx].Categories
+		}
+	}
+	for i, id := range categories {
+		v.Item("categories", i).CheckField(slices.ContainsFunc(eventCategories, func(c *entity.Category) bool {
+			return c.ID == id && c.Kind == entity.CategoryKindCustom
+		}), "", validator.CodeNotFound, fmt.Sprintf("%s is not a custom category of athlete's event", id))
+	}
+	res := slices.Clone(categories)
+	slices.SortFunc(res, func(a, b uuid.UUID) int {
+		return slices.Compare(a[:], b[:])
+	})
+	return slices.Compact(res), nil
+}
+
 func (ps *AthleteService) DeleteAthleteBulk(ctx context.Context, raceID uuid.UUID, ids []uuid.UUID) []error {
 	var errors []error
 	for _, id := range ids {
@@ -155,6 +320,10 @@ func (ps *AthleteService) DeleteAthleteBulk(ctx context.Context, raceID uuid.UUI
 }
 
 func (as *AthleteService) DeleteAthletesForRace(ctx context.Context, raceID, eventID uuid.UUID) error {
+	err := as.checkChange(ctx, raceID)
+	if err != nil {
+		return err
+	}
 	if eventID == uuid.Nil {
 		err := as.athleteRepo.DeleteAthletesForRace(ctx, raceID)
 		if err != nil {
@@ -207,15 +376,37 @@ func (as *AthleteService) FromCSVtoRequestAthlete(ctx context.Context, raceID uu
 		gender := entity.GenderFrom(a.Gender)
 
 		// assign categoryID
-		categoryIdx := slices.IndexFunc(raceModel.Events[eventIdx].Categories, func(c *entity.Category) bool {
-			return c.Valid(gender, dob)
-		})
-		var athleteCatID uuid.NullUUID
-		if categoryIdx != -1 {
-			athleteCatID = uuid.NullUUID{
-				UUID:  raceModel.Events[eventIdx].Categories[categoryIdx].ID,
-				Valid: true,
+		athleteCatID := entity.CategoryFor(raceModel.Events[eventIdx].Categories, gender, dob)
+
+		// custom categories are listed by name
+		customCats := []uuid.UUID{}
+		for _, name := range strings.Split(a.Categories, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			catIdx := slices.IndexFunc(raceModel.Events[eventIdx].Categories, func(c *entity.Category) bool {
+				return c.Kind == entity.CategoryKindCustom && c.Name == name
+			})
+			if catIdx == -1 {
+				return nil, fmt.Errorf("custom category with name %s does not exists. Import aborted", name)
+			}
+			customCats = append(customCats, raceModel.Events[eventIdx].Categories[catIdx].ID)
+		}
+		var startTime time.Time
+		if a.StartTime != "" {
+			startTime, err = parseStartTime(a.StartTime, raceModel.Events[eventIdx].EventDate, raceModel.Location())
+			if err != nil {
+				return nil, fmt.Errorf("invalid start time %s for athlete with bib %d. Import aborted", a.StartTime, a.Bib)
+			}
+		}
+		var handicap *time.Duration
+		if a.Handicap != "" {
+			h, err := parseHandicap(a.Handicap)
+			if err != nil {
+				return nil, fmt.Errorf("invalid handicap %s for athlete with bib %d. Import aborted", a.Handicap, a.Bib)
 			}
+			handicap = &h
 		}
 		r := entity.AthleteCreateRequest{
 			RaceID:      raceID,
@@ -228,6 +419,9 @@ func (as *AthleteService) FromCSVtoRequestAthlete(ctx context.Context, raceID uu
 			Gender:      gender,
 			DateOfBirth: dob,
 			CategoryID:  athleteCatID,
+			Categories:  customCats,
+			StartTime:   startTime,
+			Handicap:    handicap,
 			Phone:       a.Phone,
 			Comments:    a.Comments,
 		}
@@ -236,3 +430,21 @@ func (as *AthleteService) FromCSVtoRequestAthlete(ctx context.Context, raceID uu
 	fmt.Printf("Processing CSV took: %v\n", time.Since(start))
 	return res, nil
 }
+
+// recalculate queues recalculation of results of race after athletes' category membership changed,
+// so the change is returned without waiting for calculation of the whole race
+func (as *AthleteService) recalculate(raceID uuid.UUID) {
+	if as.recalc == nil {
+		return
+	}
+	as.recalc.Queue(raceID)
+}
+
+// checkChange returns error if athletes of the race can't be changed in its current status
+func (as *AthleteService) checkChange(ctx context.Context, raceID uuid.UUID) error {
+	race, err := as.raceRepo.GetRace(ctx, raceID)
+	if err != nil || race == nil {
+		return err
+	}
+	return race.Status.CheckChange()
+}
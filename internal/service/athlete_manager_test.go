@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// memAthleteRepo keeps a single athlete and assigns the same category to every athlete
+type memAthleteRepo struct {
+	AthleteRepo
+	athlete  *entity.Athlete
+	category uuid.NullUUID
+}
+
+func (r *memAthleteRepo) GetAthleteByID(ctx context.Context, athleteID uuid.UUID) (*entity.Athlete, error) {
+	if r.athlete == nil || r.athlete.ID != athleteID {
+		return nil, nil
+	}
+	a := *r.athlete
+	return &a, nil
+}
+
+func (r *memAthleteRepo) GetCategoryFor(ctx context.Context, p *entity.Athlete) (uuid.NullUUID, bool, error) {
+	return r.category, r.category.Valid, nil
+}
+
+func (r *memAthleteRepo) SaveAthlete(ctx context.Context, p *entity.Athlete) error {
+	r.athlete = p
+	return nil
+}
+
+type memRaceConfigurator struct {
+	RaceConfigurator
+}
+
+func (r *memRaceConfigurator) GetRace(ctx context.Context, raceID uuid.UUID) (*entity.Race, error) {
+	return &entity.Race{ID: raceID, Status: entity.RaceStatusSetup}, nil
+}
+
+func TestUpdateAthleteCategoryLock(t *testing.T) {
+	auto := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	other := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+	tests := []struct {
+		name         string
+		locked       bool
+		categoryID   uuid.NullUUID
+		wantCategory uuid.NullUUID
+		wantLocked   bool
+	}{
+		{
+			name:         "assigned category sent back",
+			categoryID:   auto,
+			wantCategory: auto,
+		},
+		{
+			name:         "category changed",
+			categoryID:   other,
+			wantCategory: other,
+			wantLocked:   true,
+		},
+		{
+			name:         "locked category sent back",
+			locked:       true,
+			categoryID:   other,
+			wantCategory: other,
+			wantLocked:   true,
+		},
+		{
+			name:         "locked category cleared",
+			locked:       true,
+			wantCategory: auto,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &entity.Athlete{
+				ID:             uuid.New(),
+				RaceID:         uuid.New(),
+				EventID:        uuid.New(),
+				WaveID:         uuid.New(),
+				Bib:            1,
+				Chip:           1,
+				CategoryID:     auto,
+				CategoryLocked: tt.locked,
+			}
+			if tt.locked {
+				a.CategoryID = tt.categoryID
+			}
+			repo := &memAthleteRepo{athlete: a, category: auto}
+			svc := NewAthleteService(nil, repo, &memRaceConfigurator{}, nil)
+			req := entity.AthleteUpdateRequest{
+				ID: a.ID,
+				AthleteCreateRequest: entity.AthleteCreateRequest{
+					RaceID:     a.RaceID,
+					EventID:    a.EventID,
+					WaveID:     a.WaveID,
+					Bib:        a.Bib,
+					Chip:       a.Chip,
+					CategoryID: tt.categoryID,
+				},
+			}
+
+			got, err := svc.UpdateAthlete(context.Background(), req, validator.New())
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantCategory, got.CategoryID)
+			assert.Equal(t, tt.wantLocked, got.CategoryLocked)
+		})
+	}
+}
+
+func TestCreateBulkAthletesReportsRows(t *testing.T) {
+	raceID, eventID, waveID := uuid.New(), uuid.New(), uuid.New()
+	reqs := []entity.AthleteCreateRequest{
+		{RaceID: raceID, EventID: eventID, WaveID: waveID, Bib: 1, Chip: 1},
+		{RaceID: raceID, EventID: eventID, WaveID: waveID, Bib: 0, Chip: 2},
+		{RaceID: raceID, EventID: eventID, Bib: 3, Chip: 0},
+	}
+	svc := NewAthleteService(nil, &memAthleteRepo{}, &memRaceConfigurator{}, nil)
+	v := validator.New()
+
+	_, err := svc.CreateBulkAthletes(context.Background(), reqs, v)
+	assert.ErrorIs(t, err, validator.ErrValidation)
+	assert.Equal(t, []validator.FieldError{
+		{Path: "athletes[1].bib", Code: validator.CodeOutOfRange, Message: "must be greater than 0"},
+		{Path: "athletes[2].wave_id", Code: validator.CodeRequired, Message: "athlete wave must be assigned"},
+		{Path: "athletes[2].chip", Code: validator.CodeOutOfRange, Message: "must be greater than 0"},
+	}, v.FieldErrors())
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+)
+
+// BackupRace returns snapshot of config, athletes, reads and results of the race. Returns nil if race is not found.
+func (rs RaceService) BackupRace(ctx context.Context, raceID uuid.UUID) (*entity.RaceBackup, error) {
+	return rs.repo.BackupRace(ctx, raceID)
+}
+
+// RestoreRace replaces race of backup b with its snapshot, or creates the race if it doesn't exist. IDs of
+// all rows are kept. Race being timed or with official results is replaced only with force.
+func (rs RaceService) RestoreRace(ctx context.Context, b *entity.RaceBackup, force bool) (*entity.Race, error) {
+	var races []struct {
+		ID uuid.UUID
+	}
+	err := json.Unmarshal(b.Tables["races"], &races)
+	if err != nil || len(races) != 1 || races[0].ID != b.RaceID {
+		return nil, fmt.Errorf("%w: races must contain race %s of manifest", entity.ErrRaceBackup, b.RaceID)
+	}
+	prev, err := rs.repo.GetRaceInfo(ctx, b.RaceID)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil {
+		err = prev.Status.CheckDelete(force)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = rs.repo.RestoreRace(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	rs.log.Info("race restored from backup", "race", b.RaceID, "backup_created_at", b.CreatedAt, "replaced", prev != nil)
+	return rs.GetRace(ctx, b.RaceID)
+}
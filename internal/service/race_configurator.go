@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
@@ -15,37 +17,92 @@ import (
 type ValidationErrors map[string]string
 
 type RaceConfigurator interface {
-	SaveRaceConfig(ctx context.Context, rc *dto.RaceModelDTO, v *validator.Validator) error
+	SaveRaceConfig(ctx context.Context, rc *dto.RaceModelDTO, dryRun, force bool, v *validator.Validator) (*entity.RaceConfigDiff, []*entity.CategoryReassignment, error)
 	GetRaces(ctx context.Context) ([]*entity.Race, error)
 	CreateRace(ctx context.Context, req *dto.RaceDTO, v *validator.Validator) (*entity.Race, error)
-	DeleteRace(ctx context.Context, raceID uuid.UUID) error
+	DeleteRace(ctx context.Context, raceID uuid.UUID, force bool) error
+	CloneRace(ctx context.Context, raceID uuid.UUID, req entity.RaceCloneRequest, v *validator.Validator) (*entity.RaceModel, error)
+	ExportRaceConfig(ctx context.Context, raceID uuid.UUID) (*dto.RaceConfigDocument, error)
+	ImportRaceConfig(ctx context.Context, doc *dto.RaceConfigDocument, targetID uuid.UUID, dryRun, force bool, v *validator.Validator) (*entity.RaceConfigDiff, []*entity.CategoryReassignment, error)
+	BackupRace(ctx context.Context, raceID uuid.UUID) (*entity.RaceBackup, error)
+	RestoreRace(ctx context.Context, b *entity.RaceBackup, force bool) (*entity.Race, error)
+	GetRace(ctx context.Context, raceID uuid.UUID) (*entity.Race, error)
+	ChangeRaceStatus(ctx context.Context, raceID uuid.UUID, req entity.RaceStatusRequest, v *validator.Validator) (*entity.RaceStatusChange, error)
+	GetRaceStatusHistory(ctx context.Context, raceID uuid.UUID) ([]*entity.RaceStatusChange, error)
 	GetRaceConfig(ctx context.Context, raceID uuid.UUID) (*entity.RaceModel, error)
 	GetWavesForRace(ctx context.Context, raceID uuid.UUID) ([]*entity.Wave, error)
 	StartWave(ctx context.Context, raceID uuid.UUID, startInfo entity.WaveStart) (time.Time, bool, error)
 	GetEventIDsWithWaveStarted(ctx context.Context, raceID uuid.UUID) ([]uuid.UUID, error)
+	LaunchWaves(ctx context.Context, raceID uuid.UUID, req entity.WavesLaunch, v *validator.Validator) ([]*entity.Wave, error)
+	ResetWave(ctx context.Context, raceID, waveID uuid.UUID, req entity.WaveReset, v *validator.Validator) (*entity.Wave, error)
+	AdjustWaveStart(ctx context.Context, raceID, waveID uuid.UUID, req entity.WaveAdjust, v *validator.Validator) (*entity.Wave, error)
+	CreateEvent(ctx context.Context, raceID uuid.UUID, req *dto.EventModelDTO, v *validator.Validator) (*entity.Event, error)
+	UpdateEvent(ctx context.Context, raceID, eventID uuid.UUID, req *dto.EventDTO, v *validator.Validator) (*entity.Event, error)
+	DeleteEvent(ctx context.Context, raceID, eventID uuid.UUID, force bool) (bool, error)
+	CreateSplit(ctx context.Context, raceID, eventID uuid.UUID, req *dto.SplitDTO, v *validator.Validator) (*entity.Split, error)
+	UpdateSplit(ctx context.Context, raceID, eventID, splitID uuid.UUID, req *dto.SplitDTO, force bool, v *validator.Validator) (*entity.Split, error)
+	DeleteSplit(ctx context.Context, raceID, eventID, splitID uuid.UUID, force bool, v *validator.Validator) (bool, error)
+	CreateWave(ctx context.Context, raceID, eventID uuid.UUID, req *dto.WaveDTO, v *validator.Validator) (*entity.Wave, error)
+	UpdateWave(ctx context.Context, raceID, eventID, waveID uuid.UUID, req *dto.WaveDTO, v *validator.Validator) (*entity.Wave, error)
+	DeleteWave(ctx context.Context, raceID, eventID, waveID uuid.UUID, v *validator.Validator) (bool, error)
+	CreateCategory(ctx context.Context, raceID, eventID uuid.UUID, req *dto.CategoryDTO, v *validator.Validator) (*entity.Category, error)
+	UpdateCategory(ctx context.Context, raceID, eventID, categoryID uuid.UUID, req *dto.CategoryDTO, v *validator.Validator) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, raceID, eventID, categoryID uuid.UUID, v *validator.Validator) (bool, error)
+	CreateTimeReader(ctx context.Context, raceID uuid.UUID, req *dto.TimeReaderDTO, v *validator.Validator) (*entity.TimeReader, error)
+	UpdateTimeReader(ctx context.Context, raceID, readerID uuid.UUID, req *dto.TimeReaderDTO, force bool, v *validator.Validator) (*entity.TimeReader, error)
+	DeleteTimeReader(ctx context.Context, raceID, readerID uuid.UUID, v *validator.Validator) (bool, error)
+	GetWaveAudit(ctx context.Context, raceID uuid.UUID) ([]*entity.WaveAuditEntry, error)
 }
 
 type RaceRepo interface {
-	SaveRaceConfig(ctx context.Context, r *entity.Race, trs []*entity.TimeReader, ee []*entity.Event) error
+	SaveRaceConfig(ctx context.Context, rm *entity.RaceModel, check func(prev *entity.RaceModel, usage *entity.ConfigUsage) (*entity.RaceConfigDiff, error)) error
+	GetConfigUsage(ctx context.Context, raceID uuid.UUID) (*entity.ConfigUsage, error)
 	GetRaceConfig(ctx context.Context, raceID uuid.UUID) (*entity.RaceModel, error)
+	GetRaceInfo(ctx context.Context, raceID uuid.UUID) (*entity.Race, error)
 	GetRaces(ctx context.Context) ([]*entity.Race, error)
+	GetRacesWithStatus(ctx context.Context, status entity.RaceStatus) ([]*entity.Race, error)
+	GetRacesWithScheduledWaves(ctx context.Context, now time.Time) ([]*entity.Race, error)
 	SaveRaceInfo(ctx context.Context, race *entity.Race) error
 	SaveWave(ctx context.Context, wave *entity.Wave) error
 	DeleteRace(ctx context.Context, raceID uuid.UUID) error
+	SetRaceStatus(ctx context.Context, c *entity.RaceStatusChange) (bool, error)
+	GetRaceStatusHistory(ctx context.Context, raceID uuid.UUID) ([]*entity.RaceStatusChange, error)
 	GetWavesForRace(ctx context.Context, raceID uuid.UUID) ([]*entity.Wave, error)
 	GetWaveByID(ctx context.Context, waveID uuid.UUID) (*entity.Wave, error)
+	LaunchWavesByTrigger(ctx context.Context, raceID uuid.UUID) ([]*entity.Wave, error)
+	LaunchScheduledWaves(ctx context.Context, raceID uuid.UUID, now time.Time) ([]*entity.Wave, error)
+	SaveWaveChanges(ctx context.Context, raceID uuid.UUID, changes []*entity.WaveAuditEntry) ([]*entity.Wave, error)
+	GetWaveAudit(ctx context.Context, raceID uuid.UUID) ([]*entity.WaveAuditEntry, error)
 	GetEventIDsWithWavesStarted(ctx context.Context, raceID uuid.UUID) ([]uuid.UUID, error)
+	GetAthletesForCategories(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.Athlete, error)
+	UpdateAthleteCategories(ctx context.Context, raceID, eventID uuid.UUID, athletes []*entity.Athlete) (int, error)
+	SaveEvent(ctx context.Context, raceID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) error
+	DeleteEvent(ctx context.Context, raceID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) error
+	DeleteSplit(ctx context.Context, raceID, splitID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) error
+	DeleteWave(ctx context.Context, raceID, waveID uuid.UUID, check func(rc *entity.RaceModel, athletes int) (*entity.Event, error)) error
+	DeleteCategory(ctx context.Context, raceID, categoryID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) error
+	SaveTimeReader(ctx context.Context, tr *entity.TimeReader) error
+	DeleteTimeReader(ctx context.Context, readerID uuid.UUID) error
+	BackupRace(ctx context.Context, raceID uuid.UUID) (*entity.RaceBackup, error)
+	RestoreRace(ctx context.Context, b *entity.RaceBackup) error
+}
+
+// RaceResultsCalculator recalculates results of the race after changes affecting them
+type RaceResultsCalculator interface {
+	CalculateSplitResults(ctx context.Context, raceID uuid.UUID) error
 }
 
 type RaceService struct {
-	repo RaceRepo
-	log  *logger.Logger
+	repo    RaceRepo
+	log     *logger.Logger
+	results RaceResultsCalculator
 }
 
-func NewRaceService(logger *logger.Logger, repo RaceRepo) *RaceService {
+func NewRaceService(logger *logger.Logger, repo RaceRepo, results RaceResultsCalculator) *RaceService {
 	return &RaceService{
-		log:  logger,
-		repo: repo,
+		log:     logger,
+		repo:    repo,
+		results: results,
 	}
 }
 
@@ -77,59 +134,185 @@ func (rs RaceService) GetRaceConfig(ctx context.Context, raceID uuid.UUID) (*ent
 	if rconfig == nil {
 		return nil, nil
 	}
-
+	rconfig.InLocation()
 	return rconfig, nil
 }
 
 // FIXME return ErrValidation when v is not Valid
-func (rs RaceService) SaveRaceConfig(ctx context.Context, rc *dto.RaceModelDTO, v *validator.Validator) error {
+// SaveRaceConfig replaces race configuration with rc. Items of saved config missing in rc are deleted together
+// with their results, deleting wave with athletes is rejected. With dryRun the diff is returned without saving.
+// Athletes of events whose categories changed are matched against the new categories, except for athletes
+// with manually locked category, and results are recalculated if anyone moved, handicap of any category
+// changed or splits or waves changed. Config of official or archived race can't be changed, moving splits
+// to other readers or deleting them once timing has begun must be forced.
+func (rs RaceService) SaveRaceConfig(ctx context.Context, rc *dto.RaceModelDTO, dryRun, force bool, v *validator.Validator) (*entity.RaceConfigDiff, []*entity.CategoryReassignment, error) {
 	race := entity.NewRace(rc.RaceDTO, v)
 	if !v.Valid() {
-		return validator.ErrValidation
+		return nil, nil, validator.ErrValidation
 	}
 
-	v.Check(len(rc.TimeReaders) != 0, "time readers", "race must have at least one time reader")
+	v.CheckField(len(rc.TimeReaders) != 0, "time_readers", validator.CodeRequired, "race must have at least one time reader")
 	// no point for further validation since there are no time readers
 	if !v.Valid() {
-		return validator.ErrValidation
+		return nil, nil, validator.ErrValidation
 	}
 
 	timeReaders := make([]*entity.TimeReader, 0, len(rc.TimeReaders))
-	var timeReadersNames []string
-	for _, tr := range rc.TimeReaders {
-		timeReader := entity.NewTimeReader(tr, v)
-		if !v.Valid() {
-			return validator.ErrValidation
-		}
-		timeReadersNames = append(timeReadersNames, tr.ReaderName)
-		timeReaders = append(timeReaders, timeReader)
+	readerNames := make(map[string]bool, len(rc.TimeReaders))
+	for i, tr := range rc.TimeReaders {
+		rv := v.Item("time_readers", i)
+		timeReaders = append(timeReaders, entity.NewTimeReader(tr, rv))
+		rv.CheckField(!readerNames[tr.ReaderName], "reader_name", validator.CodeNotUnique, "must be unique")
+		readerNames[tr.ReaderName] = true
 	}
-	v.Check(validator.Unique(timeReadersNames), "time readers names", "must be unique")
 
+	// every event is checked, so problems of all of them are returned at once
 	events := make([]*entity.Event, 0, len(rc.Events))
-	for _, e := range rc.Events {
-		event := entity.NewEvent(e.EventDTO, e.Splits, rc.TimeReaders, e.Waves, e.Categories, v)
+	for i, e := range rc.Events {
+		events = append(events, entity.NewEvent(e.EventDTO, e.Splits, rc.TimeReaders, e.Waves, e.Categories, race.Location(), v.Item("events", i)))
+	}
+	if !v.Valid() {
+		return nil, nil, validator.ErrValidation
+	}
+
+	next := &entity.RaceModel{Race: race, TimeReaders: timeReaders, Events: events}
+	if dryRun {
+		prev, err := rs.repo.GetRaceConfig(ctx, race.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		var usage *entity.ConfigUsage
+		if prev != nil {
+			usage, err = rs.repo.GetConfigUsage(ctx, race.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		return entity.DiffRaceConfig(prev, next, usage), nil, nil
+	}
+
+	// diff is computed within the saving transaction, so items deleted by it can't get athletes
+	// or results concurrently
+	var prev *entity.RaceModel
+	var diff *entity.RaceConfigDiff
+	err := rs.repo.SaveRaceConfig(ctx, next, func(p *entity.RaceModel, usage *entity.ConfigUsage) (*entity.RaceConfigDiff, error) {
+		prev = p
+		diff = entity.DiffRaceConfig(prev, next, usage)
+		if prev != nil {
+			err := prev.Status.CheckChange()
+			if err == nil {
+				err = checkReadersChange(prev, next, force)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, c := range diff.Warnings() {
+			if c.Blocking {
+				// deleted item is missing in rc, so it is reported at the list of event it is deleted from
+				msg := fmt.Sprintf("%s %q: %s", c.Kind, c.Name, c.Warning)
+				if idx := slices.IndexFunc(rc.Events, func(e *dto.EventModelDTO) bool { return e.EventDTO != nil && e.ID == c.EventID }); idx != -1 {
+					v.Item("events", idx).AddFieldError("waves", validator.CodeInUse, msg)
+				} else {
+					v.AddFieldError("events", validator.CodeInUse, msg)
+				}
+			}
+		}
 		if !v.Valid() {
-			return validator.ErrValidation
+			return nil, validator.ErrValidation
+		}
+		return diff, nil
+	})
+	if err != nil {
+		if !errors.Is(err, validator.ErrValidation) && !errors.Is(err, entity.ErrRaceStatus) {
+			rs.log.Error("error saving race to repo", "error", err)
 		}
-		events = append(events, event)
+		return nil, nil, err
+	}
+	if prev == nil {
+		return diff, nil, nil
+	}
+	for _, c := range diff.Warnings() {
+		rs.log.Info("destructive race config change applied", "race", race.ID, "kind", c.Kind, "id", c.ID, "warning", c.Warning)
 	}
 
-	err := rs.repo.SaveRaceConfig(ctx, race, timeReaders, events)
+	var reassigned []*entity.CategoryReassignment
+	moved := 0
+	handicapsChanged := false
+	for _, e := range events {
+		idx := slices.IndexFunc(prev.Events, func(pe *entity.Event) bool {
+			return pe.ID == e.ID
+		})
+		// new event has no athletes yet
+		if idx == -1 {
+			continue
+		}
+		if entity.HandicapsChanged(prev.Events[idx].Categories, e.Categories) {
+			handicapsChanged = true
+		}
+		if !entity.CategoriesChanged(prev.Events[idx].Categories, e.Categories) {
+			continue
+		}
+		res, err := rs.reassignCategories(ctx, race.ID, e)
+		if err != nil {
+			rs.log.Error("error reassigning categories", "event", e.ID, "error", err)
+			return nil, nil, err
+		}
+		moved += res.Moved
+		reassigned = append(reassigned, res)
+	}
+	if moved > 0 || handicapsChanged || splitsOrWavesChanged(diff) {
+		rs.recalculate(ctx, race.ID)
+	}
+	return diff, reassigned, nil
+}
+
+func splitsOrWavesChanged(diff *entity.RaceConfigDiff) bool {
+	return slices.ContainsFunc(diff.Changes, func(c *entity.ConfigChange) bool {
+		return c.Kind == entity.ConfigItemSplit || c.Kind == entity.ConfigItemWave
+	})
+}
+
+func (rs RaceService) reassignCategories(ctx context.Context, raceID uuid.UUID, e *entity.Event) (*entity.CategoryReassignment, error) {
+	athletes, err := rs.repo.GetAthletesForCategories(ctx, raceID, e.ID)
 	if err != nil {
-		const msg = "error saving race to repo"
-		rs.log.Error(msg, "error", err)
-		return err
+		return nil, err
 	}
-	// BUG if event already exists then reassign categories and recalculate results
-	return nil
+	var changed []*entity.Athlete
+	for _, a := range athletes {
+		cat := entity.CategoryFor(e.Categories, a.Gender, a.DateOfBirth)
+		if cat != a.CategoryID {
+			a.CategoryID = cat
+			changed = append(changed, a)
+		}
+	}
+	moved, err := rs.repo.UpdateAthleteCategories(ctx, raceID, e.ID, changed)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.CategoryReassignment{
+		EventID: e.ID,
+		Checked: len(athletes),
+		Moved:   moved,
+	}, nil
 }
 
-func (rs RaceService) DeleteRace(ctx context.Context, raceID uuid.UUID) error {
-	err := rs.repo.DeleteRace(ctx, raceID)
+// DeleteRace removes race with all its athletes and results. Race which is timed or has results
+// is deleted only with force.
+func (rs RaceService) DeleteRace(ctx context.Context, raceID uuid.UUID, force bool) error {
+	race, err := rs.repo.GetRaceInfo(ctx, raceID)
+	if err != nil || race == nil {
+		return err
+	}
+	err = race.Status.CheckDelete(force)
+	if err != nil {
+		return err
+	}
+	err = rs.repo.DeleteRace(ctx, raceID)
 	if err != nil {
 		return fmt.Errorf("error deleting race: %w", err)
 	}
+	rs.log.Info("race deleted", "race", raceID, "status", race.Status, "forced", force)
 	return nil
 }
 
@@ -141,10 +324,33 @@ func (rs RaceService) GetWavesForRace(ctx context.Context, raceID uuid.UUID) ([]
 	if waves == nil {
 		return nil, nil
 	}
+	loc, err := rs.raceLocation(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range waves {
+		w.StartTime = entity.InLocation(w.StartTime, loc)
+	}
 	return waves, nil
 }
 
+// raceLocation returns location of race timezone to render times of day in, UTC if race is not found
+func (rs RaceService) raceLocation(ctx context.Context, raceID uuid.UUID) (*time.Location, error) {
+	race, err := rs.repo.GetRaceInfo(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if race == nil {
+		return time.UTC, nil
+	}
+	return race.Location(), nil
+}
+
 func (rs RaceService) StartWave(ctx context.Context, raceID uuid.UUID, startInfo entity.WaveStart) (time.Time, bool, error) {
+	err := rs.checkChange(ctx, raceID)
+	if err != nil {
+		return time.Time{}, false, err
+	}
 	w, err := rs.repo.GetWaveByID(ctx, startInfo.WaveID)
 	if err != nil {
 		return time.Time{}, false, err
@@ -164,6 +370,34 @@ func (rs RaceService) StartWave(ctx context.Context, raceID uuid.UUID, startInfo
 	if err != nil {
 		return time.Time{}, true, fmt.Errorf("error saving wave: %w", err)
 	}
+	loc, err := rs.raceLocation(ctx, w.RaceID)
+	if err != nil {
+		return time.Time{}, true, err
+	}
+	return entity.InLocation(w.StartTime, loc), true, nil
+}
 
-	return w.StartTime, true, nil
+// CloneRace copies config of the race under new name with all dates moved by offset days. Athletes and results
+// are not copied. Returns nil if race is not found.
+func (rs RaceService) CloneRace(ctx context.Context, raceID uuid.UUID, req entity.RaceCloneRequest, v *validator.Validator) (*entity.RaceModel, error) {
+	v.CheckField(req.Name != "", "race_name", validator.CodeRequired, "must be provided")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	rc, err := rs.repo.GetRaceConfig(ctx, raceID)
+	if err != nil || rc == nil {
+		return nil, err
+	}
+	clone := rc.Clone(req.Name, req.OffsetDays)
+	err = rs.repo.SaveRaceConfig(ctx, clone, func(prev *entity.RaceModel, usage *entity.ConfigUsage) (*entity.RaceConfigDiff, error) {
+		// clone gets new IDs, so there is no saved config to change
+		return nil, nil
+	})
+	if err != nil {
+		rs.log.Error("error saving cloned race", "race", raceID, "error", err)
+		return nil, err
+	}
+	rs.log.Info("race cloned", "race", raceID, "clone", clone.ID, "offset_days", req.OffsetDays)
+	clone.InLocation()
+	return clone, nil
 }
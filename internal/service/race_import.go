@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// ExportRaceConfig returns config of the race as a document to import on another server. Times are written
+// in race timezone. Returns nil if race is not found.
+func (rs RaceService) ExportRaceConfig(ctx context.Context, raceID uuid.UUID) (*dto.RaceConfigDocument, error) {
+	rc, err := rs.GetRaceConfig(ctx, raceID)
+	if err != nil || rc == nil {
+		return nil, err
+	}
+	return &dto.RaceConfigDocument{
+		Version:      dto.RaceConfigVersion,
+		ExportedAt:   time.Now().In(rc.Location()),
+		RaceModelDTO: rc.DTO(),
+	}, nil
+}
+
+// ImportRaceConfig saves race config of the document the same way SaveRaceConfig does. Config is mapped onto
+// the race with targetID, onto the race with ID of the document if targetID is not set and the race exists,
+// otherwise a new race is created with IDs of the document. Returns nil diff if target race is not found.
+func (rs RaceService) ImportRaceConfig(ctx context.Context, doc *dto.RaceConfigDocument, targetID uuid.UUID, dryRun, force bool, v *validator.Validator) (*entity.RaceConfigDiff, []*entity.CategoryReassignment, error) {
+	doc.Validate(ctx, v)
+	if !v.Valid() {
+		return nil, nil, validator.ErrValidation
+	}
+	if targetID == uuid.Nil {
+		targetID = doc.ID
+	}
+	target, err := rs.repo.GetRaceConfig(ctx, targetID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target == nil && targetID != doc.ID {
+		return nil, nil, nil
+	}
+	if target != nil {
+		mapRaceConfig(doc.RaceModelDTO, target)
+	}
+	return rs.SaveRaceConfig(ctx, doc.RaceModelDTO, dryRun, force, v)
+}
+
+// mapRaceConfig moves imported config rd onto saved config of the race. Items matching saved ones by ID,
+// or by name if IDs differ between servers, take their IDs, so they are updated instead of being replaced
+// together with athletes and results. Other items get new IDs.
+func mapRaceConfig(rd *dto.RaceModelDTO, target *entity.RaceModel) {
+	rd.ID = target.ID
+	readerIDs := make(map[uuid.UUID]uuid.UUID, len(rd.TimeReaders))
+	for _, tr := range rd.TimeReaders {
+		id := matchID(target.TimeReaders, tr.ID, tr.ReaderName, func(t *entity.TimeReader) (uuid.UUID, string) {
+			return t.ID, t.ReaderName
+		})
+		readerIDs[tr.ID] = id
+		tr.ID, tr.RaceID = id, target.ID
+	}
+	mapReader := func(id uuid.UUID) uuid.UUID {
+		if mapped, ok := readerIDs[id]; ok {
+			return mapped
+		}
+		// unknown reader is left to validation
+		return id
+	}
+
+	for _, em := range rd.Events {
+		te := &entity.Event{}
+		eventID := matchID(target.Events, em.ID, em.Name, func(e *entity.Event) (uuid.UUID, string) {
+			return e.ID, e.Name
+		})
+		if idx := slices.IndexFunc(target.Events, func(e *entity.Event) bool { return e.ID == eventID }); idx != -1 {
+			te = target.Events[idx]
+		}
+		em.ID, em.RaceID = eventID, target.ID
+
+		splitIDs := make(map[uuid.UUID]uuid.UUID, len(em.Splits))
+		for _, s := range em.Splits {
+			id := matchID(te.Splits, s.ID, s.Name, func(s *entity.Split) (uuid.UUID, string) { return s.ID, s.Name })
+			splitIDs[s.ID] = id
+			s.ID, s.RaceID, s.EventID = id, target.ID, eventID
+			s.TimeReaderID = mapReader(s.TimeReaderID)
+		}
+		for _, s := range em.Splits {
+			if s.PreviousLapSplitID.Valid {
+				s.PreviousLapSplitID.UUID = splitIDs[s.PreviousLapSplitID.UUID]
+			}
+		}
+		for _, w := range em.Waves {
+			w.ID = matchID(te.Waves, w.ID, w.Name, func(w *entity.Wave) (uuid.UUID, string) { return w.ID, w.Name })
+			w.RaceID, w.EventID = target.ID, eventID
+			if w.TriggerReaderID.Valid {
+				w.TriggerReaderID.UUID = mapReader(w.TriggerReaderID.UUID)
+			}
+		}
+		for _, c := range em.Categories {
+			c.ID = matchID(te.Categories, c.ID, c.Name, func(c *entity.Category) (uuid.UUID, string) { return c.ID, c.Name })
+			c.RaceID, c.EventID = target.ID, eventID
+		}
+	}
+}
+
+// matchID returns ID of saved item with id, or with name if there is none, new ID if nothing matches
+func matchID[T any](saved []T, id uuid.UUID, name string, key func(T) (uuid.UUID, string)) uuid.UUID {
+	for _, s := range saved {
+		if sid, _ := key(s); sid == id {
+			return id
+		}
+	}
+	for _, s := range saved {
+		if sid, sname := key(s); sname == name {
+			return sid
+		}
+	}
+	return uuid.New()
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapRaceConfig(t *testing.T) {
+	finish := &entity.TimeReader{ID: uuid.New(), ReaderName: "finish"}
+	lap := &entity.Split{ID: uuid.New(), Name: "Lap 1"}
+	wave := &entity.Wave{ID: uuid.New(), Name: "Elite"}
+	target := &entity.RaceModel{
+		Race:        &entity.Race{ID: uuid.New()},
+		TimeReaders: []*entity.TimeReader{finish},
+		Events: []*entity.Event{{
+			ID: uuid.New(), Name: "10K",
+			Splits: []*entity.Split{lap},
+			Waves:  []*entity.Wave{wave},
+		}},
+	}
+
+	// exported from another server, so no IDs match
+	readerID, lapID, finishSplitID := uuid.New(), uuid.New(), uuid.New()
+	rd := &dto.RaceModelDTO{
+		RaceDTO:     &dto.RaceDTO{ID: uuid.New()},
+		TimeReaders: []*dto.TimeReaderDTO{{ID: readerID, ReaderName: "finish"}},
+		Events: []*dto.EventModelDTO{{
+			EventDTO: &dto.EventDTO{ID: uuid.New(), Name: "10K"},
+			Splits: []*dto.SplitDTO{
+				{ID: lapID, Name: "Lap 1", TimeReaderID: readerID},
+				{ID: finishSplitID, Name: "Finish", TimeReaderID: readerID, PreviousLapSplitID: uuid.NullUUID{UUID: lapID, Valid: true}},
+			},
+			Waves:      []*dto.WaveDTO{{ID: uuid.New(), Name: "Elite", TriggerReaderID: uuid.NullUUID{UUID: readerID, Valid: true}}},
+			Categories: []*dto.CategoryDTO{{ID: uuid.New(), Name: "Open"}},
+		}},
+	}
+
+	mapRaceConfig(rd, target)
+
+	assert.Equal(t, target.ID, rd.ID)
+	assert.Equal(t, finish.ID, rd.TimeReaders[0].ID)
+	e := rd.Events[0]
+	assert.Equal(t, target.Events[0].ID, e.ID)
+	assert.Equal(t, target.ID, e.RaceID)
+
+	assert.Equal(t, lap.ID, e.Splits[0].ID, "split is matched by name")
+	assert.Equal(t, finish.ID, e.Splits[0].TimeReaderID)
+	assert.NotEqual(t, finishSplitID, e.Splits[1].ID, "new split gets new ID")
+	assert.Equal(t, lap.ID, e.Splits[1].PreviousLapSplitID.UUID)
+	assert.Equal(t, e.ID, e.Splits[1].EventID)
+
+	assert.Equal(t, wave.ID, e.Waves[0].ID)
+	assert.Equal(t, finish.ID, e.Waves[0].TriggerReaderID.UUID)
+	assert.Equal(t, target.ID, e.Categories[0].RaceID)
+}
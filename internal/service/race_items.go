@@ -0,0 +1,545 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ecoarchie/timeit/internal/controller/httpv1/dto"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// Single items of race config are changed on top of saved config. Changed event is validated as a whole
+// the same way SaveRaceConfig validates it, so granular changes can't produce config that would be rejected there.
+// Saved config is read and checked within the saving transaction holding lock of race config, so concurrent
+// changes of the race are not lost.
+
+// CreateEvent adds event with its splits, waves and categories to the race
+func (rs RaceService) CreateEvent(ctx context.Context, raceID uuid.UUID, req *dto.EventModelDTO, v *validator.Validator) (*entity.Event, error) {
+	v.CheckField(req.EventDTO != nil, "event_id", validator.CodeRequired, "event must be provided")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	req.RaceID = raceID
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	for _, s := range req.Splits {
+		s.RaceID, s.EventID = raceID, req.ID
+		if s.ID == uuid.Nil {
+			s.ID = uuid.New()
+		}
+	}
+	for _, w := range req.Waves {
+		w.RaceID, w.EventID = raceID, req.ID
+		if w.ID == uuid.Nil {
+			w.ID = uuid.New()
+		}
+	}
+	for _, c := range req.Categories {
+		c.RaceID, c.EventID = raceID, req.ID
+		if c.ID == uuid.Nil {
+			c.ID = uuid.New()
+		}
+	}
+	return rs.saveEvent(ctx, raceID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		if rc == nil {
+			return nil, nil
+		}
+		err := rc.Status.CheckChange()
+		if err != nil {
+			return nil, err
+		}
+		rd := rc.DTO()
+		for _, e := range rd.Events {
+			v.CheckField(e.ID != req.ID, "event_id", validator.CodeNotUnique, "event already exists")
+			v.CheckField(e.Name != req.Name, "event_name", validator.CodeNotUnique, "must be unique")
+		}
+		e := validateEvent(rd, req, v)
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		return e, nil
+	})
+}
+
+// UpdateEvent changes name, distance and date of event. Age categories of the event are counted from the new date.
+func (rs RaceService) UpdateEvent(ctx context.Context, raceID, eventID uuid.UUID, req *dto.EventDTO, v *validator.Validator) (*entity.Event, error) {
+	req.ID, req.RaceID = eventID, raceID
+	return rs.saveEvent(ctx, raceID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		rd, em, err := eventConfig(rc, eventID)
+		if err != nil || em == nil {
+			return nil, err
+		}
+		for _, e := range rd.Events {
+			v.CheckField(e.ID == eventID || e.Name != req.Name, "event_name", validator.CodeNotUnique, "must be unique")
+		}
+		em.EventDTO = req
+		e := validateEvent(rd, em, v)
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		return e, nil
+	})
+}
+
+// DeleteEvent removes event with its athletes and their results. Returns false if event is not found.
+// Event with splits is deleted only with force once timing has begun.
+func (rs RaceService) DeleteEvent(ctx context.Context, raceID, eventID uuid.UUID, force bool) (bool, error) {
+	var found bool
+	err := rs.repo.DeleteEvent(ctx, raceID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		_, em, err := eventConfig(rc, eventID)
+		if err != nil || em == nil {
+			return nil, err
+		}
+		found = true
+		err = checkReadersChange(rc, withEvent(rc, eventID, nil), force)
+		if err != nil {
+			return nil, err
+		}
+		return rc.Events[slices.IndexFunc(rc.Events, func(e *entity.Event) bool { return e.ID == eventID })], nil
+	})
+	if err != nil || !found {
+		return found, err
+	}
+	rs.log.Info("event deleted", "race", raceID, "event", eventID)
+	return true, nil
+}
+
+func (rs RaceService) CreateSplit(ctx context.Context, raceID, eventID uuid.UUID, req *dto.SplitDTO, v *validator.Validator) (*entity.Split, error) {
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	return rs.saveSplit(ctx, raceID, eventID, req, true, false, v)
+}
+
+// UpdateSplit changes split of event. Moving split to another reader once timing has begun must be forced.
+func (rs RaceService) UpdateSplit(ctx context.Context, raceID, eventID, splitID uuid.UUID, req *dto.SplitDTO, force bool, v *validator.Validator) (*entity.Split, error) {
+	req.ID = splitID
+	return rs.saveSplit(ctx, raceID, eventID, req, false, force, v)
+}
+
+func (rs RaceService) saveSplit(ctx context.Context, raceID, eventID uuid.UUID, req *dto.SplitDTO, isNew, force bool, v *validator.Validator) (*entity.Split, error) {
+	req.RaceID, req.EventID = raceID, eventID
+	e, err := rs.saveEvent(ctx, raceID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		rd, em, err := eventConfig(rc, eventID)
+		if err != nil || em == nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(em.Splits, func(s *dto.SplitDTO) bool { return s.ID == req.ID })
+		switch {
+		case isNew && idx != -1:
+			v.AddFieldError("split_id", validator.CodeNotUnique, "split already exists")
+			return nil, validator.ErrValidation
+		case isNew:
+			em.Splits = append(em.Splits, req)
+		case idx == -1:
+			return nil, nil
+		default:
+			em.Splits[idx] = req
+		}
+		e := validateEventItem(rd, em, "splits", slices.Index(em.Splits, req), v)
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		err = checkReadersChange(rc, withEvent(rc, eventID, e), force)
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	})
+	if err != nil || e == nil {
+		return nil, err
+	}
+	return e.Splits[slices.IndexFunc(e.Splits, func(s *entity.Split) bool { return s.ID == req.ID })], nil
+}
+
+// DeleteSplit removes split of event together with athletes' results at it. Returns false if split is not found.
+// Once timing has begun the split is deleted only with force.
+func (rs RaceService) DeleteSplit(ctx context.Context, raceID, eventID, splitID uuid.UUID, force bool, v *validator.Validator) (bool, error) {
+	var found bool
+	err := rs.repo.DeleteSplit(ctx, raceID, splitID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		rd, em, err := eventConfig(rc, eventID)
+		if err != nil || em == nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(em.Splits, func(s *dto.SplitDTO) bool { return s.ID == splitID })
+		if idx == -1 {
+			return nil, nil
+		}
+		found = true
+		em.Splits = slices.Delete(em.Splits, idx, idx+1)
+		e := validateEvent(rd, em, v)
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		err = checkReadersChange(rc, withEvent(rc, eventID, e), force)
+		if err != nil {
+			return nil, err
+		}
+		return e, nil
+	})
+	if err != nil || !found {
+		return found, err
+	}
+	rs.log.Info("split deleted", "race", raceID, "event", eventID, "split", splitID)
+	rs.recalculate(ctx, raceID)
+	return true, nil
+}
+
+func (rs RaceService) CreateWave(ctx context.Context, raceID, eventID uuid.UUID, req *dto.WaveDTO, v *validator.Validator) (*entity.Wave, error) {
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	return rs.saveWave(ctx, raceID, eventID, req, true, v)
+}
+
+func (rs RaceService) UpdateWave(ctx context.Context, raceID, eventID, waveID uuid.UUID, req *dto.WaveDTO, v *validator.Validator) (*entity.Wave, error) {
+	req.ID = waveID
+	return rs.saveWave(ctx, raceID, eventID, req, false, v)
+}
+
+// saveWave saves wave config. Launch state of the wave is kept, waves are launched and reset
+// by LaunchWaves and ResetWave only, so that every change is audited.
+func (rs RaceService) saveWave(ctx context.Context, raceID, eventID uuid.UUID, req *dto.WaveDTO, isNew bool, v *validator.Validator) (*entity.Wave, error) {
+	req.RaceID, req.EventID = raceID, eventID
+	e, err := rs.saveEvent(ctx, raceID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		rd, em, err := eventConfig(rc, eventID)
+		if err != nil || em == nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(em.Waves, func(w *dto.WaveDTO) bool { return w.ID == req.ID })
+		switch {
+		case isNew && idx != -1:
+			v.AddFieldError("wave_id", validator.CodeNotUnique, "wave already exists")
+			return nil, validator.ErrValidation
+		case isNew:
+			req.IsLaunched = false
+			em.Waves = append(em.Waves, req)
+		case idx == -1:
+			return nil, nil
+		default:
+			req.IsLaunched = em.Waves[idx].IsLaunched
+			em.Waves[idx] = req
+		}
+		e := validateEventItem(rd, em, "waves", slices.Index(em.Waves, req), v)
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		return e, nil
+	})
+	if err != nil || e == nil {
+		return nil, err
+	}
+	return e.Waves[slices.IndexFunc(e.Waves, func(w *entity.Wave) bool { return w.ID == req.ID })], nil
+}
+
+// DeleteWave removes wave of event. Wave with athletes can't be deleted, they must be moved to another wave first.
+// Returns false if wave is not found.
+func (rs RaceService) DeleteWave(ctx context.Context, raceID, eventID, waveID uuid.UUID, v *validator.Validator) (bool, error) {
+	var found bool
+	err := rs.repo.DeleteWave(ctx, raceID, waveID, func(rc *entity.RaceModel, athletes int) (*entity.Event, error) {
+		rd, em, err := eventConfig(rc, eventID)
+		if err != nil || em == nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(em.Waves, func(w *dto.WaveDTO) bool { return w.ID == waveID })
+		if idx == -1 {
+			return nil, nil
+		}
+		found = true
+		em.Waves = slices.Delete(em.Waves, idx, idx+1)
+		e := validateEvent(rd, em, v)
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		v.CheckField(athletes == 0, "wave_id", validator.CodeInUse, fmt.Sprintf("wave has %d athletes, move them to another wave first", athletes))
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		return e, nil
+	})
+	if err != nil || !found {
+		return found, err
+	}
+	rs.log.Info("wave deleted", "race", raceID, "event", eventID, "wave", waveID)
+	return true, nil
+}
+
+func (rs RaceService) CreateCategory(ctx context.Context, raceID, eventID uuid.UUID, req *dto.CategoryDTO, v *validator.Validator) (*entity.Category, error) {
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	return rs.saveCategory(ctx, raceID, eventID, req, true, v)
+}
+
+func (rs RaceService) UpdateCategory(ctx context.Context, raceID, eventID, categoryID uuid.UUID, req *dto.CategoryDTO, v *validator.Validator) (*entity.Category, error) {
+	req.ID = categoryID
+	return rs.saveCategory(ctx, raceID, eventID, req, false, v)
+}
+
+func (rs RaceService) saveCategory(ctx context.Context, raceID, eventID uuid.UUID, req *dto.CategoryDTO, isNew bool, v *validator.Validator) (*entity.Category, error) {
+	req.RaceID, req.EventID = raceID, eventID
+	e, err := rs.saveEvent(ctx, raceID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		rd, em, err := eventConfig(rc, eventID)
+		if err != nil || em == nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(em.Categories, func(c *dto.CategoryDTO) bool { return c.ID == req.ID })
+		switch {
+		case isNew && idx != -1:
+			v.AddFieldError("category_id", validator.CodeNotUnique, "category already exists")
+			return nil, validator.ErrValidation
+		case isNew:
+			em.Categories = append(em.Categories, req)
+		case idx == -1:
+			return nil, nil
+		default:
+			em.Categories[idx] = req
+		}
+		e := validateEventItem(rd, em, "categories", slices.Index(em.Categories, req), v)
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		return e, nil
+	})
+	if err != nil || e == nil {
+		return nil, err
+	}
+	return e.Categories[slices.IndexFunc(e.Categories, func(c *entity.Category) bool { return c.ID == req.ID })], nil
+}
+
+// DeleteCategory removes category of event. Athletes of the category are matched against the remaining categories
+// and results are recalculated. Returns false if category is not found.
+func (rs RaceService) DeleteCategory(ctx context.Context, raceID, eventID, categoryID uuid.UUID, v *validator.Validator) (bool, error) {
+	var found bool
+	var e *entity.Event
+	err := rs.repo.DeleteCategory(ctx, raceID, categoryID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		rd, em, err := eventConfig(rc, eventID)
+		if err != nil || em == nil {
+			return nil, err
+		}
+		idx := slices.IndexFunc(em.Categories, func(c *dto.CategoryDTO) bool { return c.ID == categoryID })
+		if idx == -1 {
+			return nil, nil
+		}
+		found = true
+		em.Categories = slices.Delete(em.Categories, idx, idx+1)
+		e = validateEvent(rd, em, v)
+		if !v.Valid() {
+			return nil, validator.ErrValidation
+		}
+		return e, nil
+	})
+	if err != nil || !found {
+		return found, err
+	}
+	rs.log.Info("category deleted", "race", raceID, "event", eventID, "category", categoryID)
+	_, err = rs.reassignCategories(ctx, raceID, e)
+	if err != nil {
+		rs.log.Error("error reassigning categories", "event", e.ID, "error", err)
+		return true, err
+	}
+	rs.recalculate(ctx, raceID)
+	return true, nil
+}
+
+func (rs RaceService) CreateTimeReader(ctx context.Context, raceID uuid.UUID, req *dto.TimeReaderDTO, v *validator.Validator) (*entity.TimeReader, error) {
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+	return rs.saveTimeReader(ctx, raceID, req, true, false, v)
+}
+
+// UpdateTimeReader changes reader of race. Renaming reader used by splits once timing has begun must be forced.
+func (rs RaceService) UpdateTimeReader(ctx context.Context, raceID, readerID uuid.UUID, req *dto.TimeReaderDTO, force bool, v *validator.Validator) (*entity.TimeReader, error) {
+	req.ID = readerID
+	return rs.saveTimeReader(ctx, raceID, req, false, force, v)
+}
+
+// saveTimeReader saves reader of race. Reads are matched to readers by name, so reads of renamed reader are
+// taken into account only if the box sends the new name.
+func (rs RaceService) saveTimeReader(ctx context.Context, raceID uuid.UUID, req *dto.TimeReaderDTO, isNew, force bool, v *validator.Validator) (*entity.TimeReader, error) {
+	rc, err := rs.repo.GetRaceConfig(ctx, raceID)
+	if err != nil || rc == nil {
+		return nil, err
+	}
+	err = rc.Status.CheckChange()
+	if err != nil {
+		return nil, err
+	}
+	req.RaceID = raceID
+	idx := slices.IndexFunc(rc.TimeReaders, func(tr *entity.TimeReader) bool { return tr.ID == req.ID })
+	switch {
+	case isNew && idx != -1:
+		v.AddFieldError("time_reader_id", validator.CodeNotUnique, "time reader already exists")
+		return nil, validator.ErrValidation
+	case !isNew && idx == -1:
+		return nil, nil
+	}
+	req.Validate(v, raceID)
+	tr := entity.NewTimeReader(req, v)
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	names := []string{tr.ReaderName}
+	for _, r := range rc.TimeReaders {
+		if r.ID != tr.ID {
+			names = append(names, r.ReaderName)
+		}
+	}
+	v.CheckField(validator.Unique(names), "reader_name", validator.CodeNotUnique, "must be unique")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	if !isNew {
+		next := *rc
+		next.TimeReaders = slices.Clone(rc.TimeReaders)
+		next.TimeReaders[idx] = tr
+		err = checkReadersChange(rc, &next, force)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = rs.repo.SaveTimeReader(ctx, tr)
+	if err != nil {
+		return nil, fmt.Errorf("error saving time reader: %w", err)
+	}
+	return tr, nil
+}
+
+// DeleteTimeReader removes time reader which is not used by any split or wave trigger. Returns false
+// if reader is not found.
+func (rs RaceService) DeleteTimeReader(ctx context.Context, raceID, readerID uuid.UUID, v *validator.Validator) (bool, error) {
+	rc, err := rs.repo.GetRaceConfig(ctx, raceID)
+	if err != nil || rc == nil {
+		return false, err
+	}
+	err = rc.Status.CheckChange()
+	if err != nil {
+		return false, err
+	}
+	if !slices.ContainsFunc(rc.TimeReaders, func(tr *entity.TimeReader) bool { return tr.ID == readerID }) {
+		return false, nil
+	}
+	v.CheckField(len(rc.TimeReaders) > 1, "time_reader_id", validator.CodeRequired, "race must have at least one time reader")
+	for _, e := range rc.Events {
+		for _, s := range e.Splits {
+			v.CheckField(s.TimeReaderID != readerID, "time_reader_id", validator.CodeInUse, fmt.Sprintf("used by split %q of event %q", s.Name, e.Name))
+		}
+		for _, w := range e.Waves {
+			v.CheckField(w.TriggerReaderID.UUID != readerID, "time_reader_id", validator.CodeInUse, fmt.Sprintf("used by trigger of wave %q of event %q", w.Name, e.Name))
+		}
+	}
+	if !v.Valid() {
+		return true, validator.ErrValidation
+	}
+	err = rs.repo.DeleteTimeReader(ctx, readerID)
+	if err != nil {
+		return true, fmt.Errorf("error deleting time reader: %w", err)
+	}
+	rs.log.Info("time reader deleted", "race", raceID, "reader", readerID)
+	return true, nil
+}
+
+// eventConfig returns race config rc as DTO together with DTO of the event. Event is nil if race
+// or event is not found. Fails if config of the race can't be changed in its current status.
+func eventConfig(rc *entity.RaceModel, eventID uuid.UUID) (*dto.RaceModelDTO, *dto.EventModelDTO, error) {
+	if rc == nil {
+		return nil, nil, nil
+	}
+	err := rc.Status.CheckChange()
+	if err != nil {
+		return nil, nil, err
+	}
+	rd := rc.DTO()
+	idx := slices.IndexFunc(rd.Events, func(e *dto.EventModelDTO) bool { return e.ID == eventID })
+	if idx == -1 {
+		return rd, nil, nil
+	}
+	return rd, rd.Events[idx], nil
+}
+
+// withEvent returns copy of race config rc with event e in place of saved event, without the event if e is nil
+func withEvent(rc *entity.RaceModel, eventID uuid.UUID, e *entity.Event) *entity.RaceModel {
+	next := *rc
+	next.Events = make([]*entity.Event, 0, len(rc.Events))
+	for _, pe := range rc.Events {
+		switch {
+		case pe.ID != eventID:
+			next.Events = append(next.Events, pe)
+		case e != nil:
+			next.Events = append(next.Events, e)
+		}
+	}
+	return &next
+}
+
+// validateEvent validates changed event of race config the same way it is validated within whole race config
+func validateEvent(rd *dto.RaceModelDTO, em *dto.EventModelDTO, v *validator.Validator) *entity.Event {
+	em.Validate(v, rd.ID, rd.TimeReaders)
+	if !v.Valid() {
+		return nil
+	}
+	race := &entity.Race{Timezone: rd.Timezone}
+	return entity.NewEvent(em.EventDTO, em.Splits, rd.TimeReaders, em.Waves, em.Categories, race.Location(), v)
+}
+
+// validateEventItem validates event em changed at i-th item of list key. Errors of the item are reported
+// at paths relative to the item as it is the request body, errors of the rest of event at their paths in the event.
+func validateEventItem(rd *dto.RaceModelDTO, em *dto.EventModelDTO, key string, i int, v *validator.Validator) *entity.Event {
+	ev := validator.New()
+	e := validateEvent(rd, em, ev)
+	item := fmt.Sprintf("%s[%d]", key, i)
+	for _, fe := range ev.FieldErrors() {
+		path, ok := strings.CutPrefix(fe.Path, item+".")
+		if !ok {
+			path = fe.Path
+		}
+		v.AddFieldError(path, fe.Code, fe.Message)
+	}
+	return e
+}
+
+// saveEvent saves event returned by check, which gets race config read under lock of the config. Athletes
+// are matched against changed categories and results are recalculated. Event is nil if check returns nil.
+func (rs RaceService) saveEvent(ctx context.Context, raceID uuid.UUID, check func(rc *entity.RaceModel) (*entity.Event, error)) (*entity.Event, error) {
+	var prev *entity.RaceModel
+	var e *entity.Event
+	err := rs.repo.SaveEvent(ctx, raceID, func(rc *entity.RaceModel) (*entity.Event, error) {
+		var err error
+		prev = rc
+		e, err = check(rc)
+		return e, err
+	})
+	if err != nil || e == nil {
+		return nil, err
+	}
+	idx := slices.IndexFunc(prev.Events, func(pe *entity.Event) bool { return pe.ID == e.ID })
+	if idx != -1 && entity.CategoriesChanged(prev.Events[idx].Categories, e.Categories) {
+		res, err := rs.reassignCategories(ctx, raceID, e)
+		if err != nil {
+			rs.log.Error("error reassigning categories", "event", e.ID, "error", err)
+			return nil, err
+		}
+		rs.log.Info("categories reassigned", "event", e.ID, "checked", res.Checked, "moved", res.Moved)
+	}
+	rs.recalculate(ctx, raceID)
+	e.InLocation(prev.Location())
+	return e, nil
+}
+
+// recalculate recalculates results of race after its config changed
+func (rs RaceService) recalculate(ctx context.Context, raceID uuid.UUID) {
+	if rs.results == nil {
+		return
+	}
+	// config is saved already so failed calculation is left to the next recalculation
+	err := rs.results.CalculateSplitResults(ctx, raceID)
+	if err != nil {
+		rs.log.Error("error recalculating results after config change", "race", raceID, "error", err)
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// GetRace returns race without its config, nil if not found
+func (rs RaceService) GetRace(ctx context.Context, raceID uuid.UUID) (*entity.Race, error) {
+	return rs.repo.GetRaceInfo(ctx, raceID)
+}
+
+// ChangeRaceStatus moves race through its lifecycle and records the transition. Returns nil if race is not found.
+func (rs RaceService) ChangeRaceStatus(ctx context.Context, raceID uuid.UUID, req entity.RaceStatusRequest, v *validator.Validator) (*entity.RaceStatusChange, error) {
+	race, err := rs.repo.GetRaceInfo(ctx, raceID)
+	if err != nil || race == nil {
+		return nil, err
+	}
+	c := entity.NewRaceStatusChange(race, req, v)
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	ok, err := rs.repo.SetRaceStatus(ctx, c)
+	if err != nil {
+		rs.log.Error("error changing race status", "race", raceID, "error", err)
+		return nil, err
+	}
+	if !ok {
+		v.AddFieldError("status", validator.CodeConflict, fmt.Sprintf("race is not %s anymore, status has been changed meanwhile", c.OldStatus))
+		return nil, validator.ErrValidation
+	}
+	rs.log.Info("race status changed", "race", raceID, "old_status", c.OldStatus, "new_status", c.NewStatus, "forced", c.Forced, "reason", c.Reason)
+	return c, nil
+}
+
+func (rs RaceService) GetRaceStatusHistory(ctx context.Context, raceID uuid.UUID) ([]*entity.RaceStatusChange, error) {
+	changes, err := rs.repo.GetRaceStatusHistory(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := rs.raceLocation(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range changes {
+		c.CreatedAt = entity.InLocation(c.CreatedAt, loc)
+	}
+	return changes, nil
+}
+
+// checkChange returns error if config, athletes or results of the race can't be changed in its current status.
+// Missing race is left to the caller.
+func (rs RaceService) checkChange(ctx context.Context, raceID uuid.UUID) error {
+	race, err := rs.repo.GetRaceInfo(ctx, raceID)
+	if err != nil || race == nil {
+		return err
+	}
+	return race.Status.CheckChange()
+}
+
+// checkReadersChange returns error if change of saved config prev to next moves reads to other splits
+// while timing is running and the change is not forced
+func checkReadersChange(prev, next *entity.RaceModel, force bool) error {
+	if !entity.SplitReadersChanged(prev, next) {
+		return nil
+	}
+	return prev.Status.CheckReadersChange(force)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/database"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+const benchEvents = 4
+
+type benchEvent struct {
+	recs   []database.GetEventAthleteRecordsCRow
+	splits []*entity.Split
+}
+
+// benchAthleteRepo keeps records in memory and collects what calculation saves without saving it anywhere
+type benchAthleteRepo struct {
+	AthleteRepo
+	eventIDs []uuid.UUID
+	events   map[uuid.UUID]*benchEvent
+	saved    []*entity.AthleteSplit
+	statuses []*entity.AthleteStatus
+}
+
+func (r *benchAthleteRepo) GetEventIDsWithWavesStarted(ctx context.Context, raceID uuid.UUID) ([]uuid.UUID, error) {
+	return r.eventIDs, nil
+}
+
+func (r *benchAthleteRepo) GetRecordsAndSplitsForEventAthlete(ctx context.Context, raceID, eventID uuid.UUID, chips []int) ([]database.GetEventAthleteRecordsCRow, []*entity.Split, error) {
+	e := r.events[eventID]
+	return e.recs, e.splits, nil
+}
+
+func (r *benchAthleteRepo) GetManualAthleteSplits(ctx context.Context, raceID, eventID uuid.UUID) (map[uuid.UUID][]*entity.AthleteSplit, error) {
+	return map[uuid.UUID][]*entity.AthleteSplit{}, nil
+}
+
+func (r *benchAthleteRepo) SaveBulkAthleteSplits(ctx context.Context, raceID uuid.UUID, as []*entity.AthleteSplit, ss []*entity.AthleteStatus) error {
+	r.saved, r.statuses = as, ss
+	return nil
+}
+
+func (r *benchAthleteRepo) GetReadsSnapshot(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *benchAthleteRepo) SetResultsWatermark(ctx context.Context, raceID uuid.UUID, snapshot int64) error {
+	return nil
+}
+
+type benchRaceRepo struct {
+	RaceRepo
+}
+
+func (r *benchRaceRepo) LaunchWavesByTrigger(ctx context.Context, raceID uuid.UUID) ([]*entity.Wave, error) {
+	return nil, nil
+}
+
+func (r *benchRaceRepo) GetRaceInfo(ctx context.Context, raceID uuid.UUID) (*entity.Race, error) {
+	return &entity.Race{ID: raceID, Timezone: "UTC", Status: entity.RaceStatusLive}, nil
+}
+
+// newBenchAthleteRepo creates athletes spread over events with start, 5km, 10km and finish splits.
+// Every athlete has reads at all splits, every other athlete has not finished yet.
+func newBenchAthleteRepo(athletes int) *benchAthleteRepo {
+	repo := &benchAthleteRepo{events: make(map[uuid.UUID]*benchEvent)}
+	waveStart := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	for e := 0; e < benchEvents; e++ {
+		eventID := uuid.New()
+		repo.eventIDs = append(repo.eventIDs, eventID)
+		ev := &benchEvent{}
+		for i, st := range []entity.SplitType{entity.SplitTypeStart, entity.SplitTypeStandard, entity.SplitTypeStandard, entity.SplitTypeFinish} {
+			ev.splits = append(ev.splits, &entity.Split{
+				ID:                uuid.New(),
+				EventID:           eventID,
+				Name:              fmt.Sprintf("split %d", i),
+				Type:              st,
+				DistanceFromStart: i * 5000,
+				TimeReaderID:      uuid.New(),
+			})
+		}
+		for a := e; a < athletes; a += benchEvents {
+			reads := len(ev.splits)
+			if a%2 == 1 {
+				reads--
+			}
+			var rrTod []entity.RecordTOD
+			for s := 0; s < reads; s++ {
+				rrTod = append(rrTod, entity.RecordTOD{
+					ReaderID: ev.splits[s].TimeReaderID,
+					TOD:      waveStart.Add(time.Duration(s)*25*time.Minute + time.Duration(a)*time.Millisecond),
+				})
+			}
+			ev.recs = append(ev.recs, database.GetEventAthleteRecordsCRow{
+				AthleteID:  uuid.New(),
+				Bib:        int32(a + 1),
+				Chip:       int32(a + 1),
+				Gender:     database.CategoryGenderMale,
+				StatusFull: string(entity.NYS),
+				WaveStart:  pgtype.Timestamptz{Time: waveStart, Valid: true},
+				RrTod:      rrTod,
+			})
+		}
+		repo.events[eventID] = ev
+	}
+	return repo
+}
+
+func TestCalculateSplitResultsSavesStatusesWithSplits(t *testing.T) {
+	repo := newBenchAthleteRepo(8)
+	rs := NewResultsService(repo, &benchRaceRepo{}, nil)
+
+	err := rs.CalculateSplitResults(context.Background(), uuid.New())
+	assert.NoError(t, err)
+	// every athlete has 4 splits, finished and running athletes change status from NYS
+	assert.Len(t, repo.saved, 8*4)
+	got := make(map[entity.Status]int)
+	for _, st := range repo.statuses {
+		got[st.Status]++
+	}
+	assert.Equal(t, map[entity.Status]int{entity.FIN: 4, entity.RUN: 4}, got)
+}
+
+// BenchmarkCalculateSplitResultsCPU measures calculation only, records are read from and results are saved
+// to memory, so saving splits and statuses to the database is not covered.
+func BenchmarkCalculateSplitResultsCPU(b *testing.B) {
+	ctx := context.Background()
+	raceID := uuid.New()
+	for _, athletes := range []int{10000, 50000} {
+		repo := newBenchAthleteRepo(athletes)
+		for _, workers := range []int{1, 0} {
+			rs := NewResultsService(repo, &benchRaceRepo{}, nil)
+			rs.Workers = workers
+			b.Run(fmt.Sprintf("athletes=%d/workers=%d", athletes, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					if err := rs.CalculateSplitResults(ctx, raceID); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+)
+
+// GetCategoryResults returns results of athletes of age or custom category at the split ranked within the category.
+// Finish split of category's event is used if splitID is not set.
+func (rs *ResultsService) GetCategoryResults(ctx context.Context, raceID, categoryID, splitID uuid.UUID) ([]*entity.CategoryResult, error) {
+	rm, err := rs.RaceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if rm == nil {
+		return nil, nil
+	}
+	eventIdx := slices.IndexFunc(rm.Events, func(e *entity.Event) bool {
+		return slices.ContainsFunc(e.Categories, func(c *entity.Category) bool {
+			return c.ID == categoryID
+		})
+	})
+	if eventIdx == -1 {
+		return nil, nil
+	}
+	splits := rm.Events[eventIdx].Splits
+	splitIdx := slices.IndexFunc(splits, func(s *entity.Split) bool {
+		if splitID == uuid.Nil {
+			return s.Type == entity.SplitTypeFinish
+		}
+		return s.ID == splitID
+	})
+	if splitIdx == -1 {
+		return nil, nil
+	}
+	res, err := rs.AthleteRepo.GetCategoryResults(ctx, raceID, categoryID, splits[splitIdx].ID)
+	if err != nil {
+		return nil, err
+	}
+	loc := rm.Location()
+	for _, r := range res {
+		r.TOD = entity.InLocation(r.TOD, loc)
+	}
+	return res, nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/database"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtureAthleteRepo returns records and splits of a single event kept in memory and keeps saved statuses
+type fixtureAthleteRepo struct {
+	AthleteRepo
+	recs     []database.GetEventAthleteRecordsCRow
+	splits   []*entity.Split
+	statuses []*entity.AthleteStatus
+}
+
+func (r *fixtureAthleteRepo) UpdateStatuses(ctx context.Context, raceID uuid.UUID, ss []*entity.AthleteStatus) error {
+	r.statuses = ss
+	return nil
+}
+
+func (r *fixtureAthleteRepo) GetRecordsAndSplitsForEventAthlete(ctx context.Context, raceID, eventID uuid.UUID, chips []int) ([]database.GetEventAthleteRecordsCRow, []*entity.Split, error) {
+	return r.recs, r.splits, nil
+}
+
+func (r *fixtureAthleteRepo) GetManualAthleteSplits(ctx context.Context, raceID, eventID uuid.UUID) (map[uuid.UUID][]*entity.AthleteSplit, error) {
+	return map[uuid.UUID][]*entity.AthleteSplit{}, nil
+}
+
+func TestCalculateSplitResultsMissedCutoff(t *testing.T) {
+	raceID, eventID := uuid.New(), uuid.New()
+	waveStart := time.Date(2025, time.May, 20, 9, 0, 0, 0, time.UTC)
+	start := &entity.Split{ID: uuid.New(), EventID: eventID, Name: "start", Type: entity.SplitTypeStart, TimeReaderID: uuid.New()}
+	km10 := &entity.Split{ID: uuid.New(), EventID: eventID, Name: "10km", Type: entity.SplitTypeStandard, DistanceFromStart: 10000, TimeReaderID: uuid.New(), CutoffTime: time.Hour}
+	finish := &entity.Split{ID: uuid.New(), EventID: eventID, Name: "finish", Type: entity.SplitTypeFinish, DistanceFromStart: 21100, TimeReaderID: uuid.New()}
+	athlete := func(status entity.Status, reads ...entity.RecordTOD) database.GetEventAthleteRecordsCRow {
+		return database.GetEventAthleteRecordsCRow{
+			AthleteID:  uuid.New(),
+			StatusFull: string(status),
+			WaveStart:  pgtype.Timestamptz{Time: waveStart, Valid: true},
+			RrTod:      reads,
+		}
+	}
+	read := func(s *entity.Split, d time.Duration) entity.RecordTOD {
+		return entity.RecordTOD{ReaderID: s.TimeReaderID, TOD: waveStart.Add(d)}
+	}
+	inTime := athlete(entity.NYS, read(start, time.Minute), read(km10, 50*time.Minute))
+	late := athlete(entity.RUN, read(start, time.Minute))
+	notStarted := athlete(entity.NYS)
+	repo := &fixtureAthleteRepo{recs: []database.GetEventAthleteRecordsCRow{inTime, late, notStarted}, splits: []*entity.Split{start, km10, finish}}
+	rs := NewResultsService(repo, &benchRaceRepo{}, nil)
+	rs.Now = func() time.Time { return waveStart.Add(90 * time.Minute) }
+
+	_, err := rs.CalculateSplitResultsForEvent(context.Background(), raceID, eventID)
+	assert.NoError(t, err)
+	got := make(map[uuid.UUID]*entity.AthleteStatus, len(repo.statuses))
+	for _, st := range repo.statuses {
+		got[st.AthleteID] = st
+	}
+	assert.Equal(t, entity.RUN, got[inTime.AthleteID].Status)
+	for _, a := range []database.GetEventAthleteRecordsCRow{late, notStarted} {
+		if assert.Contains(t, got, a.AthleteID) {
+			assert.Equal(t, entity.DNF, got[a.AthleteID].Status)
+			assert.Equal(t, entity.CutoffReason(km10), got[a.AthleteID].Reason)
+		}
+	}
+}
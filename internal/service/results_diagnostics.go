@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+
+	"github.com/ecoarchie/timeit/internal/database"
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+)
+
+type traceKey struct {
+	readerID uuid.UUID
+	tod      int64
+}
+
+// GetAthleteDiagnostics reruns results calculation for a single athlete and explains
+// for every raw read which splits it was checked against and why it was accepted or rejected.
+func (rs *ResultsService) GetAthleteDiagnostics(ctx context.Context, raceID, athleteID uuid.UUID) (*entity.AthleteDiagnostics, error) {
+	a, err := rs.AthleteRepo.GetAthleteByID(ctx, athleteID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if a == nil || a.RaceID != raceID {
+		return nil, nil
+	}
+	loc, err := rs.raceLocation(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+
+	reads, err := rs.AthleteRepo.GetAthleteReaderRecords(ctx, raceID, a.Chip)
+	if err != nil {
+		return nil, err
+	}
+	recs, splits, err := rs.AthleteRepo.GetRecordsAndSplitsForEventAthlete(ctx, raceID, a.EventID, []int{a.Chip})
+	if err != nil {
+		return nil, err
+	}
+	manual, err := rs.AthleteRepo.GetManualAthleteSplits(ctx, raceID, a.EventID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &entity.AthleteDiagnostics{
+		AthleteID: a.ID,
+		EventID:   a.EventID,
+		Bib:       a.Bib,
+		Chip:      a.Chip,
+		Status:    entity.NYS,
+		Reads:     reads,
+		Splits:    []*entity.SplitAssignment{},
+	}
+
+	idx := slices.IndexFunc(recs, func(r database.GetEventAthleteRecordsCRow) bool {
+		return r.AthleteID == athleteID
+	})
+	if idx == -1 {
+		for _, rd := range reads {
+			rd.Note = "athlete's wave is not launched"
+		}
+		res.InLocation(loc)
+		return res, nil
+	}
+	r := recs[idx]
+	res.WaveStart = r.WaveStart.Time
+
+	var startSplit *entity.Split
+	for _, s := range splits {
+		if s.Type == entity.SplitTypeStart {
+			startSplit = s
+			break
+		}
+	}
+	decisions := make(map[traceKey][]*entity.ReadDecision)
+	trace := func(rec entity.RecordTOD, s *entity.Split, rule entity.RecordRule) {
+		key := traceKey{rec.ReaderID, rec.TOD.UnixNano()}
+		decisions[key] = append(decisions[key], &entity.ReadDecision{
+			SplitID:   s.ID,
+			SplitName: s.Name,
+			Rule:      rule,
+		})
+	}
+	athleteSplits, status, err := traceSplitResultForSingleAthlete(r, splits, startSplit, trace)
+	if err != nil {
+		return nil, err
+	}
+	calculated := slices.Clone(athleteSplits)
+	if mans, ok := manual[athleteID]; ok {
+		replaceWithManual(athleteSplits, mans)
+	}
+	res.Status = status
+
+	for i, as := range athleteSplits {
+		if !as.IsVisited() {
+			continue
+		}
+		res.Splits = append(res.Splits, &entity.SplitAssignment{
+			SplitID:   splits[i].ID,
+			SplitName: splits[i].Name,
+			SplitType: splits[i].Type,
+			TOD:       as.TOD,
+			GunTime:   as.GunTime,
+			NetTime:   as.NetTime,
+			IsManual:  as != calculated[i],
+		})
+	}
+
+	for _, rd := range reads {
+		switch {
+		case !rd.CanUse:
+			rd.Note = "read is disabled"
+			continue
+		case !rd.TimeReaderID.Valid:
+			rd.Note = "no time reader with this name"
+			continue
+		}
+		checked := decisions[traceKey{rd.TimeReaderID.UUID, rd.TOD.UnixNano()}]
+		for j, s := range splits {
+			if s.TimeReaderID != rd.TimeReaderID.UUID {
+				continue
+			}
+			d := &entity.ReadDecision{SplitID: s.ID, SplitName: s.Name, Rule: entity.RecordNotEvaluated}
+			if i := slices.IndexFunc(checked, func(c *entity.ReadDecision) bool { return c.SplitID == s.ID }); i != -1 {
+				d = checked[i]
+			}
+			d.Used = d.Rule == entity.RecordAccepted && athleteSplits[j] == calculated[j] && athleteSplits[j].TOD.Equal(rd.TOD)
+			rd.Used = rd.Used || d.Used
+			rd.Candidates = append(rd.Candidates, &entity.ReadDecision{
+				SplitID:   d.SplitID,
+				SplitName: d.SplitName,
+				Rule:      d.Rule,
+				Used:      d.Used,
+			})
+		}
+		if len(rd.Candidates) == 0 {
+			rd.Note = "no split uses this reader"
+		}
+	}
+	res.InLocation(loc)
+	return res, nil
+}
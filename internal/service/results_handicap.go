@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+)
+
+// GetHandicapResults returns finish results of event with both handicapped and scratch times ranked on the given basis.
+// Handicap of athlete is athlete's own offset or, if not set, the offset of athlete's category.
+func (rs *ResultsService) GetHandicapResults(ctx context.Context, raceID, eventID uuid.UUID, basis entity.HandicapBasis) ([]*entity.HandicapResult, error) {
+	rm, err := rs.RaceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if rm == nil || !slices.ContainsFunc(rm.Events, func(e *entity.Event) bool { return e.ID == eventID }) {
+		return nil, nil
+	}
+	finishers, err := rs.AthleteRepo.GetFinishResultsForEvent(ctx, raceID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*entity.HandicapResult, 0, len(finishers))
+	for _, f := range finishers {
+		res = append(res, entity.NewHandicapResult(f))
+	}
+	entity.RankHandicap(res, basis)
+	return res, nil
+}
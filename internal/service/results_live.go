@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/logger"
+)
+
+// LiveResults periodically recalculates results of live races for reads added since the last calculation.
+type LiveResults struct {
+	results  *ResultsService
+	log      *logger.Logger
+	interval time.Duration
+}
+
+func NewLiveResults(logger *logger.Logger, results *ResultsService, interval time.Duration) *LiveResults {
+	return &LiveResults{
+		results:  results,
+		log:      logger,
+		interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (lr *LiveResults) Run(ctx context.Context) {
+	ticker := time.NewTicker(lr.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			lr.tick(ctx)
+		}
+	}
+}
+
+func (lr *LiveResults) tick(ctx context.Context) {
+	// reads are timed only for live races, results of others are calculated on demand
+	races, err := lr.results.RaceRepo.GetRacesWithStatus(ctx, entity.RaceStatusLive)
+	if err != nil {
+		lr.log.Error("live results: get races", "err", err.Error())
+		return
+	}
+	for _, r := range races {
+		start := time.Now()
+		n, err := lr.results.RecalculateNewRecords(ctx, r.ID)
+		if err != nil {
+			lr.log.Error("live results: recalculate new records", "race_id", r.ID.String(), "err", err.Error())
+			continue
+		}
+		if n > 0 {
+			lr.log.Info("live results: recalculated", "race_id", r.ID.String(), "chips", n, "took", time.Since(start).String())
+		}
+	}
+}
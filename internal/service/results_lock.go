@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// raceCalcs serializes results calculations per race. Full calculation requested while another one
+// is running is queued after it, all requests arriving meanwhile join the same queued calculation.
+type raceCalcs struct {
+	mu    sync.Mutex
+	races map[uuid.UUID]*raceCalcState
+}
+
+type raceCalcState struct {
+	// held while any calculation of the race is in progress
+	lock    sync.Mutex
+	running *calcRun
+	queued  *calcRun
+}
+
+type calcRun struct {
+	fn      func(ctx context.Context) error
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+	done    chan struct{}
+	err     error
+}
+
+func newRaceCalcs() *raceCalcs {
+	return &raceCalcs{
+		races: make(map[uuid.UUID]*raceCalcState),
+	}
+}
+
+// state must be called with mu held
+func (rc *raceCalcs) state(raceID uuid.UUID) *raceCalcState {
+	st, ok := rc.races[raceID]
+	if !ok {
+		st = &raceCalcState{}
+		rc.races[raceID] = st
+	}
+	return st
+}
+
+// do runs fn or joins calculation of the race queued behind the running one and waits for it.
+// Calculation is cancelled when contexts of all waiting callers are done.
+func (rc *raceCalcs) do(ctx context.Context, raceID uuid.UUID, fn func(ctx context.Context) error) error {
+	rc.mu.Lock()
+	st := rc.state(raceID)
+	var run *calcRun
+	switch {
+	case st.running == nil:
+		run = newCalcRun(ctx, fn)
+		st.running = run
+		go rc.execute(st, run)
+	case st.queued == nil:
+		run = newCalcRun(ctx, fn)
+		st.queued = run
+	default:
+		run = st.queued
+	}
+	run.waiters++
+	rc.mu.Unlock()
+
+	select {
+	case <-run.done:
+		return run.err
+	case <-ctx.Done():
+		rc.mu.Lock()
+		run.waiters--
+		if run.waiters == 0 {
+			run.cancel()
+		}
+		rc.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func newCalcRun(ctx context.Context, fn func(ctx context.Context) error) *calcRun {
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	return &calcRun{
+		fn:     fn,
+		ctx:    runCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+func (rc *raceCalcs) execute(st *raceCalcState, run *calcRun) {
+	for run != nil {
+		st.lock.Lock()
+		if run.ctx.Err() != nil {
+			run.err = run.ctx.Err()
+		} else {
+			run.err = run.fn(run.ctx)
+		}
+		st.lock.Unlock()
+		run.cancel()
+		close(run.done)
+
+		rc.mu.Lock()
+		run = st.queued
+		st.queued = nil
+		st.running = run
+		rc.mu.Unlock()
+	}
+}
+
+// tryLock acquires race calculation lock if no calculation of the race is in progress.
+func (rc *raceCalcs) tryLock(raceID uuid.UUID) (unlock func(), ok bool) {
+	rc.mu.Lock()
+	st := rc.state(raceID)
+	rc.mu.Unlock()
+	if !st.lock.TryLock() {
+		return nil, false
+	}
+	return st.lock.Unlock, true
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRaceCalcsQueueAndJoin(t *testing.T) {
+	rc := newRaceCalcs()
+	raceID := uuid.New()
+	release := make(chan struct{})
+	var calls, concurrent, maxConcurrent atomic.Int32
+	fn := func(ctx context.Context) error {
+		n := concurrent.Add(1)
+		if n > maxConcurrent.Load() {
+			maxConcurrent.Store(n)
+		}
+		calls.Add(1)
+		<-release
+		concurrent.Add(-1)
+		return nil
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, rc.do(context.Background(), raceID, fn))
+	}()
+	// wait for the first calculation to start
+	assert.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second, time.Millisecond)
+
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, rc.do(context.Background(), raceID, fn))
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		return rc.races[raceID].queued != nil && rc.races[raceID].queued.waiters == 3
+	}, time.Second, time.Millisecond)
+
+	_, ok := rc.tryLock(raceID)
+	assert.False(t, ok)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, int32(1), maxConcurrent.Load())
+
+	unlock, ok := rc.tryLock(raceID)
+	assert.True(t, ok)
+	unlock()
+}
+
+func TestRaceCalcsCancel(t *testing.T) {
+	rc := newRaceCalcs()
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		err := rc.do(ctx, uuid.New(), func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(stopped)
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	}()
+	<-started
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("calculation was not cancelled")
+	}
+}
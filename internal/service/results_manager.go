@@ -4,12 +4,15 @@ import (
 	"cmp"
 	"context"
 	"fmt"
+	"runtime"
 	"slices"
 	"time"
 
 	"github.com/ecoarchie/timeit/internal/database"
 	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/logger"
 	"github.com/google/uuid"
+	"golang.org/x/sync/errgroup"
 )
 
 type ResultsManager interface {
@@ -17,15 +20,35 @@ type ResultsManager interface {
 	CalculateRanks(ctx context.Context, eventResults []*entity.AthleteSplit)
 	CalculateSplitResults(ctx context.Context, raceID uuid.UUID) error
 	GetSplitResults(ctx context.Context, raceID uuid.UUID) (map[EventID][]entity.AthleteSplitResults, error)
+	GetAgeGradedResults(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.AgeGradedResult, error)
+	GetPredictions(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.AthletePrediction, error)
+	GetExpectedAtSplit(ctx context.Context, raceID, splitID uuid.UUID, within time.Duration) ([]*entity.ExpectedArrival, error)
+	GetOverdueReport(ctx context.Context, raceID uuid.UUID, tolerance float64) (*entity.OverdueReport, error)
+	GetAthleteDiagnostics(ctx context.Context, raceID, athleteID uuid.UUID) (*entity.AthleteDiagnostics, error)
+	GetCategoryResults(ctx context.Context, raceID, categoryID, splitID uuid.UUID) ([]*entity.CategoryResult, error)
+	GetHandicapResults(ctx context.Context, raceID, eventID uuid.UUID, basis entity.HandicapBasis) ([]*entity.HandicapResult, error)
+	RecalculateNewRecords(ctx context.Context, raceID uuid.UUID) (int, error)
 }
 
 type ResultsService struct {
 	AthleteRepo AthleteRepo
+	RaceRepo    RaceRepo
+	AgeGrades   *entity.AgeGradeTable
+	// number of events calculated concurrently, GOMAXPROCS if not positive
+	Workers int
+	// Now returns current time athletes who missed cutoffs are found at, time.Now if nil
+	Now func() time.Time
+	// logs automatic wave launches and timings of calculation, may be nil
+	Log   *logger.Logger
+	calcs *raceCalcs
 }
 
-func NewResultsService(repo AthleteRepo) *ResultsService {
+func NewResultsService(athleteRepo AthleteRepo, raceRepo RaceRepo, ageGrades *entity.AgeGradeTable) *ResultsService {
 	return &ResultsService{
-		AthleteRepo: repo,
+		AthleteRepo: athleteRepo,
+		RaceRepo:    raceRepo,
+		AgeGrades:   ageGrades,
+		calcs:       newRaceCalcs(),
 	}
 }
 
@@ -33,7 +56,29 @@ func (rs ResultsService) GetSplitResults(ctx context.Context, raceID uuid.UUID)
 	panic("Not implemented")
 }
 
+// CalculateSplitResults calculates results of all athletes of the race. Calculations of the same race never run
+// concurrently, if one is already running the call waits for the next calculation queued after it.
+// Official and archived results are kept as they are.
 func (rs *ResultsService) CalculateSplitResults(ctx context.Context, raceID uuid.UUID) error {
+	return rs.calcs.do(ctx, raceID, func(ctx context.Context) error {
+		return rs.calculateSplitResults(ctx, raceID)
+	})
+}
+
+func (rs *ResultsService) calculateSplitResults(ctx context.Context, raceID uuid.UUID) error {
+	frozen, err := rs.resultsFrozen(ctx, raceID)
+	if err != nil || frozen {
+		return err
+	}
+	// reads added during calculation will be picked up by the next incremental recalculation
+	snapshot, err := rs.AthleteRepo.GetReadsSnapshot(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = rs.launchTriggeredWaves(ctx, raceID)
+	if err != nil {
+		return err
+	}
 	IDs, err := rs.AthleteRepo.GetEventIDsWithWavesStarted(ctx, raceID)
 	if err != nil {
 		return err
@@ -42,37 +87,184 @@ func (rs *ResultsService) CalculateSplitResults(ctx context.Context, raceID uuid
 		return nil
 	}
 
-	var allRecords []*entity.AthleteSplit
-	for _, eventID := range IDs {
-		eventResults, err := rs.CalculateSplitResultsForEvent(ctx, raceID, eventID)
+	allRecords, statuses, err := rs.calculateEvents(ctx, raceID, IDs, nil)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	err = rs.AthleteRepo.SaveBulkAthleteSplits(ctx, raceID, allRecords, statuses)
+	if err != nil {
+		return fmt.Errorf("save athlete splits of race: %w", err)
+	}
+	rs.debug("athlete splits of race saved", "race_id", raceID.String(), "took", time.Since(start))
+	return rs.AthleteRepo.SetResultsWatermark(ctx, raceID, snapshot)
+}
+
+// RecalculateNewRecords recalculates only athletes whose chips have reads added since the last calculation,
+// upserts their splits and refreshes ranks at affected splits. If race results have never been calculated
+// the watermark is only seeded, earlier reads are taken into account by the next full calculation. Falls back
+// to full calculation if a wave has just been launched by trigger chip read, since reads of its athletes
+// may be behind the watermark. Skipped if another calculation of the race is in progress or results are official.
+// Returns the number of recalculated chips.
+func (rs *ResultsService) RecalculateNewRecords(ctx context.Context, raceID uuid.UUID) (int, error) {
+	unlock, ok := rs.calcs.tryLock(raceID)
+	if !ok {
+		// new reads are handled by the running calculation or the next call
+		return 0, nil
+	}
+	frozen, err := rs.resultsFrozen(ctx, raceID)
+	if err != nil || frozen {
+		unlock()
+		return 0, err
+	}
+	chips, snapshot, watermarkValid, err := rs.AthleteRepo.GetChipsWithNewRecords(ctx, raceID)
+	if err != nil {
+		unlock()
+		return 0, err
+	}
+	launched, err := rs.launchTriggeredWaves(ctx, raceID)
+	if err != nil {
+		unlock()
+		return 0, err
+	}
+	if launched {
+		unlock()
+		return 0, rs.CalculateSplitResults(ctx, raceID)
+	}
+	defer unlock()
+	if !watermarkValid {
+		return 0, rs.AthleteRepo.SetResultsWatermark(ctx, raceID, snapshot)
+	}
+	if len(chips) == 0 {
+		return 0, nil
+	}
+	IDs, err := rs.AthleteRepo.GetEventIDsWithWavesStarted(ctx, raceID)
+	if err != nil {
+		return 0, err
+	}
+
+	allRecords, statuses, err := rs.calculateEvents(ctx, raceID, IDs, chips)
+	if err != nil {
+		return 0, err
+	}
+	if len(allRecords) > 0 || len(statuses) > 0 {
+		err = rs.AthleteRepo.UpsertAthleteSplits(ctx, raceID, allRecords, statuses)
 		if err != nil {
-			return err
+			return 0, err
 		}
-		allRecords = append(allRecords, eventResults...)
-		for _, e := range eventResults {
-			if e == nil {
-				fmt.Println("WE HAVE NIL HERE")
+	}
+	return len(chips), rs.AthleteRepo.SetResultsWatermark(ctx, raceID, snapshot)
+}
+
+func (rs ResultsService) CalculateSplitResultsForEvent(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.AthleteSplit, error) {
+	now, err := rs.raceNow(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	res, statuses, err := rs.calculateSplitResultsForEvent(ctx, raceID, eventID, nil, now)
+	if err != nil {
+		return nil, err
+	}
+	err = rs.AthleteRepo.UpdateStatuses(ctx, raceID, statuses)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// calculateEvents calculates splits of the events concurrently using at most Workers goroutines.
+// Splits and changed statuses are returned in the order of events.
+func (rs ResultsService) calculateEvents(ctx context.Context, raceID uuid.UUID, eventIDs []uuid.UUID, chips []int) ([]*entity.AthleteSplit, []*entity.AthleteStatus, error) {
+	now, err := rs.raceNow(ctx, raceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	workers := rs.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	eventSplits := make([][]*entity.AthleteSplit, len(eventIDs))
+	eventStatuses := make([][]*entity.AthleteStatus, len(eventIDs))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(workers)
+	for i, eventID := range eventIDs {
+		g.Go(func() error {
+			res, statuses, err := rs.calculateSplitResultsForEvent(gctx, raceID, eventID, chips, now)
+			if err != nil {
+				return err
 			}
+			eventSplits[i] = res
+			eventStatuses[i] = statuses
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	return slices.Concat(eventSplits...), slices.Concat(eventStatuses...), nil
+}
+
+// resultsFrozen reports whether results of the race are official or archived and must not be recalculated
+func (rs ResultsService) resultsFrozen(ctx context.Context, raceID uuid.UUID) (bool, error) {
+	race, err := rs.RaceRepo.GetRaceInfo(ctx, raceID)
+	if err != nil {
+		return false, err
+	}
+	return race != nil && race.Status.ReadOnly(), nil
+}
+
+// launchTriggeredWaves launches waves whose trigger chip has been read, reports whether any wave was launched
+func (rs ResultsService) launchTriggeredWaves(ctx context.Context, raceID uuid.UUID) (bool, error) {
+	waves, err := rs.RaceRepo.LaunchWavesByTrigger(ctx, raceID)
+	if err != nil {
+		return false, err
+	}
+	for _, w := range waves {
+		if rs.Log != nil {
+			rs.Log.Info("wave launched by trigger chip", "race_id", raceID.String(), "wave_id", w.ID.String(), "wave", w.Name, "chip", w.TriggerChip, "start_time", w.StartTime.Format(time.RFC3339Nano))
 		}
 	}
-	start := time.Now()
-	err = rs.AthleteRepo.SaveBulkAthleteSplits(ctx, raceID, allRecords)
+	return len(waves) > 0, nil
+}
+
+// raceLocation returns location of race timezone to render times of day in, UTC if race is not found
+func (rs ResultsService) raceLocation(ctx context.Context, raceID uuid.UUID) (*time.Location, error) {
+	race, err := rs.RaceRepo.GetRaceInfo(ctx, raceID)
 	if err != nil {
-		fmt.Println("error saving bulk athlete split for the whole race")
-		return err
+		return nil, err
+	}
+	if race == nil {
+		return time.UTC, nil
 	}
-	fmt.Printf("saving whole bulk athlete splits took %v\n", time.Since(start))
-	return nil
+	return race.Location(), nil
 }
 
-func (rs ResultsService) CalculateSplitResultsForEvent(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.AthleteSplit, error) {
+// raceNow returns current time of Now cutoffs of the race are checked at. Zero time is returned
+// if race is not found, so no cutoffs are applied.
+func (rs ResultsService) raceNow(ctx context.Context, raceID uuid.UUID) (time.Time, error) {
+	race, err := rs.RaceRepo.GetRaceInfo(ctx, raceID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if race == nil {
+		return time.Time{}, nil
+	}
+	if rs.Now == nil {
+		return time.Now(), nil
+	}
+	return rs.Now(), nil
+}
+
+// calculateSplitResultsForEvent calculates splits for athletes of the event, for all of them if chips is nil.
+// Statuses which must be changed are returned rather than saved, so that they can be saved for the whole race at once.
+func (rs ResultsService) calculateSplitResultsForEvent(ctx context.Context, raceID, eventID uuid.UUID, chips []int, now time.Time) ([]*entity.AthleteSplit, []*entity.AthleteStatus, error) {
 	start := time.Now()
-	recs, splits, err := rs.AthleteRepo.GetRecordsAndSplitsForEventAthlete(ctx, raceID, eventID)
+	recs, splits, err := rs.AthleteRepo.GetRecordsAndSplitsForEventAthlete(ctx, raceID, eventID, chips)
 	if err != nil {
-		fmt.Println("error getting records and splits for event athlete: ", err)
-		return nil, err
+		return nil, nil, fmt.Errorf("get records and splits of event %s: %w", eventID, err)
 	}
-	fmt.Printf("Time for getting records for eventID = %v is %v\n", eventID, time.Since(start))
+	rs.debug("records of event read", "event_id", eventID.String(), "took", time.Since(start))
 	var startSplit *entity.Split
 	for _, s := range splits {
 		if s.Type == entity.SplitTypeStart {
@@ -83,36 +275,54 @@ func (rs ResultsService) CalculateSplitResultsForEvent(ctx context.Context, race
 
 	manualAthleteSplits, err := rs.AthleteRepo.GetManualAthleteSplits(ctx, raceID, eventID)
 	if err != nil {
-		fmt.Println("Error getting manual")
-		return nil, err
+		return nil, nil, fmt.Errorf("get manual splits of event %s: %w", eventID, err)
 	}
-	fmt.Println("manual: ", manualAthleteSplits)
 
-	var allRecords []*entity.AthleteSplit
-	// start = time.Now()
+	allRecords := make([]*entity.AthleteSplit, 0, len(recs)*len(splits))
+	var statuses []*entity.AthleteStatus
 	for _, r := range recs {
 		athleteSplits, potentialStatus, err := calculateSplitResultForSingleAthlete(r, splits, startSplit)
 		if err != nil {
-			fmt.Println("Error getting result for single athlete: ", err)
-			return nil, err
+			return nil, nil, fmt.Errorf("calculate splits of athlete %s: %w", r.AthleteID, err)
 		}
 		if mans, ok := manualAthleteSplits[r.AthleteID]; ok {
 			replaceWithManual(athleteSplits, mans)
 		}
-		if entity.ValidStatusTransition(entity.Status(r.StatusFull), potentialStatus) {
-			err = rs.AthleteRepo.UpdateStatus(ctx, potentialStatus, raceID, eventID, r.AthleteID)
-			if err != nil {
-				fmt.Println("error updating status after split calculation")
-				return nil, err
+		var reason string
+		// athletes of launched wave who haven't started by the cutoff are out of the race as well
+		if (potentialStatus == entity.RUN || potentialStatus == entity.NYS) && !now.IsZero() {
+			if s, missed := entity.MissedCutoff(splits, athleteSplits, r.WaveStart.Time, now); missed {
+				potentialStatus = entity.DNF
+				reason = entity.CutoffReason(s)
 			}
 		}
+		if statusChanged(r, potentialStatus, reason) {
+			statuses = append(statuses, &entity.AthleteStatus{
+				AthleteID: r.AthleteID,
+				EventID:   eventID,
+				Status:    potentialStatus,
+				Reason:    reason,
+			})
+		}
 		allRecords = append(allRecords, athleteSplits...)
 	}
-	return allRecords, nil
+	return allRecords, statuses, nil
+}
+
+// statusChanged reports whether calculated status must be saved. Statuses locked by officials are never changed,
+// DNF set for missed cutoff is reverted if athlete is found to reach the split in time, e.g. after late reads upload.
+func statusChanged(r database.GetEventAthleteRecordsCRow, potential entity.Status, reason string) bool {
+	if r.StatusLocked {
+		return false
+	}
+	current := entity.Status(r.StatusFull)
+	if current == entity.DNF && entity.IsCutoffReason(r.StatusReason) {
+		return current != potential || r.StatusReason != reason
+	}
+	return entity.ValidStatusTransition(current, potential)
 }
 
 func replaceWithManual(original, manual []*entity.AthleteSplit) []*entity.AthleteSplit {
-	fmt.Println("WE HAVE MANUAL")
 	for _, m := range manual {
 		for i, o := range original {
 			if m.SplitID == o.SplitID {
@@ -124,6 +334,13 @@ func replaceWithManual(original, manual []*entity.AthleteSplit) []*entity.Athlet
 }
 
 func calculateSplitResultForSingleAthlete(r database.GetEventAthleteRecordsCRow, splits []*entity.Split, startSplit *entity.Split) ([]*entity.AthleteSplit, entity.Status, error) {
+	return traceSplitResultForSingleAthlete(r, splits, startSplit, nil)
+}
+
+// recordTracer is called for every check of athlete's read against a split with the same reader
+type recordTracer func(rec entity.RecordTOD, s *entity.Split, rule entity.RecordRule)
+
+func traceSplitResultForSingleAthlete(r database.GetEventAthleteRecordsCRow, splits []*entity.Split, startSplit *entity.Split, trace recordTracer) ([]*entity.AthleteSplit, entity.Status, error) {
 	// create slice for athlete's splits with zero times values and visited is false
 	singleAthleteRecords := entity.NewAthleteSplitsTemlate(splits, r.AthleteID, r.CategoryID, entity.CategoryGender(r.Gender))
 	if len(r.RrTod) == 0 {
@@ -142,12 +359,19 @@ func calculateSplitResultForSingleAthlete(r database.GetEventAthleteRecordsCRow,
 			}
 			// check min_time, max_time constraint
 			prevLapSplitResult := athleteResultsMap[s.PreviousLapSplitID.UUID]
-			if !s.IsValidForRecord(r.WaveStart.Time, rec.TOD, prevLapSplitResult) {
-				continue
-			}
+			rule := s.CheckRecord(r.WaveStart.Time, rec.TOD, prevLapSplitResult)
 
 			// check if such athlete result for this split is already in results map
 			_, exist := athleteResultsMap[s.ID]
+			if rule == entity.RecordAccepted && exist && s.Type != entity.SplitTypeStart {
+				rule = entity.RecordSplitAlreadyRecorded
+			}
+			if trace != nil {
+				trace(rec, s, rule)
+			}
+			if rule != entity.RecordAccepted {
+				continue
+			}
 
 			// for type 'start' existing results must be overwritten, for 'standard' and 'finish' existing must be kept unchanged
 			if !exist || s.Type == entity.SplitTypeStart {
@@ -206,10 +430,14 @@ func (rs ResultsService) CalculateRanks(ctx context.Context, eventResults []*ent
 	start := time.Now()
 	calculateRanks(eventResults, gunCmp, GUN)
 	calculateRanks(eventResults, netCmp, NET)
-	fmt.Printf("RANKS calculation for event took - %v\n", time.Since(start))
-	// for i, r := range eventResults {
-	// 	fmt.Printf("%d. res = %+v\n", i, r)
-	// }
+	rs.debug("ranks of event calculated", "took", time.Since(start))
+}
+
+// debug logs at debug level if logger is set
+func (rs ResultsService) debug(msg string, args ...any) {
+	if rs.Log != nil {
+		rs.Log.Debug(msg, args...)
+	}
 }
 
 const (
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+)
+
+// GetOverdueReport returns running athletes of all events of the race who are late for their next split.
+// Tolerance multiplies expected segment duration when it is based on the field median or athlete's own pace.
+func (rs *ResultsService) GetOverdueReport(ctx context.Context, raceID uuid.UUID, tolerance float64) (*entity.OverdueReport, error) {
+	rm, err := rs.RaceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if rm == nil {
+		return nil, nil
+	}
+	now := time.Now()
+	report := &entity.OverdueReport{
+		GeneratedAt: now,
+		Tolerance:   tolerance,
+		Athletes:    []*entity.OverdueAthlete{},
+	}
+	for _, e := range rm.Events {
+		running, err := rs.AthleteRepo.GetEventAthleteProgress(ctx, raceID, e.ID, entity.RUN)
+		if err != nil {
+			return nil, err
+		}
+		if len(running) == 0 {
+			continue
+		}
+		finished, err := rs.AthleteRepo.GetEventAthleteProgress(ctx, raceID, e.ID, entity.FIN)
+		if err != nil {
+			return nil, err
+		}
+		field := append(slices.Clip(running), finished...)
+		report.Athletes = append(report.Athletes, entity.FindOverdue(running, field, e.Splits, now, tolerance)...)
+	}
+	slices.SortFunc(report.Athletes, func(a, b *entity.OverdueAthlete) int {
+		return cmp.Compare(b.OverdueBy, a.OverdueBy)
+	})
+	report.InLocation(rm.Location())
+	return report, nil
+}
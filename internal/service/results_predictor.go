@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/google/uuid"
+)
+
+func (rs *ResultsService) GetPredictions(ctx context.Context, raceID, eventID uuid.UUID) ([]*entity.AthletePrediction, error) {
+	rm, err := rs.RaceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if rm == nil {
+		return nil, nil
+	}
+	idx := slices.IndexFunc(rm.Events, func(e *entity.Event) bool {
+		return e.ID == eventID
+	})
+	if idx == -1 {
+		return nil, nil
+	}
+	predictions, err := rs.predictForEvent(ctx, raceID, rm.Events[idx])
+	if err != nil {
+		return nil, err
+	}
+	loc := rm.Location()
+	for _, p := range predictions {
+		p.InLocation(loc)
+	}
+	return predictions, nil
+}
+
+// GetExpectedAtSplit returns running athletes who may arrive at the split within the given time from now.
+func (rs *ResultsService) GetExpectedAtSplit(ctx context.Context, raceID, splitID uuid.UUID, within time.Duration) ([]*entity.ExpectedArrival, error) {
+	rm, err := rs.RaceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if rm == nil {
+		return nil, nil
+	}
+	var event *entity.Event
+	for _, e := range rm.Events {
+		if slices.ContainsFunc(e.Splits, func(s *entity.Split) bool { return s.ID == splitID }) {
+			event = e
+			break
+		}
+	}
+	if event == nil {
+		return nil, nil
+	}
+
+	predictions, err := rs.predictForEvent(ctx, raceID, event)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	loc := rm.Location()
+	res := []*entity.ExpectedArrival{}
+	for _, p := range predictions {
+		sp, ok := p.ExpectedAt(splitID, now, now.Add(within))
+		if !ok {
+			continue
+		}
+		p.InLocation(loc)
+		res = append(res, &entity.ExpectedArrival{
+			AthleteID:       p.AthleteID,
+			Bib:             p.Bib,
+			FirstName:       p.FirstName,
+			LastName:        p.LastName,
+			LastSplitName:   p.LastSplitName,
+			LastTOD:         p.LastTOD,
+			SplitPrediction: sp,
+		})
+	}
+	slices.SortFunc(res, func(a, b *entity.ExpectedArrival) int {
+		return a.ExpectedTOD.Compare(b.ExpectedTOD)
+	})
+	return res, nil
+}
+
+func (rs *ResultsService) predictForEvent(ctx context.Context, raceID uuid.UUID, event *entity.Event) ([]*entity.AthletePrediction, error) {
+	progress, err := rs.AthleteRepo.GetEventAthleteProgress(ctx, raceID, event.ID, entity.RUN)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*entity.AthletePrediction, 0, len(progress))
+	for _, p := range progress {
+		prediction, ok := entity.PredictArrivals(p, event.Splits)
+		if !ok {
+			continue
+		}
+		res = append(res, prediction)
+	}
+	return res, nil
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/logger"
+	"github.com/google/uuid"
+)
+
+type RecalcScheduler interface {
+	Status(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error)
+	Pause(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error)
+	Resume(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error)
+}
+
+// RecalcQueue requests results calculation of the race without waiting for it
+type RecalcQueue interface {
+	Queue(raceID uuid.UUID)
+}
+
+// ResultsScheduler periodically runs full results calculation for every race with launched waves
+// and runs calculations queued by changes of races. Calculation for the race is skipped if the previous
+// one hasn't finished yet, queued calculation is run after it.
+type ResultsScheduler struct {
+	results  *ResultsService
+	log      *logger.Logger
+	interval time.Duration
+	mu       sync.Mutex
+	races    map[uuid.UUID]*entity.RecalcStatus
+	queued   map[uuid.UUID]bool
+	wake     chan struct{}
+}
+
+func NewResultsScheduler(logger *logger.Logger, results *ResultsService, interval time.Duration) *ResultsScheduler {
+	return &ResultsScheduler{
+		results:  results,
+		log:      logger,
+		interval: interval,
+		races:    make(map[uuid.UUID]*entity.RecalcStatus),
+		queued:   make(map[uuid.UUID]bool),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Run blocks until ctx is cancelled. Races are not recalculated periodically if interval is 0,
+// queued calculations are run anyway.
+func (rs *ResultsScheduler) Run(ctx context.Context) {
+	var tick <-chan time.Time
+	if rs.interval > 0 {
+		ticker := time.NewTicker(rs.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+			rs.tick(ctx)
+		case <-rs.wake:
+			rs.runQueued(ctx)
+		}
+	}
+}
+
+// Queue requests calculation of the race, e.g. after its athletes changed. Race is calculated
+// even if none of its waves is launched yet.
+func (rs *ResultsScheduler) Queue(raceID uuid.UUID) {
+	rs.mu.Lock()
+	rs.queued[raceID] = true
+	rs.mu.Unlock()
+	rs.notify()
+}
+
+// notify wakes Run to start queued calculations, it doesn't block if Run is woken already
+func (rs *ResultsScheduler) notify() {
+	select {
+	case rs.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runQueued starts calculation of queued races which are neither paused nor running
+func (rs *ResultsScheduler) runQueued(ctx context.Context) {
+	rs.mu.Lock()
+	var start []uuid.UUID
+	for raceID := range rs.queued {
+		st := rs.statusFor(raceID)
+		if st.Paused || st.Running {
+			continue
+		}
+		st.Running = true
+		delete(rs.queued, raceID)
+		start = append(start, raceID)
+	}
+	rs.mu.Unlock()
+	for _, raceID := range start {
+		go rs.run(ctx, raceID)
+	}
+}
+
+func (rs *ResultsScheduler) tick(ctx context.Context) {
+	races, err := rs.results.RaceRepo.GetRaces(ctx)
+	if err != nil {
+		rs.log.Error("results scheduler: get races", "err", err.Error())
+		return
+	}
+	for _, r := range races {
+		IDs, err := rs.results.AthleteRepo.GetEventIDsWithWavesStarted(ctx, r.ID)
+		if err != nil {
+			rs.log.Error("results scheduler: get started events", "race_id", r.ID.String(), "err", err.Error())
+			continue
+		}
+		if len(IDs) == 0 || !rs.startRun(r.ID) {
+			continue
+		}
+		go rs.run(ctx, r.ID)
+	}
+}
+
+// startRun marks race as running, returns false if race is paused or already running
+func (rs *ResultsScheduler) startRun(raceID uuid.UUID) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	st := rs.statusFor(raceID)
+	if st.Paused || st.Running {
+		return false
+	}
+	st.Running = true
+	return true
+}
+
+func (rs *ResultsScheduler) run(ctx context.Context, raceID uuid.UUID) {
+	start := time.Now()
+	err := rs.results.CalculateSplitResults(ctx, raceID)
+	if err != nil {
+		rs.log.Error("results scheduler: calculate results", "race_id", raceID.String(), "err", err.Error())
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if rs.queued[raceID] {
+		// race was changed during calculation
+		defer rs.notify()
+	}
+	st := rs.statusFor(raceID)
+	st.Running = false
+	st.Runs++
+	st.LastRunAt = start
+	st.LastDuration = time.Since(start)
+	st.LastError = ""
+	if err != nil {
+		st.LastError = err.Error()
+	}
+}
+
+// statusFor must be called with mu held
+func (rs *ResultsScheduler) statusFor(raceID uuid.UUID) *entity.RecalcStatus {
+	st, ok := rs.races[raceID]
+	if !ok {
+		st = &entity.RecalcStatus{RaceID: raceID}
+		rs.races[raceID] = st
+	}
+	return st
+}
+
+func (rs *ResultsScheduler) Status(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error) {
+	return rs.update(ctx, raceID, func(st *entity.RecalcStatus) {})
+}
+
+func (rs *ResultsScheduler) Pause(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error) {
+	return rs.update(ctx, raceID, func(st *entity.RecalcStatus) { st.Paused = true })
+}
+
+func (rs *ResultsScheduler) Resume(ctx context.Context, raceID uuid.UUID) (*entity.RecalcStatus, error) {
+	st, err := rs.update(ctx, raceID, func(st *entity.RecalcStatus) { st.Paused = false })
+	if st != nil {
+		// calculations queued while race was paused
+		rs.notify()
+	}
+	return st, err
+}
+
+// update applies f to race status and returns its copy, returns nil if race doesn't exist
+func (rs *ResultsScheduler) update(ctx context.Context, raceID uuid.UUID, f func(st *entity.RecalcStatus)) (*entity.RecalcStatus, error) {
+	race, err := rs.results.RaceRepo.GetRaceInfo(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if race == nil {
+		return nil, nil
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	st := rs.statusFor(raceID)
+	f(st)
+	res := *st
+	res.Interval = rs.interval
+	return &res, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultsSchedulerQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	raceID := uuid.New()
+	repo := newBenchAthleteRepo(8)
+	rs := NewResultsScheduler(nil, NewResultsService(repo, &benchRaceRepo{}, nil), 0)
+	go rs.Run(ctx)
+
+	_, err := rs.Pause(ctx, raceID)
+	assert.NoError(t, err)
+	rs.Queue(raceID)
+	time.Sleep(20 * time.Millisecond)
+	st, err := rs.Status(ctx, raceID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Zero(t, st.Runs, "paused race is not calculated")
+
+	_, err = rs.Resume(ctx, raceID)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		st, err := rs.Status(ctx, raceID)
+		return err == nil && st.Runs == 1 && !st.Running
+	}, time.Second, 5*time.Millisecond)
+	assert.Len(t, repo.saved, 8*4)
+}
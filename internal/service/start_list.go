@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// GenerateStartTimes assigns individual start times to athletes of event, or of one wave of event,
+// every IntervalSec seconds in bib order starting at FirstStart. Previously set start times are replaced.
+func (as *AthleteService) GenerateStartTimes(ctx context.Context, raceID uuid.UUID, req entity.StartTimesRequest, v *validator.Validator) ([]*entity.AthleteStartTime, error) {
+	v.CheckField(req.EventID != uuid.Nil, "event_id", validator.CodeRequired, "must be provided")
+	v.CheckField(validator.IsValidTime(time.RFC3339, req.FirstStart), "first_start", validator.CodeFormat, "must be date in RFC3339 format")
+	v.CheckField(req.IntervalSec > 0, "interval_sec", validator.CodeOutOfRange, "must be greater than 0")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+
+	rc, err := as.raceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if rc == nil {
+		return nil, nil
+	}
+	err = rc.Status.CheckChange()
+	if err != nil {
+		return nil, err
+	}
+	eventIdx := slices.IndexFunc(rc.Events, func(e *entity.Event) bool { return e.ID == req.EventID })
+	v.CheckField(eventIdx != -1, "event_id", validator.CodeNotFound, "event not found in race")
+	if eventIdx != -1 && req.WaveID.Valid {
+		v.CheckField(slices.ContainsFunc(rc.Events[eventIdx].Waves, func(w *entity.Wave) bool {
+			return w.ID == req.WaveID.UUID
+		}), "wave_id", validator.CodeNotFound, "wave not found in event")
+	}
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+
+	entries, err := as.athleteRepo.GetStartList(ctx, raceID, uuid.NullUUID{UUID: req.EventID, Valid: true}, req.WaveID)
+	if err != nil {
+		return nil, fmt.Errorf("generate start times: error getting athletes for event %s: %w", req.EventID, err)
+	}
+	firstStart, _ := time.Parse(time.RFC3339, req.FirstStart)
+	st := entity.GenerateStartTimes(entries, firstStart, time.Duration(req.IntervalSec)*time.Second)
+	err = as.athleteRepo.SetStartTimes(ctx, raceID, st)
+	if err != nil {
+		return nil, fmt.Errorf("generate start times: %w", err)
+	}
+	as.log.Info("start times generated", "event", req.EventID, "athletes", len(st))
+	loc := rc.Location()
+	for _, s := range st {
+		s.StartTime = entity.InLocation(s.StartTime, loc)
+	}
+	return st, nil
+}
+
+// GetStartList returns athletes of race, or of one event, ordered by start time and bib.
+// Start times are in race timezone.
+func (as *AthleteService) GetStartList(ctx context.Context, raceID uuid.UUID, eventID uuid.NullUUID) ([]*entity.StartListEntry, error) {
+	entries, err := as.athleteRepo.GetStartList(ctx, raceID, eventID, uuid.NullUUID{})
+	if err != nil {
+		return nil, err
+	}
+	loc, err := as.raceLocation(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		e.StartTime = entity.InLocation(e.StartTime, loc)
+	}
+	return entries, nil
+}
+
+// raceLocation returns location of race timezone, UTC if race is not found
+func (as *AthleteService) raceLocation(ctx context.Context, raceID uuid.UUID) (*time.Location, error) {
+	rc, err := as.raceRepo.GetRaceConfig(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	if rc == nil {
+		return time.UTC, nil
+	}
+	return rc.Location(), nil
+}
+
+// parseHandicap parses start offset from CSV either as duration string like 2m30s or as hh:mm:ss
+func parseHandicap(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	t, err := time.Parse(time.TimeOnly, s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// parseStartTime parses start time from CSV either as RFC3339 instant, or as date and time or time of day
+// on event date in race location loc
+func parseStartTime(s string, eventDate time.Time, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation(time.DateTime, s, loc); err == nil {
+		return t, nil
+	}
+	tod, err := time.Parse(time.TimeOnly, s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	y, m, d := eventDate.Date()
+	return time.Date(y, m, d, tod.Hour(), tod.Minute(), tod.Second(), 0, loc), nil
+}
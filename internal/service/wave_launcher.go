@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ecoarchie/timeit/pkg/logger"
+)
+
+// WaveLauncher periodically launches auto start waves of live races once their start time is reached.
+type WaveLauncher struct {
+	results  *ResultsService
+	log      *logger.Logger
+	interval time.Duration
+}
+
+func NewWaveLauncher(logger *logger.Logger, results *ResultsService, interval time.Duration) *WaveLauncher {
+	return &WaveLauncher{
+		results:  results,
+		log:      logger,
+		interval: interval,
+	}
+}
+
+// Run blocks until ctx is cancelled.
+func (wl *WaveLauncher) Run(ctx context.Context) {
+	ticker := time.NewTicker(wl.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			wl.tick(ctx)
+		}
+	}
+}
+
+func (wl *WaveLauncher) tick(ctx context.Context) {
+	now := time.Now()
+	// a single query finds races to launch waves of, races without due waves are not touched
+	races, err := wl.results.RaceRepo.GetRacesWithScheduledWaves(ctx, now)
+	if err != nil {
+		wl.log.Error("wave launcher: get races", "err", err.Error())
+		return
+	}
+	for _, r := range races {
+		waves, err := wl.results.RaceRepo.LaunchScheduledWaves(ctx, r.ID, now)
+		if err != nil {
+			wl.log.Error("wave launcher: launch scheduled waves", "race_id", r.ID.String(), "err", err.Error())
+			continue
+		}
+		if len(waves) == 0 {
+			continue
+		}
+		for _, w := range waves {
+			wl.log.Info("wave launched at scheduled start", "race_id", r.ID.String(), "wave_id", w.ID.String(), "wave", w.Name, "start_time", w.StartTime.In(r.Location()).Format(time.RFC3339Nano))
+		}
+		// reads of athletes of launched waves may be behind results watermark
+		err = wl.results.CalculateSplitResults(ctx, r.ID)
+		if err != nil {
+			wl.log.Error("wave launcher: calculate results", "race_id", r.ID.String(), "err", err.Error())
+		}
+	}
+}
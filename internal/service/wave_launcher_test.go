@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// launcherRaceRepo launches due waves of races kept in memory
+type launcherRaceRepo struct {
+	RaceRepo
+	races    []*entity.Race
+	waves    map[uuid.UUID][]*entity.Wave
+	launches []uuid.UUID
+}
+
+func (r *launcherRaceRepo) GetRacesWithScheduledWaves(ctx context.Context, now time.Time) ([]*entity.Race, error) {
+	var res []*entity.Race
+	for _, race := range r.races {
+		if race.Status == entity.RaceStatusLive && len(r.dueWaves(race.ID, now)) > 0 {
+			res = append(res, race)
+		}
+	}
+	return res, nil
+}
+
+func (r *launcherRaceRepo) LaunchScheduledWaves(ctx context.Context, raceID uuid.UUID, now time.Time) ([]*entity.Wave, error) {
+	r.launches = append(r.launches, raceID)
+	waves := r.dueWaves(raceID, now)
+	for _, w := range waves {
+		w.IsLaunched = true
+	}
+	return waves, nil
+}
+
+func (r *launcherRaceRepo) dueWaves(raceID uuid.UUID, now time.Time) []*entity.Wave {
+	var res []*entity.Wave
+	for _, w := range r.waves[raceID] {
+		if w.AutoStart && !w.IsLaunched && !w.StartTime.After(now) {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+func (r *launcherRaceRepo) GetRaceInfo(ctx context.Context, raceID uuid.UUID) (*entity.Race, error) {
+	for _, race := range r.races {
+		if race.ID == raceID {
+			return race, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *launcherRaceRepo) LaunchWavesByTrigger(ctx context.Context, raceID uuid.UUID) ([]*entity.Wave, error) {
+	return nil, nil
+}
+
+// launcherAthleteRepo records races results are calculated for
+type launcherAthleteRepo struct {
+	AthleteRepo
+	calculated []uuid.UUID
+}
+
+func (r *launcherAthleteRepo) GetReadsSnapshot(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (r *launcherAthleteRepo) GetEventIDsWithWavesStarted(ctx context.Context, raceID uuid.UUID) ([]uuid.UUID, error) {
+	r.calculated = append(r.calculated, raceID)
+	return nil, nil
+}
+
+func TestWaveLauncherTick(t *testing.T) {
+	now := time.Now()
+	wave := func(autoStart, launched bool, start time.Time) *entity.Wave {
+		return &entity.Wave{ID: uuid.New(), AutoStart: autoStart, IsLaunched: launched, StartTime: start}
+	}
+	tests := []struct {
+		name       string
+		status     entity.RaceStatus
+		wave       *entity.Wave
+		wantLaunch bool
+	}{
+		{
+			name:       "due auto start wave",
+			status:     entity.RaceStatusLive,
+			wave:       wave(true, false, now.Add(-time.Second)),
+			wantLaunch: true,
+		},
+		{
+			name:   "start time not reached",
+			status: entity.RaceStatusLive,
+			wave:   wave(true, false, now.Add(time.Minute)),
+		},
+		{
+			name:   "wave without auto start",
+			status: entity.RaceStatusLive,
+			wave:   wave(false, false, now.Add(-time.Second)),
+		},
+		{
+			name:   "wave already launched",
+			status: entity.RaceStatusLive,
+			wave:   wave(true, true, now.Add(-time.Second)),
+		},
+		{
+			name:   "race not timed yet",
+			status: entity.RaceStatusSetup,
+			wave:   wave(true, false, now.Add(-time.Second)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			race := &entity.Race{ID: uuid.New(), Timezone: "UTC", Status: tt.status}
+			launched := tt.wave.IsLaunched
+			raceRepo := &launcherRaceRepo{
+				races: []*entity.Race{race},
+				waves: map[uuid.UUID][]*entity.Wave{race.ID: {tt.wave}},
+			}
+			athleteRepo := &launcherAthleteRepo{}
+			wl := NewWaveLauncher(logger.New("error"), NewResultsService(athleteRepo, raceRepo, nil), time.Second)
+
+			wl.tick(context.Background())
+			if tt.wantLaunch {
+				assert.True(t, tt.wave.IsLaunched)
+				assert.Equal(t, []uuid.UUID{race.ID}, raceRepo.launches)
+				// results are recalculated for reads of launched wave behind the watermark
+				assert.Equal(t, []uuid.UUID{race.ID}, athleteRepo.calculated)
+				return
+			}
+			assert.Equal(t, launched, tt.wave.IsLaunched)
+			assert.Empty(t, raceRepo.launches)
+			assert.Empty(t, athleteRepo.calculated)
+		})
+	}
+}
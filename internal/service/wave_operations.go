@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ecoarchie/timeit/internal/entity"
+	"github.com/ecoarchie/timeit/pkg/validator"
+	"github.com/google/uuid"
+)
+
+// LaunchWaves launches all listed waves of race at once at StartTime, or at current time of the race
+// if not set. Either all waves are launched or none of them. Waves missing in the race are reported at wave_ids.
+// Returns entity.ErrWaveChanged if any wave is launched or reset meanwhile.
+func (rs RaceService) LaunchWaves(ctx context.Context, raceID uuid.UUID, req entity.WavesLaunch, v *validator.Validator) ([]*entity.Wave, error) {
+	v.CheckField(len(req.WaveIDs) != 0, "wave_ids", validator.CodeRequired, "must be provided")
+	v.CheckField(validator.Unique(req.WaveIDs), "wave_ids", validator.CodeNotUnique, "must be unique")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	waves, err := rs.repo.GetWavesForRace(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	startTime := req.StartTime
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	changes := make([]*entity.WaveAuditEntry, 0, len(req.WaveIDs))
+	for i, id := range req.WaveIDs {
+		idx := slices.IndexFunc(waves, func(w *entity.Wave) bool { return w.ID == id })
+		if idx == -1 {
+			v.Item("wave_ids", i).AddFieldError("", validator.CodeNotFound, fmt.Sprintf("wave %s not found in race", id))
+			continue
+		}
+		if waves[idx].IsLaunched {
+			v.Item("wave_ids", i).AddFieldError("", validator.CodeConflict, fmt.Sprintf("wave %q is launched already, adjust its start time instead", waves[idx].Name))
+			continue
+		}
+		changes = append(changes, entity.NewWaveAuditEntry(waves[idx], entity.WaveActionLaunch, startTime, req.Reason))
+	}
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	return rs.applyWaveChanges(ctx, raceID, changes)
+}
+
+// ResetWave returns launched wave to not launched, e.g. after false start. Results of wave athletes are removed.
+func (rs RaceService) ResetWave(ctx context.Context, raceID, waveID uuid.UUID, req entity.WaveReset, v *validator.Validator) (*entity.Wave, error) {
+	v.CheckField(req.Reason != "", "reason", validator.CodeRequired, "must be provided")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	w, err := rs.getRaceWave(ctx, raceID, waveID)
+	if err != nil || w == nil {
+		return nil, err
+	}
+	v.CheckField(w.IsLaunched, "wave_id", validator.CodeConflict, "wave is not launched")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	waves, err := rs.applyWaveChanges(ctx, raceID, []*entity.WaveAuditEntry{
+		entity.NewWaveAuditEntry(w, entity.WaveActionReset, w.StartTime, req.Reason),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return waves[0], nil
+}
+
+// AdjustWaveStart corrects start time of launched wave
+func (rs RaceService) AdjustWaveStart(ctx context.Context, raceID, waveID uuid.UUID, req entity.WaveAdjust, v *validator.Validator) (*entity.Wave, error) {
+	v.CheckField(!req.StartTime.IsZero(), "start_time", validator.CodeRequired, "must be provided")
+	v.CheckField(req.Reason != "", "reason", validator.CodeRequired, "must be provided")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	w, err := rs.getRaceWave(ctx, raceID, waveID)
+	if err != nil || w == nil {
+		return nil, err
+	}
+	v.CheckField(w.IsLaunched, "wave_id", validator.CodeConflict, "wave is not launched, launch it instead")
+	if !v.Valid() {
+		return nil, validator.ErrValidation
+	}
+	waves, err := rs.applyWaveChanges(ctx, raceID, []*entity.WaveAuditEntry{
+		entity.NewWaveAuditEntry(w, entity.WaveActionAdjust, req.StartTime, req.Reason),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return waves[0], nil
+}
+
+func (rs RaceService) GetWaveAudit(ctx context.Context, raceID uuid.UUID) ([]*entity.WaveAuditEntry, error) {
+	entries, err := rs.repo.GetWaveAudit(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := rs.raceLocation(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		e.OldStartTime = entity.InLocation(e.OldStartTime, loc)
+		e.NewStartTime = entity.InLocation(e.NewStartTime, loc)
+		e.CreatedAt = entity.InLocation(e.CreatedAt, loc)
+	}
+	return entries, nil
+}
+
+// getRaceWave returns wave of the race, nil if not found
+func (rs RaceService) getRaceWave(ctx context.Context, raceID, waveID uuid.UUID) (*entity.Wave, error) {
+	w, err := rs.repo.GetWaveByID(ctx, waveID)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil || w.RaceID != raceID {
+		return nil, nil
+	}
+	return w, nil
+}
+
+// applyWaveChanges saves changes of waves with audit and recalculates results of the race.
+// Waves of official or archived race can't be changed.
+func (rs RaceService) applyWaveChanges(ctx context.Context, raceID uuid.UUID, changes []*entity.WaveAuditEntry) ([]*entity.Wave, error) {
+	err := rs.checkChange(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := rs.raceLocation(ctx, raceID)
+	if err != nil {
+		return nil, err
+	}
+	waves, err := rs.repo.SaveWaveChanges(ctx, raceID, changes)
+	if err != nil {
+		rs.log.Error("error saving wave changes", "race", raceID, "error", err)
+		return nil, err
+	}
+	for _, c := range changes {
+		rs.log.Info("wave changed", "race", raceID, "wave", c.WaveID, "action", c.Action,
+			"old_start_time", c.OldStartTime.In(loc).Format(time.RFC3339Nano), "new_start_time", c.NewStartTime.In(loc).Format(time.RFC3339Nano), "reason", c.Reason)
+	}
+	for _, w := range waves {
+		w.StartTime = entity.InLocation(w.StartTime, loc)
+	}
+	if rs.results != nil {
+		// changes are saved already so failed calculation is left to the next recalculation
+		err = rs.results.CalculateSplitResults(ctx, raceID)
+		if err != nil {
+			rs.log.Error("error recalculating results after wave changes", "race", raceID, "error", err)
+		}
+	}
+	return waves, nil
+}
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE splits
+ADD COLUMN cutoff_time INTERVAL NOT NULL DEFAULT '0 seconds',
+ADD COLUMN cutoff_tod TIMESTAMP;
+
+ALTER TABLE event_athlete
+ADD COLUMN status_reason TEXT NOT NULL DEFAULT '',
+ADD COLUMN status_locked BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE event_athlete
+DROP COLUMN IF EXISTS status_locked,
+DROP COLUMN IF EXISTS status_reason;
+
+ALTER TABLE splits
+DROP COLUMN IF EXISTS cutoff_tod,
+DROP COLUMN IF EXISTS cutoff_time;
+-- +goose StatementEnd
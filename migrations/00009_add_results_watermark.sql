@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE results_watermark (
+  race_id UUID PRIMARY KEY REFERENCES races(id) ON DELETE CASCADE,
+  last_record_id INTEGER NOT NULL DEFAULT 0,
+  updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS results_watermark;
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE categories ADD COLUMN kind TEXT NOT NULL DEFAULT 'age' CHECK (kind IN ('age', 'custom'));
+
+-- Table: athlete_category. Membership of athletes in custom categories
+CREATE TABLE athlete_category (
+  race_id UUID NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  event_id UUID NOT NULL,
+  athlete_id UUID NOT NULL REFERENCES athletes(id) ON DELETE CASCADE,
+  category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+  PRIMARY KEY (athlete_id, category_id)
+);
+
+CREATE INDEX idx_athlete_category_category ON athlete_category (category_id);
+
+-- Table: athlete_category_rank. Ranks of athletes at splits in custom categories
+CREATE TABLE athlete_category_rank (
+  race_id UUID NOT NULL,
+  event_id UUID NOT NULL,
+  split_id UUID NOT NULL,
+  athlete_id UUID NOT NULL,
+  category_id UUID NOT NULL,
+  gun_rank INTEGER,
+  net_rank INTEGER,
+  PRIMARY KEY (race_id, event_id, split_id, athlete_id, category_id),
+  FOREIGN KEY (athlete_id, category_id) REFERENCES athlete_category (athlete_id, category_id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS athlete_category_rank;
+DROP TABLE IF EXISTS athlete_category;
+ALTER TABLE categories DROP COLUMN IF EXISTS kind;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- individual start time for time trials, wave start time is used when not set
+ALTER TABLE event_athlete ADD COLUMN start_time TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE event_athlete DROP COLUMN IF EXISTS start_time;
+-- +goose StatementEnd
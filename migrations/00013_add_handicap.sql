@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE event_athlete
+ADD COLUMN handicap INTERVAL;
+
+ALTER TABLE categories
+ADD COLUMN handicap INTERVAL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE categories
+DROP COLUMN IF EXISTS handicap;
+
+ALTER TABLE event_athlete
+DROP COLUMN IF EXISTS handicap;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- first read of trigger chip by trigger reader launches the wave
+ALTER TABLE waves
+ADD COLUMN trigger_chip INTEGER,
+ADD COLUMN trigger_reader_id UUID REFERENCES time_readers(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE waves
+DROP COLUMN IF EXISTS trigger_reader_id,
+DROP COLUMN IF EXISTS trigger_chip;
+-- +goose StatementEnd
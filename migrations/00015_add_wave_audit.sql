@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- manual launches, resets and start time corrections of waves
+CREATE TABLE wave_audit (
+  id BIGSERIAL PRIMARY KEY,
+  race_id UUID NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  wave_id UUID NOT NULL,
+  action VARCHAR NOT NULL,
+  old_start_time TIMESTAMP NOT NULL,
+  new_start_time TIMESTAMP NOT NULL,
+  was_launched BOOLEAN NOT NULL,
+  is_launched BOOLEAN NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+CREATE INDEX wave_audit_race_id_idx ON wave_audit (race_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS wave_audit;
+-- +goose StatementEnd
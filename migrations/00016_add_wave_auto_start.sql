@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- wave with auto_start is launched automatically at its start time
+ALTER TABLE waves
+ADD COLUMN auto_start BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE waves
+DROP COLUMN IF EXISTS auto_start;
+-- +goose StatementEnd
@@ -0,0 +1,74 @@
+-- +goose Up
+-- +goose StatementBegin
+-- times of day were stored as wall clock time of the race without time zone,
+-- convert them to absolute instants interpreting them in race timezone
+CREATE FUNCTION pg_temp.race_tz(race UUID) RETURNS TEXT AS $$
+  SELECT coalesce((SELECT nullif(timezone, '') FROM races WHERE id = race AND timezone <> 'Local'), 'UTC')
+$$ LANGUAGE SQL STABLE;
+
+ALTER TYPE rr_tod ALTER ATTRIBUTE tod TYPE TIMESTAMPTZ;
+
+ALTER TABLE reader_records
+ALTER COLUMN tod TYPE TIMESTAMPTZ USING tod AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE athlete_split
+ALTER COLUMN tod TYPE TIMESTAMPTZ USING tod AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE waves
+ALTER COLUMN start_time TYPE TIMESTAMPTZ USING start_time AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE event_athlete
+ALTER COLUMN start_time TYPE TIMESTAMPTZ USING start_time AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE splits
+ALTER COLUMN cutoff_tod TYPE TIMESTAMPTZ USING cutoff_tod AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE wave_audit
+ALTER COLUMN old_start_time TYPE TIMESTAMPTZ USING old_start_time AT TIME ZONE pg_temp.race_tz(race_id),
+ALTER COLUMN new_start_time TYPE TIMESTAMPTZ USING new_start_time AT TIME ZONE pg_temp.race_tz(race_id),
+ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+
+-- server times
+ALTER TABLE athletes
+ALTER COLUMN created_at TYPE TIMESTAMPTZ,
+ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+
+ALTER TABLE results_watermark
+ALTER COLUMN updated_at TYPE TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE FUNCTION pg_temp.race_tz(race UUID) RETURNS TEXT AS $$
+  SELECT coalesce((SELECT nullif(timezone, '') FROM races WHERE id = race AND timezone <> 'Local'), 'UTC')
+$$ LANGUAGE SQL STABLE;
+
+ALTER TABLE results_watermark
+ALTER COLUMN updated_at TYPE TIMESTAMP;
+
+ALTER TABLE athletes
+ALTER COLUMN created_at TYPE TIMESTAMP,
+ALTER COLUMN updated_at TYPE TIMESTAMP;
+
+ALTER TABLE wave_audit
+ALTER COLUMN old_start_time TYPE TIMESTAMP USING old_start_time AT TIME ZONE pg_temp.race_tz(race_id),
+ALTER COLUMN new_start_time TYPE TIMESTAMP USING new_start_time AT TIME ZONE pg_temp.race_tz(race_id),
+ALTER COLUMN created_at TYPE TIMESTAMP;
+
+ALTER TABLE splits
+ALTER COLUMN cutoff_tod TYPE TIMESTAMP USING cutoff_tod AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE event_athlete
+ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE waves
+ALTER COLUMN start_time TYPE TIMESTAMP USING start_time AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE athlete_split
+ALTER COLUMN tod TYPE TIMESTAMP USING tod AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TABLE reader_records
+ALTER COLUMN tod TYPE TIMESTAMP USING tod AT TIME ZONE pg_temp.race_tz(race_id);
+
+ALTER TYPE rr_tod ALTER ATTRIBUTE tod TYPE TIMESTAMP;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- lifecycle of the race: setup, live, provisional, official, archived
+ALTER TABLE races
+ADD COLUMN status VARCHAR NOT NULL DEFAULT 'setup'
+CHECK (status IN ('setup', 'live', 'provisional', 'official', 'archived'));
+
+CREATE TABLE race_status_history (
+  id BIGSERIAL PRIMARY KEY,
+  race_id UUID NOT NULL REFERENCES races(id) ON DELETE CASCADE,
+  old_status VARCHAR NOT NULL,
+  new_status VARCHAR NOT NULL,
+  forced BOOLEAN NOT NULL DEFAULT FALSE,
+  reason TEXT NOT NULL DEFAULT '',
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX race_status_history_race_id_idx ON race_status_history (race_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS race_status_history;
+ALTER TABLE races DROP COLUMN IF EXISTS status;
+-- +goose StatementEnd
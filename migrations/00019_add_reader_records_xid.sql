@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ID of transaction which added the read. Reads are committed out of order of their serial IDs, so new reads
+-- are found by transactions not finished by the time of the previous calculation instead.
+ALTER TABLE reader_records
+ADD COLUMN xid BIGINT NOT NULL DEFAULT pg_current_xact_id()::text::bigint;
+CREATE INDEX reader_records_race_xid_idx ON reader_records (race_id, xid);
+
+-- watermark is the oldest transaction running at the time of calculation, reads of it and later ones are new
+ALTER TABLE results_watermark DROP COLUMN last_record_id;
+ALTER TABLE results_watermark ADD COLUMN snapshot_xmin BIGINT NOT NULL DEFAULT 0;
+-- watermarks by read IDs are useless now, they are seeded again by live results
+DELETE FROM results_watermark;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM results_watermark;
+ALTER TABLE results_watermark DROP COLUMN snapshot_xmin;
+ALTER TABLE results_watermark ADD COLUMN last_record_id INTEGER NOT NULL DEFAULT 0;
+DROP INDEX IF EXISTS reader_records_race_xid_idx;
+ALTER TABLE reader_records DROP COLUMN xid;
+-- +goose StatementEnd
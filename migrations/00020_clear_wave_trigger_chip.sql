@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- trigger reader is set to NULL when time reader is deleted, trigger chip without reader never launches the wave
+UPDATE waves SET trigger_chip = NULL WHERE trigger_reader_id IS NULL;
+
+CREATE FUNCTION clear_wave_trigger_chip() RETURNS TRIGGER AS $$
+BEGIN
+  IF NEW.trigger_reader_id IS NULL THEN
+    NEW.trigger_chip := NULL;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER waves_clear_trigger_chip
+BEFORE INSERT OR UPDATE OF trigger_reader_id ON waves
+FOR EACH ROW EXECUTE FUNCTION clear_wave_trigger_chip();
+
+ALTER TABLE waves
+ADD CONSTRAINT waves_trigger_chip_reader CHECK (trigger_chip IS NULL OR trigger_reader_id IS NOT NULL);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE waves DROP CONSTRAINT IF EXISTS waves_trigger_chip_reader;
+DROP TRIGGER IF EXISTS waves_clear_trigger_chip ON waves;
+DROP FUNCTION IF EXISTS clear_wave_trigger_chip();
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- wave of athlete can't be deleted while athlete is assigned to it, existing rows are not checked
+ALTER TABLE event_athlete
+ADD CONSTRAINT event_athlete_wave_id_fkey FOREIGN KEY (race_id, event_id, wave_id) REFERENCES waves (race_id, event_id, id) NOT VALID;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE event_athlete DROP CONSTRAINT IF EXISTS event_athlete_wave_id_fkey;
+-- +goose StatementEnd
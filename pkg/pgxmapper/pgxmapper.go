@@ -28,6 +28,17 @@ func PgxTimestampToTime(ts pgtype.Timestamp) time.Time {
 	return ts.Time
 }
 
+func TimeToPgxTimestamptz(t time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{
+		Time:  t,
+		Valid: true,
+	}
+}
+
+func PgxTimestamptzToTime(ts pgtype.Timestamptz) time.Time {
+	return ts.Time
+}
+
 func TimeToPgxDate(t time.Time) pgtype.Date {
 	return pgtype.Date{
 		Time:  t,
@@ -2,8 +2,10 @@ package validator
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,13 +16,66 @@ var (
 	ErrValidation = errors.New("validation error")
 )
 
+// Codes of validation errors for clients to tell errors apart without parsing messages
+const (
+	CodeRequired   = "required"
+	CodeInvalid    = "invalid"
+	CodeFormat     = "invalid_format"
+	CodeNotUnique  = "not_unique"
+	CodeOutOfRange = "out_of_range"
+	CodeMismatch   = "mismatch"
+	CodeNotFound   = "not_found"
+	CodeOverlap    = "overlap"
+	CodeGap        = "gap"
+	// CodeInUse reports item which can't be changed or deleted while it is used, e.g. wave with athletes
+	CodeInUse = "in_use"
+	// CodeConflict reports request not matching current state, e.g. launching wave launched already
+	CodeConflict = "conflict"
+)
+
+// FieldError is a validation error of the field at Path of request, e.g. events[2].splits[1].min_time
+type FieldError struct {
+	Path    string `json:"path"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Validator collects validation errors. Validators returned by Field and Item share errors with the
+// validator they are created from and prefix keys of errors with path of the field.
 type Validator struct {
+	// Errors keeps the first message for every path
 	Errors map[string]string
+	fields *[]FieldError
+	path   string
 }
 
 // New is a helper which creates a new Validator instance with an empty errors map.
 func New() *Validator {
-	return &Validator{Errors: make(map[string]string)}
+	return &Validator{Errors: make(map[string]string), fields: &[]FieldError{}}
+}
+
+// Field returns validator for errors of nested field key
+func (v *Validator) Field(key string) *Validator {
+	return &Validator{Errors: v.Errors, fields: v.fields, path: v.Path(key)}
+}
+
+// Item returns validator for errors of i-th element of list key
+func (v *Validator) Item(key string, i int) *Validator {
+	return v.Field(fmt.Sprintf("%s[%d]", key, i))
+}
+
+// Path returns path of key in the request
+func (v *Validator) Path(key string) string {
+	switch {
+	case v.path == "":
+		return key
+	case key == "":
+		return v.path
+	case strings.HasPrefix(key, "["):
+		return v.path + key
+	default:
+		return v.path + "." + key
+	}
 }
 
 // Valid returns true if the errors map doesn't contain any entries.
@@ -28,11 +83,22 @@ func (v *Validator) Valid() bool {
 	return len(v.Errors) == 0
 }
 
-// AddError adds an error message to the map (so long as no entry already exists for
-// the given key).
+// FieldErrors returns all errors in order they were added, including several errors of the same field
+func (v *Validator) FieldErrors() []FieldError {
+	return *v.fields
+}
+
+// AddError adds an error message with CodeInvalid for key.
 func (v *Validator) AddError(key, message string) {
-	if _, exists := v.Errors[key]; !exists {
-		v.Errors[key] = message
+	v.AddFieldError(key, CodeInvalid, message)
+}
+
+// AddFieldError adds an error with code for key. Errors map keeps only the first message for key.
+func (v *Validator) AddFieldError(key, code, message string) {
+	path := v.Path(key)
+	*v.fields = append(*v.fields, FieldError{Path: path, Code: code, Message: message})
+	if _, exists := v.Errors[path]; !exists {
+		v.Errors[path] = message
 	}
 }
 
@@ -43,6 +109,20 @@ func (v *Validator) Check(ok bool, key, message string) {
 	}
 }
 
+// CheckField adds an error with code for key only if a validation check is not 'ok'.
+func (v *Validator) CheckField(ok bool, key, code, message string) {
+	if !ok {
+		v.AddFieldError(key, code, message)
+	}
+}
+
+// Merge adds errors of other under path of v, e.g. of item checked separately from the rest of request
+func (v *Validator) Merge(other *Validator) {
+	for _, fe := range other.FieldErrors() {
+		v.AddFieldError(fe.Path, fe.Code, fe.Message)
+	}
+}
+
 // Generic function which returns true if a specific value is in a list.
 func PermittedValue[T comparable](value T, permittedValues ...T) bool {
 	return slices.Contains(permittedValues, value)
@@ -0,0 +1,40 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatorPaths(t *testing.T) {
+	v := New()
+	ev := v.Item("events", 2)
+	ev.Item("splits", 1).AddFieldError("min_time", CodeFormat, "must be duration string")
+	ev.Item("splits", 1).AddFieldError("min_time", CodeOutOfRange, "must not be greater than max time")
+	ev.Item("categories", 0).CheckField(true, "age_from", CodeGap, "not covered")
+	v.Item("ids", 3).AddFieldError("", CodeNotFound, "not found")
+	v.AddError("race_id", "must be valid uuid")
+
+	assert.False(t, v.Valid())
+	assert.Equal(t, map[string]string{
+		"events[2].splits[1].min_time": "must be duration string",
+		"ids[3]":                       "not found",
+		"race_id":                      "must be valid uuid",
+	}, v.Errors)
+	assert.Equal(t, []FieldError{
+		{Path: "events[2].splits[1].min_time", Code: CodeFormat, Message: "must be duration string"},
+		{Path: "events[2].splits[1].min_time", Code: CodeOutOfRange, Message: "must not be greater than max time"},
+		{Path: "ids[3]", Code: CodeNotFound, Message: "not found"},
+		{Path: "race_id", Code: CodeInvalid, Message: "must be valid uuid"},
+	}, v.FieldErrors())
+}
+
+func TestValidatorMerge(t *testing.T) {
+	iv := New()
+	iv.AddFieldError("split_name", CodeRequired, "must not be empty")
+
+	v := New()
+	v.Item("splits", 4).Merge(iv)
+	v.Item("splits", 5).Merge(New())
+	assert.Equal(t, []FieldError{{Path: "splits[4].split_name", Code: CodeRequired, Message: "must not be empty"}}, v.FieldErrors())
+}